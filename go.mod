@@ -6,5 +6,5 @@ require (
 	github.com/go-gl/gl v0.0.0-20190320180904-bf2b1f2f34d7
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20191125211704-12ad95a8df72
 	github.com/go-gl/mathgl v0.0.0-20190713194549-592312d8590a
-	github.com/stretchr/testify v1.4.0
+	golang.org/x/image v0.0.0-20190321063152-3fc05d484e9f
 )
@@ -0,0 +1,170 @@
+package anim
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+)
+
+// the property of a node a channel animates - also used as a set of flags in NodePose
+type Path int
+
+const (
+    Translation Path = 1 << iota
+    Rotation
+    Scale
+    Weights
+)
+
+func (p Path) String() string {
+    switch p {
+    case Translation:
+        return "translation"
+    case Rotation:
+        return "rotation"
+    case Scale:
+        return "scale"
+    case Weights:
+        return "weights"
+    default:
+        return "Unknown"
+    }
+}
+
+// Channel animates one property of one node
+type Channel struct {
+    // the index of the node animated, as given to Player.Apply
+    Node int
+
+    Path    Path
+    Sampler *Sampler
+}
+
+// Clip is a named animation made of channels played together
+type Clip struct {
+    Name     string
+    Channels []Channel
+
+    // the time of the last keyframe of any channel
+    Duration float32
+}
+
+// Creates a clip, working out its duration from the channels
+func NewClip(name string, channels []Channel) *Clip {
+    clip := &Clip{Name: name, Channels: channels}
+
+    for _, c := range channels {
+        if d := c.Sampler.Duration(); d > clip.Duration {
+            clip.Duration = d
+        }
+    }
+
+    return clip
+}
+
+// NodePose is the animated transform of a node. Only the properties flagged in Animated were set by the clip.
+type NodePose struct {
+    Translation mgl32.Vec3
+    Rotation    mgl32.Quat
+    Scale       mgl32.Vec3
+
+    // morph target weights
+    Weights []float32
+
+    Animated Path
+}
+
+// Pose holds the animated transforms of nodes by index
+type Pose map[int]*NodePose
+
+// the pose of the node, created with the identity transform if it has none
+func (p Pose) node(index int) *NodePose {
+    np, ok := p[index]
+    if !ok {
+        np = &NodePose{Rotation: mgl32.QuatIdent(), Scale: mgl32.Vec3{1, 1, 1}}
+        p[index] = np
+    }
+
+    return np
+}
+
+// Samples every channel of the clip at time t into the pose
+func (c *Clip) Sample(t float32, pose Pose) {
+    for _, ch := range c.Channels {
+        np := pose.node(ch.Node)
+        np.Animated |= ch.Path
+
+        switch ch.Path {
+        case Translation:
+            ch.Sampler.Sample(t, np.Translation[:])
+        case Rotation:
+            np.Rotation = ch.Sampler.SampleQuat(t)
+        case Scale:
+            ch.Sampler.Sample(t, np.Scale[:])
+        case Weights:
+            if len(np.Weights) != ch.Sampler.Components {
+                np.Weights = make([]float32, ch.Sampler.Components)
+            }
+            ch.Sampler.Sample(t, np.Weights)
+        }
+    }
+}
+
+// Blends two poses, weight 0 giving a and 1 giving b. A property animated in only one of the poses is taken from base
+// for the other, base being called with the node index - typically the node's rest transform.
+func Blend(a, b Pose, weight float32, base func(node int) NodePose) Pose {
+    result := Pose{}
+
+    for node := range a {
+        result[node] = nil
+    }
+
+    for node := range b {
+        result[node] = nil
+    }
+
+    for node := range result {
+        rest := base(node)
+
+        pa, pb := a[node], b[node]
+        if pa == nil {
+            pa = &rest
+        }
+
+        if pb == nil {
+            pb = &rest
+        }
+
+        pick := func(p *NodePose, path Path) *NodePose {
+            if p.Animated&path != 0 {
+                return p
+            }
+            return &rest
+        }
+
+        np := &NodePose{Animated: pa.Animated | pb.Animated}
+
+        ta, tb := pick(pa, Translation), pick(pb, Translation)
+        np.Translation = ta.Translation.Add(tb.Translation.Sub(ta.Translation).Mul(weight))
+
+        ra, rb := pick(pa, Rotation), pick(pb, Rotation)
+        np.Rotation = slerp(ra.Rotation, rb.Rotation, weight)
+
+        sa, sb := pick(pa, Scale), pick(pb, Scale)
+        np.Scale = sa.Scale.Add(sb.Scale.Sub(sa.Scale).Mul(weight))
+
+        wa, wb := pick(pa, Weights).Weights, pick(pb, Weights).Weights
+        if len(wa) == len(wb) {
+            np.Weights = make([]float32, len(wa))
+            for i := range wa {
+                np.Weights[i] = wa[i] + (wb[i]-wa[i])*weight
+            }
+        } else if weight < 0.5 {
+            np.Weights = wa
+        } else {
+            np.Weights = wb
+        }
+
+        result[node] = np
+    }
+
+    return result
+}
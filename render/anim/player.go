@@ -0,0 +1,226 @@
+package anim
+
+import (
+    "logl/render/scene"
+)
+
+// a clip being played
+type playback struct {
+    clip *Clip
+    time float32
+    loop bool
+}
+
+// advances the playback time, wrapping when looping and holding the last frame otherwise
+func (pb *playback) advance(seconds float32) {
+    pb.time += seconds
+
+    duration := pb.clip.Duration
+    if duration <= 0 {
+        pb.time = 0
+        return
+    }
+
+    if pb.loop {
+        for pb.time >= duration {
+            pb.time -= duration
+        }
+        for pb.time < 0 {
+            pb.time += duration
+        }
+        return
+    }
+
+    if pb.time > duration {
+        pb.time = duration
+    }
+
+    if pb.time < 0 {
+        pb.time = 0
+    }
+}
+
+// Player plays a clip on a set of scene graph nodes, cross fading from the previous clip when asked
+type Player struct {
+    // playback rate, 1 for normal speed - negative plays backwards
+    Speed float32
+
+    current  *playback
+    previous *playback
+
+    // progress through a cross fade
+    fade     float32
+    fadeTime float32
+
+    // the transforms of the nodes before they were first animated, used where a clip leaves a property alone
+    rest map[int]NodePose
+}
+
+// Creates a player with nothing playing
+func NewPlayer() *Player {
+    return &Player{Speed: 1, rest: map[int]NodePose{}}
+}
+
+// Starts playing the clip from the beginning straight away - from the end when Speed is negative
+func (p *Player) Play(clip *Clip, loop bool) {
+    p.current = p.start(clip, loop)
+    p.previous = nil
+}
+
+// a playback at the start of the clip in the direction of play
+func (p *Player) start(clip *Clip, loop bool) *playback {
+    pb := &playback{clip: clip, loop: loop}
+    if p.Speed < 0 {
+        pb.time = clip.Duration
+    }
+
+    return pb
+}
+
+// Starts playing the clip from the beginning (or end when Speed is negative), blending in from the current clip over the given number of seconds.
+// The current clip carries on playing while it fades out.
+func (p *Player) CrossFade(clip *Clip, loop bool, seconds float32) {
+    if p.current == nil || seconds <= 0 {
+        p.Play(clip, loop)
+        return
+    }
+
+    p.previous = p.current
+    p.current = p.start(clip, loop)
+    p.fade = 0
+    p.fadeTime = seconds
+}
+
+// the clip playing, nil for none
+func (p *Player) Clip() *Clip {
+    if p.current == nil {
+        return nil
+    }
+
+    return p.current.clip
+}
+
+// the playback position in the current clip
+func (p *Player) Time() float32 {
+    if p.current == nil {
+        return 0
+    }
+
+    return p.current.time
+}
+
+// moves the playback position in the current clip
+func (p *Player) Seek(t float32) {
+    if p.current != nil {
+        p.current.time = 0
+        p.current.advance(t)
+    }
+}
+
+// whether a clip that doesn't loop has reached its end, or its start when playing backwards
+func (p *Player) Finished() bool {
+    if p.current == nil {
+        return true
+    }
+
+    if p.current.loop {
+        return false
+    }
+
+    if p.Speed < 0 {
+        return p.current.time <= 0
+    }
+
+    return p.current.time >= p.current.clip.Duration
+}
+
+// Advances playback by the elapsed time
+func (p *Player) Update(seconds float32) {
+    if p.current == nil {
+        return
+    }
+
+    step := seconds * p.Speed
+    p.current.advance(step)
+
+    if p.previous != nil {
+        p.previous.advance(step)
+
+        p.fade += seconds
+        if p.fade >= p.fadeTime {
+            p.previous = nil
+        }
+    }
+}
+
+// the blended pose at the current playback position
+func (p *Player) Pose() Pose {
+    if p.current == nil {
+        return Pose{}
+    }
+
+    pose := Pose{}
+    p.current.clip.Sample(p.current.time, pose)
+
+    if p.previous == nil {
+        return pose
+    }
+
+    from := Pose{}
+    p.previous.clip.Sample(p.previous.time, from)
+
+    return Blend(from, pose, p.fade/p.fadeTime, p.restPose)
+}
+
+func (p *Player) restPose(node int) NodePose {
+    if rest, ok := p.rest[node]; ok {
+        return rest
+    }
+
+    return *Pose{}.node(node)
+}
+
+// Sets the transforms of the animated nodes, indexed as in the clip channels. Nodes out of range or nil are skipped,
+// and properties the clips don't animate keep the value the node had before it was first animated.
+func (p *Player) Apply(nodes []*scene.Node) {
+    // the rest transforms are needed before blending
+    for _, pb := range []*playback{p.current, p.previous} {
+        if pb != nil {
+            p.recordRest(pb.clip, nodes)
+        }
+    }
+
+    for index, np := range p.Pose() {
+        if index < 0 || index >= len(nodes) || nodes[index] == nil {
+            continue
+        }
+
+        rest := p.rest[index]
+
+        if np.Animated&Translation == 0 {
+            np.Translation = rest.Translation
+        }
+
+        if np.Animated&Rotation == 0 {
+            np.Rotation = rest.Rotation
+        }
+
+        if np.Animated&Scale == 0 {
+            np.Scale = rest.Scale
+        }
+
+        nodes[index].SetTransform(np.Translation, np.Rotation, np.Scale)
+    }
+}
+
+// remembers the current transform of each node the clip animates, unless already known
+func (p *Player) recordRest(clip *Clip, nodes []*scene.Node) {
+    for _, ch := range clip.Channels {
+        if _, ok := p.rest[ch.Node]; ok || ch.Node < 0 || ch.Node >= len(nodes) || nodes[ch.Node] == nil {
+            continue
+        }
+
+        node := nodes[ch.Node]
+        p.rest[ch.Node] = NodePose{Translation: node.Position(), Rotation: node.Rotation(), Scale: node.Scale()}
+    }
+}
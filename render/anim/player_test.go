@@ -0,0 +1,147 @@
+package anim
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "testing"
+)
+
+// a clip moving node 0 along x from the given position to another over the duration
+func moveClip(from, to, duration float32) *Clip {
+    return NewClip("move", []Channel{{
+        Node: 0,
+        Path: Translation,
+        Sampler: &Sampler{
+            Times:      []float32{0, duration},
+            Values:     []float32{from, 0, 0, to, 0, 0},
+            Components: 3,
+        },
+    }})
+}
+
+func TestBlend(t *testing.T) {
+    a := Pose{0: {Translation: mgl32.Vec3{0, 0, 0}, Rotation: mgl32.QuatIdent(), Animated: Translation | Rotation}}
+    b := Pose{0: {
+        Translation: mgl32.Vec3{4, 0, 0},
+        Rotation:    mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 0, 1}),
+        Scale:       mgl32.Vec3{3, 3, 3},
+        Animated:    Translation | Rotation | Scale,
+    }}
+
+    // scale is only animated by b, so a's comes from the rest pose
+    rest := func(node int) NodePose {
+        return NodePose{Rotation: mgl32.QuatIdent(), Scale: mgl32.Vec3{1, 1, 1}}
+    }
+
+    tests := []struct {
+        weight      float32
+        translation float32
+        angle       float32
+        scale       float32
+    }{
+        {0, 0, 0, 1},
+        {0.25, 1, 22.5, 1.5},
+        {0.5, 2, 45, 2},
+        {1, 4, 90, 3},
+    }
+
+    for _, test := range tests {
+        np := Blend(a, b, test.weight, rest)[0]
+
+        if !near(np.Translation.X(), test.translation) {
+            t.Errorf("weight %v: translation %v, expected %v", test.weight, np.Translation, test.translation)
+        }
+
+        rotation := mgl32.QuatRotate(mgl32.DegToRad(test.angle), mgl32.Vec3{0, 0, 1})
+        if !np.Rotation.OrientationEqualThreshold(rotation, epsilon) {
+            t.Errorf("weight %v: rotation %v, expected %v degrees", test.weight, np.Rotation, test.angle)
+        }
+
+        if !near(np.Scale.X(), test.scale) {
+            t.Errorf("weight %v: scale %v, expected %v", test.weight, np.Scale, test.scale)
+        }
+
+        if np.Animated != Translation|Rotation|Scale {
+            t.Errorf("weight %v: animated %v", test.weight, np.Animated)
+        }
+    }
+}
+
+func TestCrossFade(t *testing.T) {
+    p := NewPlayer()
+    p.Play(moveClip(0, 0, 1), true)
+    p.CrossFade(moveClip(8, 8, 1), true, 2)
+
+    // seconds since the fade began and the expected position
+    steps := []struct {
+        seconds float32
+        x       float32
+    }{
+        {0, 0},
+        {0.5, 2},
+        {1, 4},
+        {1.5, 6},
+        {2, 8},
+        {3, 8},
+    }
+
+    elapsed := float32(0)
+    for _, step := range steps {
+        p.Update(step.seconds - elapsed)
+        elapsed = step.seconds
+
+        if x := p.Pose()[0].Translation.X(); !near(x, step.x) {
+            t.Errorf("after %vs: x = %v, expected %v", step.seconds, x, step.x)
+        }
+    }
+}
+
+func TestFinished(t *testing.T) {
+    tests := []struct {
+        name    string
+        speed   float32
+        loop    bool
+        updates []float32
+
+        // whether the player has finished after each update
+        finished []bool
+    }{
+        {"forwards", 1, false, []float32{0.5, 0.5, 0.5}, []bool{false, true, true}},
+        {"backwards", -1, false, []float32{0.5, 0.5, 0.5}, []bool{false, true, true}},
+        {"double speed backwards", -2, false, []float32{0.25, 0.5}, []bool{false, true}},
+        {"looping", 1, true, []float32{0.5, 0.5, 0.5}, []bool{false, false, false}},
+        {"looping backwards", -1, true, []float32{0.5, 0.5, 0.5}, []bool{false, false, false}},
+    }
+
+    for _, test := range tests {
+        p := NewPlayer()
+        p.Speed = test.speed
+        p.Play(moveClip(0, 1, 1), test.loop)
+
+        if p.Finished() {
+            t.Errorf("%s: finished before starting", test.name)
+        }
+
+        for i, seconds := range test.updates {
+            p.Update(seconds)
+
+            if p.Finished() != test.finished[i] {
+                t.Errorf("%s: after update %d finished = %v at %v", test.name, i, p.Finished(), p.Time())
+            }
+        }
+    }
+}
+
+func TestPlayBackwardsFromEnd(t *testing.T) {
+    p := NewPlayer()
+    p.Speed = -1
+    p.Play(moveClip(0, 4, 2), false)
+
+    if x := p.Pose()[0].Translation.X(); !near(x, 4) {
+        t.Errorf("started at x = %v, expected the end of the clip", x)
+    }
+
+    p.Update(0.5)
+    if x := p.Pose()[0].Translation.X(); !near(x, 3) {
+        t.Errorf("x = %v after half a second backwards, expected 3", x)
+    }
+}
@@ -0,0 +1,173 @@
+// Package anim plays keyframe animation on scene graph nodes - sampling keyframes with step, linear or cubic spline
+// interpolation, blending clips together and computing the joint matrices for skinned meshes. Everything here is pure
+// Go, the gl side of skinning lives in the render package.
+package anim
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "sort"
+)
+
+// how values are interpolated between keyframes
+type Interpolation int
+
+const (
+    // values change linearly, rotations are spherically interpolated
+    Linear Interpolation = iota
+
+    // each value is held until the next keyframe
+    Step
+
+    // cubic Hermite spline - every keyframe has an in tangent, the value and an out tangent
+    CubicSpline
+)
+
+func (i Interpolation) String() string {
+    switch i {
+    case Linear:
+        return "LINEAR"
+    case Step:
+        return "STEP"
+    case CubicSpline:
+        return "CUBICSPLINE"
+    default:
+        return "Unknown"
+    }
+}
+
+// Sampler holds keyframes of a vector value with Components elements. Values are stored one keyframe after another,
+// with three entries per keyframe (in tangent, value, out tangent) for CubicSpline.
+type Sampler struct {
+    Times         []float32
+    Values        []float32
+    Components    int
+    Interpolation Interpolation
+}
+
+// the time of the last keyframe
+func (s *Sampler) Duration() float32 {
+    if len(s.Times) == 0 {
+        return 0
+    }
+
+    return s.Times[len(s.Times)-1]
+}
+
+// the value entry of keyframe k
+func (s *Sampler) value(k int) []float32 {
+    n := s.Components
+
+    if s.Interpolation == CubicSpline {
+        return s.Values[(3*k+1)*n : (3*k+2)*n]
+    }
+
+    return s.Values[k*n : (k+1)*n]
+}
+
+// the in tangent of keyframe k (cubic spline only)
+func (s *Sampler) inTangent(k int) []float32 {
+    n := s.Components
+    return s.Values[3*k*n : (3*k+1)*n]
+}
+
+// the out tangent of keyframe k (cubic spline only)
+func (s *Sampler) outTangent(k int) []float32 {
+    n := s.Components
+    return s.Values[(3*k+2)*n : (3*k+3)*n]
+}
+
+// finds the keyframes either side of t and how far t is between them. Times before the first or after the last
+// keyframe clamp to it, giving the same keyframe twice.
+func (s *Sampler) locate(t float32) (int, int, float32) {
+    last := len(s.Times) - 1
+
+    if t <= s.Times[0] {
+        return 0, 0, 0
+    }
+
+    if t >= s.Times[last] {
+        return last, last, 0
+    }
+
+    // the first keyframe after t
+    next := sort.Search(len(s.Times), func(i int) bool { return s.Times[i] > t })
+    prev := next - 1
+
+    return prev, next, (t - s.Times[prev]) / (s.Times[next] - s.Times[prev])
+}
+
+// Samples the value at time t into dst, which must hold Components values
+func (s *Sampler) Sample(t float32, dst []float32) {
+    if len(s.Times) == 0 {
+        return
+    }
+
+    prev, next, u := s.locate(t)
+
+    switch {
+    case prev == next || s.Interpolation == Step:
+        copy(dst, s.value(prev))
+
+    case s.Interpolation == CubicSpline:
+        s.hermite(prev, next, u, dst)
+
+    default:
+        a, b := s.value(prev), s.value(next)
+        for i := 0; i < s.Components; i++ {
+            dst[i] = a[i] + (b[i]-a[i])*u
+        }
+    }
+}
+
+// evaluates the cubic Hermite spline between two keyframes, the tangents being scaled by the time between them
+func (s *Sampler) hermite(prev, next int, u float32, dst []float32) {
+    dt := s.Times[next] - s.Times[prev]
+    p0, m0 := s.value(prev), s.outTangent(prev)
+    p1, m1 := s.value(next), s.inTangent(next)
+
+    u2 := u * u
+    u3 := u2 * u
+
+    h00 := 2*u3 - 3*u2 + 1
+    h10 := u3 - 2*u2 + u
+    h01 := -2*u3 + 3*u2
+    h11 := u3 - u2
+
+    for i := 0; i < s.Components; i++ {
+        dst[i] = h00*p0[i] + h10*dt*m0[i] + h01*p1[i] + h11*dt*m1[i]
+    }
+}
+
+// Samples a rotation stored as x, y, z, w quaternion keyframes. Linear interpolation is spherical along the shortest
+// arc and the result is always normalised.
+func (s *Sampler) SampleQuat(t float32) mgl32.Quat {
+    if len(s.Times) == 0 {
+        return mgl32.QuatIdent()
+    }
+
+    prev, next, u := s.locate(t)
+
+    if s.Interpolation == Linear && prev != next {
+        a, b := toQuat(s.value(prev)), toQuat(s.value(next))
+        return slerp(a, b, u)
+    }
+
+    var v [4]float32
+    s.Sample(t, v[:])
+
+    return toQuat(v[:]).Normalize()
+}
+
+func toQuat(v []float32) mgl32.Quat {
+    return mgl32.Quat{W: v[3], V: mgl32.Vec3{v[0], v[1], v[2]}}
+}
+
+// spherical interpolation taking the shortest path - q and -q are the same rotation, but QuatSlerp goes the long way
+// round when they point apart
+func slerp(a, b mgl32.Quat, u float32) mgl32.Quat {
+    if a.Dot(b) < 0 {
+        b = b.Scale(-1)
+    }
+
+    return mgl32.QuatSlerp(a, b, u).Normalize()
+}
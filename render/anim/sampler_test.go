@@ -0,0 +1,120 @@
+package anim
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+    "testing"
+)
+
+const epsilon = 1e-5
+
+func near(a, b float32) bool {
+    return math.Abs(float64(a-b)) < epsilon
+}
+
+func TestSample(t *testing.T) {
+    linear := &Sampler{Times: []float32{0, 1, 3}, Values: []float32{0, 10, 30}, Components: 1}
+    step := &Sampler{Times: []float32{0, 1, 3}, Values: []float32{0, 10, 30}, Components: 1, Interpolation: Step}
+
+    // in tangent, value, out tangent per keyframe: a slope of 1 per second over 2 seconds is the straight line 2t/2
+    cubic := &Sampler{Times: []float32{0, 2}, Values: []float32{0, 0, 1, 1, 2, 0}, Components: 1,
+        Interpolation: CubicSpline}
+
+    // flat tangents ease in and out, crossing the midpoint halfway
+    flat := &Sampler{Times: []float32{1, 2}, Values: []float32{0, 4, 0, 0, 8, 0}, Components: 1,
+        Interpolation: CubicSpline}
+
+    tests := []struct {
+        name     string
+        sampler  *Sampler
+        t        float32
+        expected float32
+    }{
+        {"linear before start", linear, -1, 0},
+        {"linear first key", linear, 0, 0},
+        {"linear halfway", linear, 0.5, 5},
+        {"linear uneven keys", linear, 2, 20},
+        {"linear after end", linear, 4, 30},
+        {"step before key", step, 0.99, 0},
+        {"step on key", step, 1, 10},
+        {"step held", step, 2.9, 10},
+        {"step after end", step, 5, 30},
+        {"cubic start", cubic, 0, 0},
+        {"cubic quarter", cubic, 0.5, 0.5},
+        {"cubic halfway", cubic, 1, 1},
+        {"cubic end", cubic, 2, 2},
+        {"cubic flat start", flat, 1, 4},
+        {"cubic flat halfway", flat, 1.5, 6},
+        {"cubic flat eased", flat, 1.25, 4 + 4*0.15625},
+        {"cubic flat after end", flat, 3, 8},
+    }
+
+    for _, test := range tests {
+        var v [1]float32
+        test.sampler.Sample(test.t, v[:])
+
+        if !near(v[0], test.expected) {
+            t.Errorf("%s: sampled %v at %v, expected %v", test.name, v[0], test.t, test.expected)
+        }
+    }
+}
+
+func TestSampleComponents(t *testing.T) {
+    s := &Sampler{Times: []float32{0, 1}, Values: []float32{0, 0, 0, 2, 4, 6}, Components: 3}
+
+    var v mgl32.Vec3
+    s.Sample(0.25, v[:])
+
+    if !v.ApproxEqual(mgl32.Vec3{0.5, 1, 1.5}) {
+        t.Errorf("sampled %v", v)
+    }
+}
+
+func TestSampleQuat(t *testing.T) {
+    quarter := mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 1, 0})
+    eighth := mgl32.QuatRotate(mgl32.DegToRad(45), mgl32.Vec3{0, 1, 0})
+    ident := mgl32.QuatIdent()
+
+    keys := func(interpolation Interpolation, quats ...mgl32.Quat) *Sampler {
+        s := &Sampler{Components: 4, Interpolation: interpolation}
+        for i, q := range quats {
+            s.Times = append(s.Times, float32(i))
+            s.Values = append(s.Values, q.V[0], q.V[1], q.V[2], q.W)
+        }
+        return s
+    }
+
+    // cubic keyframes with zero tangents and an unnormalised value
+    cubic := &Sampler{Times: []float32{0, 1}, Components: 4, Interpolation: CubicSpline, Values: []float32{
+        0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0,
+        0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0,
+    }}
+
+    tests := []struct {
+        name     string
+        sampler  *Sampler
+        t        float32
+        expected mgl32.Quat
+    }{
+        {"slerp start", keys(Linear, ident, quarter), 0, ident},
+        {"slerp halfway", keys(Linear, ident, quarter), 0.5, eighth},
+        {"slerp end", keys(Linear, ident, quarter), 1, quarter},
+        {"slerp shortest arc", keys(Linear, ident, quarter.Scale(-1)), 0.5, eighth},
+        {"step", keys(Step, ident, quarter), 0.9, ident},
+        {"step unnormalised", keys(Step, ident.Scale(3), quarter), 0.5, ident},
+        {"cubic normalised", cubic, 0.5, ident},
+    }
+
+    for _, test := range tests {
+        q := test.sampler.SampleQuat(test.t)
+
+        if !near(q.Len(), 1) {
+            t.Errorf("%s: length %v", test.name, q.Len())
+        }
+
+        // q and -q are the same rotation
+        if !q.OrientationEqualThreshold(test.expected, epsilon) {
+            t.Errorf("%s: sampled %v, expected %v", test.name, q, test.expected)
+        }
+    }
+}
@@ -0,0 +1,51 @@
+package anim
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "logl/render/scene"
+)
+
+// Skin binds a mesh to a skeleton of joint nodes
+type Skin struct {
+    Name string
+
+    // the node index of each joint, in the order the mesh's joint attributes refer to them
+    Joints []int
+
+    // per joint, the transform from mesh space into the joint's space in the bind pose
+    InverseBindMatrices []mgl32.Mat4
+
+    // the node index of the common root of the joints, -1 when not given
+    Skeleton int
+}
+
+// Computes the joint matrices for a mesh drawn at the given node, writing into dst (grown when too small). Each
+// matrix maps a vertex from the mesh's bind pose to where the animated joint puts it, relative to the mesh node, so
+// the result goes through the usual model matrix in the vertex shader.
+func (s *Skin) JointMatrices(nodes []*scene.Node, meshNode *scene.Node, dst []mgl32.Mat4) []mgl32.Mat4 {
+    if cap(dst) < len(s.Joints) {
+        dst = make([]mgl32.Mat4, len(s.Joints))
+    }
+    dst = dst[:len(s.Joints)]
+
+    toMesh := mgl32.Ident4()
+    if meshNode != nil {
+        toMesh = meshNode.WorldMatrix().Inv()
+    }
+
+    for i, joint := range s.Joints {
+        world := mgl32.Ident4()
+        if joint >= 0 && joint < len(nodes) && nodes[joint] != nil {
+            world = nodes[joint].WorldMatrix()
+        }
+
+        inverseBind := mgl32.Ident4()
+        if i < len(s.InverseBindMatrices) {
+            inverseBind = s.InverseBindMatrices[i]
+        }
+
+        dst[i] = toMesh.Mul4(world).Mul4(inverseBind)
+    }
+
+    return dst
+}
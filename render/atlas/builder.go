@@ -0,0 +1,183 @@
+package atlas
+
+import (
+    "errors"
+    "fmt"
+    "image"
+    "image/draw"
+    "sort"
+)
+
+// a packed entry: its pixel rectangle within the atlas image and the matching texture coordinates
+type Region struct {
+    Rect Rect
+
+    // normalised texture coordinates of the top left (U0, V0) and bottom right (U1, V1) corners. The atlas image is
+    // uploaded top row first, so V grows downwards in the same direction as the pixel rows.
+    U0, V0, U1, V1 float32
+}
+
+// the result of a build: the composed image and the region of every entry by name
+type Atlas struct {
+    Image   *image.RGBA
+    Regions map[string]Region
+}
+
+type entry struct {
+    name string
+    img  image.Image
+}
+
+// Builder collects named images and packs them into the smallest power of two atlas that fits (or MaxSize when that
+// is not a power of two). The zero value is ready to use once MaxSize is set.
+type Builder struct {
+    // the gap in pixels kept between entries
+    Padding int
+
+    // the largest width or height the atlas may grow to
+    MaxSize int
+
+    entries []entry
+    names   map[string]bool
+}
+
+// Creates a builder for atlases up to maxSize pixels square with the given padding between entries
+func NewBuilder(maxSize, padding int) *Builder {
+    return &Builder{
+        Padding: padding,
+        MaxSize: maxSize,
+        names:   map[string]bool{},
+    }
+}
+
+// Adds a named image to the atlas, names must be unique
+func (b *Builder) Add(name string, img image.Image) error {
+    if b.names == nil {
+        b.names = map[string]bool{}
+    }
+
+    if b.names[name] {
+        return fmt.Errorf("duplicate atlas entry: name = %s", name)
+    }
+
+    b.names[name] = true
+    b.entries = append(b.entries, entry{name, img})
+
+    return nil
+}
+
+// the number of entries added so far
+func (b *Builder) Len() int {
+    return len(b.entries)
+}
+
+// Packs every entry added so far and composes the atlas image. Entries are placed tallest first; the atlas starts at
+// the smallest power of two square holding the total entry area and doubles its shorter side until everything fits,
+// with sides capped at MaxSize.
+func (b *Builder) Build() (*Atlas, error) {
+    if len(b.entries) == 0 {
+        return nil, errors.New("no entries added to atlas")
+    }
+
+    if b.MaxSize < 1 {
+        return nil, fmt.Errorf("invalid atlas max size: max = %d", b.MaxSize)
+    }
+
+    order := make([]entry, len(b.entries))
+    copy(order, b.entries)
+
+    sort.SliceStable(order, func(i, j int) bool {
+        si, sj := order[i].img.Bounds().Size(), order[j].img.Bounds().Size()
+        if si.Y != sj.Y {
+            return si.Y > sj.Y
+        }
+        return si.X > sj.X
+    })
+
+    area := 0
+    for _, e := range order {
+        size := e.img.Bounds().Size()
+
+        if size.X > b.MaxSize || size.Y > b.MaxSize {
+            return nil, fmt.Errorf("atlas entry too large: name = %s, width = %d, height = %d, max = %d",
+                e.name, size.X, size.Y, b.MaxSize)
+        }
+
+        area += (size.X + b.Padding) * (size.Y + b.Padding)
+    }
+
+    width, height := 1, 1
+    for width*height < area && width < b.MaxSize {
+        width, height = width*2, height*2
+    }
+
+    width, height = minInt(width, b.MaxSize), minInt(height, b.MaxSize)
+
+    for {
+        if rects, ok := pack(order, width, height, b.Padding); ok {
+            return compose(order, rects, width, height), nil
+        }
+
+        if width == b.MaxSize && height == b.MaxSize {
+            break
+        }
+
+        if width <= height && width < b.MaxSize || height == b.MaxSize {
+            width = minInt(width*2, b.MaxSize)
+        } else {
+            height = minInt(height*2, b.MaxSize)
+        }
+    }
+
+    return nil, fmt.Errorf("atlas entries do not fit: entries = %d, max = %d", len(order), b.MaxSize)
+}
+
+// packs the entries in order into an area of the given size
+func pack(entries []entry, width, height, padding int) ([]Rect, bool) {
+    packer := NewPacker(width, height, padding)
+    rects := make([]Rect, len(entries))
+
+    for i, e := range entries {
+        size := e.img.Bounds().Size()
+
+        rect, ok := packer.Pack(size.X, size.Y)
+        if !ok {
+            return nil, false
+        }
+
+        rects[i] = rect
+    }
+
+    return rects, true
+}
+
+// draws every entry into a new image and computes the regions
+func compose(entries []entry, rects []Rect, width, height int) *Atlas {
+    atlas := &Atlas{
+        Image:   image.NewRGBA(image.Rect(0, 0, width, height)),
+        Regions: make(map[string]Region, len(entries)),
+    }
+
+    for i, e := range entries {
+        r := rects[i]
+        dst := image.Rect(r.X, r.Y, r.MaxX(), r.MaxY())
+        draw.Draw(atlas.Image, dst, e.img, e.img.Bounds().Min, draw.Src)
+
+        atlas.Regions[e.name] = Region{
+            Rect: r,
+            U0:   float32(r.X) / float32(width),
+            V0:   float32(r.Y) / float32(height),
+            U1:   float32(r.MaxX()) / float32(width),
+            V1:   float32(r.MaxY()) / float32(height),
+        }
+    }
+
+    return atlas
+}
+
+func minInt(a, b int) int {
+    if a < b {
+        return a
+    }
+    return b
+}
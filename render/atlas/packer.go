@@ -0,0 +1,153 @@
+// Package atlas packs many small images into a single larger image using a skyline bottom-left rectangle packer.
+// It has no GL dependency so the packing can be used (and checked) without a context - the render package turns the
+// result into a texture.
+package atlas
+
+// an axis aligned rectangle in pixels with its origin at the top left
+type Rect struct {
+    X, Y, W, H int
+}
+
+// the right edge (exclusive)
+func (r Rect) MaxX() int {
+    return r.X + r.W
+}
+
+// the bottom edge (exclusive)
+func (r Rect) MaxY() int {
+    return r.Y + r.H
+}
+
+// reports whether the two rectangles share any pixels
+func (r Rect) Overlaps(o Rect) bool {
+    return r.X < o.MaxX() && o.X < r.MaxX() && r.Y < o.MaxY() && o.Y < r.MaxY()
+}
+
+// a horizontal segment of the skyline: the top of the packed area between x and x+width is at y
+type segment struct {
+    x, y, width int
+}
+
+// Packer places rectangles into a fixed size area using the skyline bottom-left heuristic - each rectangle goes where
+// its bottom edge ends up lowest, ties broken by the narrowest supporting segment
+type Packer struct {
+    width   int
+    height  int
+    padding int
+    skyline []segment
+}
+
+// Creates a packer for an area of the given size. Padding is the empty gap in pixels kept between packed rectangles,
+// to stop neighbours bleeding into each other when sampled with filtering - rectangles may still touch the edges.
+func NewPacker(width, height, padding int) *Packer {
+    if padding < 0 {
+        padding = 0
+    }
+
+    // every rectangle is packed with the padding to its right and below, so the area is grown by the padding to let
+    // those along the right and bottom edges fit exactly
+    return &Packer{
+        width:   width,
+        height:  height,
+        padding: padding,
+        skyline: []segment{{0, 0, width + padding}},
+    }
+}
+
+// the size of the area being packed
+func (p *Packer) Size() (int, int) {
+    return p.width, p.height
+}
+
+// Finds space for a rectangle of the given size, returning false when there is none left
+func (p *Packer) Pack(width, height int) (Rect, bool) {
+    if width < 0 || height < 0 {
+        return Rect{}, false
+    }
+
+    w, h := width+p.padding, height+p.padding
+
+    best, bestY, bestWidth := -1, 0, 0
+    for i := range p.skyline {
+        y, ok := p.fit(i, w, h)
+        if !ok {
+            continue
+        }
+
+        if best == -1 || y < bestY || (y == bestY && p.skyline[i].width < bestWidth) {
+            best, bestY, bestWidth = i, y, p.skyline[i].width
+        }
+    }
+
+    if best == -1 {
+        return Rect{}, false
+    }
+
+    rect := Rect{X: p.skyline[best].x, Y: bestY, W: width, H: height}
+    p.raise(best, segment{x: rect.X, y: bestY + h, width: w})
+
+    return rect, true
+}
+
+// the lowest y at which a rectangle of the given (padded) size can sit with its left edge on segment i
+func (p *Packer) fit(i, w, h int) (int, bool) {
+    x := p.skyline[i].x
+    if x+w > p.width+p.padding {
+        return 0, false
+    }
+
+    y := 0
+    for remaining := w; remaining > 0; i++ {
+        if i == len(p.skyline) {
+            return 0, false
+        }
+
+        if p.skyline[i].y > y {
+            y = p.skyline[i].y
+        }
+
+        if y+h > p.height+p.padding {
+            return 0, false
+        }
+
+        remaining -= p.skyline[i].width
+    }
+
+    return y, true
+}
+
+// inserts the new top segment at index i, trims the segments it covers and merges neighbours at the same height
+func (p *Packer) raise(i int, top segment) {
+    p.skyline = append(p.skyline, segment{})
+    copy(p.skyline[i+1:], p.skyline[i:])
+    p.skyline[i] = top
+
+    for j := i + 1; j < len(p.skyline); {
+        prev := p.skyline[j-1]
+        cur := &p.skyline[j]
+
+        if cur.x >= prev.x+prev.width {
+            break
+        }
+
+        shrink := prev.x + prev.width - cur.x
+        cur.x += shrink
+        cur.width -= shrink
+
+        if cur.width > 0 {
+            break
+        }
+
+        p.skyline = append(p.skyline[:j], p.skyline[j+1:]...)
+    }
+
+    for j := 0; j < len(p.skyline)-1; {
+        if p.skyline[j].y == p.skyline[j+1].y {
+            p.skyline[j].width += p.skyline[j+1].width
+            p.skyline = append(p.skyline[:j+1], p.skyline[j+2:]...)
+            continue
+        }
+
+        j++
+    }
+}
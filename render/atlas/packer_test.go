@@ -0,0 +1,183 @@
+package atlas
+
+import (
+    "fmt"
+    "image"
+    "math/rand"
+    "testing"
+)
+
+// packs the sizes in order, failing the test if any doesn't fit, overlaps another or leaves the area
+func packAll(t *testing.T, p *Packer, sizes [][2]int) []Rect {
+    t.Helper()
+
+    width, height := p.Size()
+    var rects []Rect
+
+    for i, size := range sizes {
+        r, ok := p.Pack(size[0], size[1])
+        if !ok {
+            t.Fatalf("rect %d (%dx%d) did not fit", i, size[0], size[1])
+        }
+
+        if r.W != size[0] || r.H != size[1] {
+            t.Fatalf("rect %d: got size %dx%d, want %dx%d", i, r.W, r.H, size[0], size[1])
+        }
+
+        if r.X < 0 || r.Y < 0 || r.MaxX() > width || r.MaxY() > height {
+            t.Fatalf("rect %d outside the area: %+v", i, r)
+        }
+
+        rects = append(rects, r)
+    }
+
+    return rects
+}
+
+func TestPackExactFit(t *testing.T) {
+    for _, padding := range []int{0, 1, 4} {
+        p := NewPacker(10, 10, padding)
+
+        if r, ok := p.Pack(10, 10); !ok || r != (Rect{0, 0, 10, 10}) {
+            t.Errorf("padding %d: got %+v, %v, want the whole area", padding, r, ok)
+        }
+
+        if _, ok := p.Pack(1, 1); ok {
+            t.Errorf("padding %d: packed into a full area", padding)
+        }
+    }
+}
+
+func TestPackGrid(t *testing.T) {
+    // four 4x4 rects with a gap of 2 exactly fill 10x10
+    p := NewPacker(10, 10, 2)
+    rects := packAll(t, p, [][2]int{{4, 4}, {4, 4}, {4, 4}, {4, 4}})
+
+    want := map[Rect]bool{{0, 0, 4, 4}: true, {6, 0, 4, 4}: true, {0, 6, 4, 4}: true, {6, 6, 4, 4}: true}
+    for _, r := range rects {
+        if !want[r] {
+            t.Errorf("unexpected placement %+v", r)
+        }
+    }
+
+    if _, ok := p.Pack(1, 1); ok {
+        t.Error("packed into a full area")
+    }
+}
+
+func TestPackTooLarge(t *testing.T) {
+    tests := []struct {
+        width, height int
+    }{
+        {11, 1},
+        {1, 11},
+        {-1, 1},
+        {1, -1},
+    }
+
+    for _, test := range tests {
+        p := NewPacker(10, 10, 0)
+        if r, ok := p.Pack(test.width, test.height); ok {
+            t.Errorf("%dx%d: packed at %+v", test.width, test.height, r)
+        }
+    }
+}
+
+func TestPackNoOverlap(t *testing.T) {
+    rng := rand.New(rand.NewSource(1))
+
+    for _, padding := range []int{0, 1, 3} {
+        t.Run(fmt.Sprintf("padding %d", padding), func(t *testing.T) {
+            p := NewPacker(256, 256, padding)
+
+            var rects []Rect
+            for i := 0; i < 500; i++ {
+                r, ok := p.Pack(1+rng.Intn(24), 1+rng.Intn(24))
+                if !ok {
+                    continue
+                }
+
+                if r.X < 0 || r.Y < 0 || r.MaxX() > 256 || r.MaxY() > 256 {
+                    t.Fatalf("rect outside the area: %+v", r)
+                }
+
+                // grown by the padding, no two rects may touch
+                padded := Rect{r.X, r.Y, r.W + padding, r.H + padding}
+                for _, o := range rects {
+                    if padded.Overlaps(Rect{o.X, o.Y, o.W + padding, o.H + padding}) {
+                        t.Fatalf("rects closer than the padding: %+v and %+v", r, o)
+                    }
+                }
+
+                rects = append(rects, r)
+            }
+
+            if len(rects) < 100 {
+                t.Errorf("only %d rects packed", len(rects))
+            }
+        })
+    }
+}
+
+func TestBuilderZeroValue(t *testing.T) {
+    b := Builder{MaxSize: 64}
+
+    if err := b.Add("a", image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+        t.Fatal(err)
+    }
+    if err := b.Add("a", image.NewRGBA(image.Rect(0, 0, 8, 8))); err == nil {
+        t.Error("duplicate name accepted")
+    }
+
+    atlas, err := b.Build()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if r := atlas.Regions["a"]; r.Rect != (Rect{0, 0, 8, 8}) || r.U1 != 1 || r.V1 != 1 {
+        t.Errorf("got region %+v", r)
+    }
+}
+
+func TestBuilderMaxSize(t *testing.T) {
+    tests := []struct {
+        maxSize int
+        sizes   [][2]int
+        width   int
+        height  int
+        ok      bool
+    }{
+        // a non power of two maximum is used when the power of two below it is too small
+        {maxSize: 100, sizes: [][2]int{{100, 100}}, width: 100, height: 100, ok: true},
+        {maxSize: 100, sizes: [][2]int{{70, 10}}, width: 100, height: 64, ok: true},
+        {maxSize: 64, sizes: [][2]int{{60, 60}}, width: 64, height: 64, ok: true},
+        {maxSize: 100, sizes: [][2]int{{101, 1}}, ok: false},
+        {maxSize: 100, sizes: [][2]int{{60, 60}, {60, 60}}, ok: false},
+    }
+
+    for i, test := range tests {
+        b := NewBuilder(test.maxSize, 1)
+        for j, size := range test.sizes {
+            if err := b.Add(fmt.Sprint(j), image.NewRGBA(image.Rect(0, 0, size[0], size[1]))); err != nil {
+                t.Fatal(err)
+            }
+        }
+
+        atlas, err := b.Build()
+        if !test.ok {
+            if err == nil {
+                t.Errorf("test %d: built a %v atlas, want an error", i, atlas.Image.Bounds().Size())
+            }
+            continue
+        }
+
+        if err != nil {
+            t.Errorf("test %d: %v", i, err)
+            continue
+        }
+
+        if size := atlas.Image.Bounds().Size(); size.X != test.width || size.Y != test.height {
+            t.Errorf("test %d: got a %dx%d atlas, want %dx%d", i, size.X, size.Y, test.width, test.height)
+        }
+    }
+}
@@ -0,0 +1,114 @@
+package render
+
+import (
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "reflect"
+    "unsafe"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Buffers and Vertex Arrays
+// --------------------------------------------------------------------------------------------------------
+
+// the binding point of a buffer
+type BufferTarget uint32
+
+const (
+    ArrayBuffer        BufferTarget = gl.ARRAY_BUFFER
+    ElementArrayBuffer BufferTarget = gl.ELEMENT_ARRAY_BUFFER
+)
+
+// hints how the buffer contents will be updated and used
+type BufferUsage uint32
+
+const (
+    StaticDraw  BufferUsage = gl.STATIC_DRAW
+    DynamicDraw BufferUsage = gl.DYNAMIC_DRAW
+    StreamDraw  BufferUsage = gl.STREAM_DRAW
+)
+
+// holds a buffer object reference (vbo / ebo)
+type Buffer struct {
+    Target BufferTarget
+    ptr    uint32
+    res    *resource
+}
+
+// Creates a new buffer for the given target
+func NewBuffer(target BufferTarget) *Buffer {
+    var buffer uint32
+    gl.GenBuffers(1, &buffer)
+
+    b := &Buffer{Target: target, ptr: buffer}
+    b.res = track(b, bufferResource, buffer)
+
+    return b
+}
+
+// binds the buffer to its target
+func (b *Buffer) Bind() {
+    gl.BindBuffer(uint32(b.Target), b.ptr)
+}
+
+// binds and (re)allocates the buffer with size bytes copied from data - data is anything accepted by gl.Ptr (a
+// pointer or slice) or nil, or an empty slice, to leave the contents undefined
+func (b *Buffer) Data(size int, data interface{}, usage BufferUsage) {
+    b.Bind()
+    gl.BufferData(uint32(b.Target), size, dataPtr(data), uint32(usage))
+}
+
+// binds the buffer and replaces size bytes starting at the given byte offset, doing nothing for an empty slice
+func (b *Buffer) SubData(offset, size int, data interface{}) {
+    ptr := dataPtr(data)
+    if ptr == nil {
+        return
+    }
+
+    b.Bind()
+    gl.BufferSubData(uint32(b.Target), offset, size, ptr)
+}
+
+// the address of the buffer data, nil for nil or an empty slice which gl.Ptr panics on
+func dataPtr(data interface{}) unsafe.Pointer {
+    if data == nil {
+        return nil
+    }
+
+    if v := reflect.ValueOf(data); v.Kind() == reflect.Slice && v.Len() == 0 {
+        return nil
+    }
+
+    return gl.Ptr(data)
+}
+
+// deletes the underlying buffer
+func (b *Buffer) Delete() {
+    b.res.release()
+}
+
+// holds a vertex array object reference
+type VertexArray struct {
+    ptr uint32
+    res *resource
+}
+
+// Creates a new vertex array
+func NewVertexArray() *VertexArray {
+    var vao uint32
+    gl.GenVertexArrays(1, &vao)
+
+    v := &VertexArray{ptr: vao}
+    v.res = track(v, vertexArrayResource, vao)
+
+    return v
+}
+
+// binds the vertex array
+func (v *VertexArray) Bind() {
+    gl.BindVertexArray(v.ptr)
+}
+
+// deletes the underlying vertex array
+func (v *VertexArray) Delete() {
+    v.res.release()
+}
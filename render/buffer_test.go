@@ -0,0 +1,28 @@
+package render
+
+import (
+    "testing"
+)
+
+func TestDataPtr(t *testing.T) {
+    var none []float32
+
+    tests := []struct {
+        name string
+        data interface{}
+        nil  bool
+    }{
+        {"nil", nil, true},
+        {"nil slice", none, true},
+        {"empty floats", []float32{}, true},
+        {"empty indices", []uint16{}, true},
+        {"floats", []float32{1, 2, 3}, false},
+        {"indices", []uint32{0, 1, 2}, false},
+    }
+
+    for _, test := range tests {
+        if ptr := dataPtr(test.data); (ptr == nil) != test.nil {
+            t.Errorf("%s: pointer %v, expected nil = %v", test.name, ptr, test.nil)
+        }
+    }
+}
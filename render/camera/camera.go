@@ -0,0 +1,78 @@
+// Package camera provides a free-fly (first person) camera and an orbit camera, both producing view and projection
+// matrices, along with controllers wiring them to window input.
+package camera
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// anything able to produce the view and projection matrices for drawing a scene
+type Camera interface {
+    // the world to view space transform
+    View() mgl32.Mat4
+
+    // the view to clip space transform for a viewport with the given width / height ratio
+    Projection(aspect float32) mgl32.Mat4
+}
+
+// the world up direction used by the cameras unless overridden
+var WorldUp = mgl32.Vec3{0, 1, 0}
+
+// perspective projection settings shared by the cameras
+type Lens struct {
+    // the vertical field of view in degrees
+    FOV float32
+
+    // the field of view limits applied when zooming
+    MinFOV float32
+    MaxFOV float32
+
+    // the near and far clip plane distances
+    Near float32
+    Far  float32
+}
+
+// a 45 degree lens with clip planes at 0.1 and 100
+func DefaultLens() Lens {
+    return Lens{
+        FOV:    45,
+        MinFOV: 1,
+        MaxFOV: 90,
+        Near:   0.1,
+        Far:    100,
+    }
+}
+
+// the perspective projection for a viewport with the given width / height ratio
+func (l *Lens) Projection(aspect float32) mgl32.Mat4 {
+    return mgl32.Perspective(mgl32.DegToRad(l.FOV), aspect, l.Near, l.Far)
+}
+
+// narrows (positive offset) or widens (negative offset) the field of view, clamped to the lens limits
+func (l *Lens) Zoom(offset float32) {
+    l.FOV = clamp(l.FOV-offset, l.MinFOV, l.MaxFOV)
+}
+
+// the unit direction for the given yaw and pitch in degrees - a yaw of 0 looks down +X, -90 down -Z
+func direction(yaw, pitch float32) mgl32.Vec3 {
+    y, p := float64(mgl32.DegToRad(yaw)), float64(mgl32.DegToRad(pitch))
+
+    return mgl32.Vec3{
+        float32(math.Cos(y) * math.Cos(p)),
+        float32(math.Sin(p)),
+        float32(math.Sin(y) * math.Cos(p)),
+    }.Normalize()
+}
+
+func clamp(v, min, max float32) float32 {
+    if v < min {
+        return min
+    }
+
+    if v > max {
+        return max
+    }
+
+    return v
+}
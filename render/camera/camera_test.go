@@ -0,0 +1,205 @@
+package camera
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "testing"
+)
+
+const epsilon = 1e-4
+
+func vecNear(a, b mgl32.Vec3) bool {
+    return a.Sub(b).Len() < epsilon
+}
+
+func TestDirection(t *testing.T) {
+    tests := []struct {
+        yaw, pitch float32
+        want       mgl32.Vec3
+    }{
+        {0, 0, mgl32.Vec3{1, 0, 0}},
+        {-90, 0, mgl32.Vec3{0, 0, -1}},
+        {90, 0, mgl32.Vec3{0, 0, 1}},
+        {180, 0, mgl32.Vec3{-1, 0, 0}},
+        {-90, 90, mgl32.Vec3{0, 1, 0}},
+        {0, -45, mgl32.Vec3{0.70710677, -0.70710677, 0}},
+    }
+
+    for _, test := range tests {
+        if got := direction(test.yaw, test.pitch); !vecNear(got, test.want) {
+            t.Errorf("direction(%v, %v) = %v, want %v", test.yaw, test.pitch, got, test.want)
+        }
+    }
+}
+
+func TestFlyAxes(t *testing.T) {
+    c := NewFly(mgl32.Vec3{1, 2, 3})
+
+    if !vecNear(c.Front(), mgl32.Vec3{0, 0, -1}) {
+        t.Errorf("front = %v, want -Z", c.Front())
+    }
+    if !vecNear(c.Right(), mgl32.Vec3{1, 0, 0}) {
+        t.Errorf("right = %v, want +X", c.Right())
+    }
+    if !vecNear(c.Up(), mgl32.Vec3{0, 1, 0}) {
+        t.Errorf("up = %v, want +Y", c.Up())
+    }
+
+    // a point straight ahead ends up on the view axis
+    ahead := c.Position.Add(c.Front().Mul(5))
+    if got := mgl32.TransformCoordinate(ahead, c.View()); !vecNear(got, mgl32.Vec3{0, 0, -5}) {
+        t.Errorf("view of a point ahead = %v, want (0, 0, -5)", got)
+    }
+}
+
+func TestFlyLook(t *testing.T) {
+    tests := []struct {
+        dx, dy     float32
+        yaw, pitch float32
+    }{
+        {0, 0, -90, 0},
+        {100, 0, -80, 0},
+        {-100, 50, -100, 5},
+        {0, 10000, -90, 89},
+        {0, -10000, -90, -89},
+    }
+
+    for _, test := range tests {
+        c := NewFly(mgl32.Vec3{})
+        c.Look(test.dx, test.dy)
+
+        if !mgl32.FloatEqualThreshold(c.Yaw, test.yaw, epsilon) || !mgl32.FloatEqualThreshold(c.Pitch, test.pitch, epsilon) {
+            t.Errorf("Look(%v, %v): yaw, pitch = %v, %v, want %v, %v", test.dx, test.dy, c.Yaw, c.Pitch,
+                test.yaw, test.pitch)
+        }
+    }
+
+    // the pitch clamp keeps the view from flipping, so up stays above the horizon
+    c := NewFly(mgl32.Vec3{})
+    c.Look(0, 10000)
+    if c.Up().Y() <= 0 {
+        t.Errorf("up = %v at the pitch limit, want a positive Y", c.Up())
+    }
+}
+
+func TestFlyMove(t *testing.T) {
+    tests := []struct {
+        movement Movement
+        want     mgl32.Vec3
+    }{
+        {Forward, mgl32.Vec3{0, 0, -5}},
+        {Backward, mgl32.Vec3{0, 0, 5}},
+        {Left, mgl32.Vec3{-5, 0, 0}},
+        {Right, mgl32.Vec3{5, 0, 0}},
+        {Up, mgl32.Vec3{0, 5, 0}},
+        {Down, mgl32.Vec3{0, -5, 0}},
+    }
+
+    for _, test := range tests {
+        c := NewFly(mgl32.Vec3{})
+        c.Speed = 2.5
+        c.Move(test.movement, 2)
+
+        if !vecNear(c.Position, test.want) {
+            t.Errorf("Move(%v) = %v, want %v", test.movement, c.Position, test.want)
+        }
+    }
+}
+
+func TestFlyLookAt(t *testing.T) {
+    c := NewFly(mgl32.Vec3{1, 1, 1})
+    target := mgl32.Vec3{4, 5, -2}
+
+    c.LookAt(target)
+
+    want := target.Sub(c.Position).Normalize()
+    if !vecNear(c.Front(), want) {
+        t.Errorf("front = %v after LookAt, want %v", c.Front(), want)
+    }
+}
+
+func TestLensZoom(t *testing.T) {
+    tests := []struct {
+        offset float32
+        want   float32
+    }{
+        {0, 45},
+        {5, 40},
+        {-5, 50},
+        {100, 1},
+        {-100, 90},
+    }
+
+    for _, test := range tests {
+        l := DefaultLens()
+        l.Zoom(test.offset)
+
+        if l.FOV != test.want {
+            t.Errorf("Zoom(%v): fov = %v, want %v", test.offset, l.FOV, test.want)
+        }
+    }
+}
+
+func TestOrbitPosition(t *testing.T) {
+    target := mgl32.Vec3{1, 2, 3}
+
+    tests := []struct {
+        yaw, pitch, distance float32
+        want                 mgl32.Vec3
+    }{
+        {90, 0, 5, mgl32.Vec3{1, 2, 8}},
+        {0, 0, 5, mgl32.Vec3{6, 2, 3}},
+        {-90, 0, 2, mgl32.Vec3{1, 2, 1}},
+        {90, 30, 4, mgl32.Vec3{1, 4, 3 + 3.4641016}},
+    }
+
+    for _, test := range tests {
+        c := NewOrbit(target, test.distance)
+        c.Yaw, c.Pitch = test.yaw, test.pitch
+
+        if got := c.Position(); !vecNear(got, test.want) {
+            t.Errorf("yaw %v, pitch %v, distance %v: position = %v, want %v", test.yaw, test.pitch, test.distance,
+                got, test.want)
+        }
+
+        // the target is always straight ahead at the orbit distance
+        if got := mgl32.TransformCoordinate(target, c.View()); !vecNear(got, mgl32.Vec3{0, 0, -test.distance}) {
+            t.Errorf("yaw %v, pitch %v: view of the target = %v", test.yaw, test.pitch, got)
+        }
+    }
+}
+
+func TestOrbitRotateClamp(t *testing.T) {
+    c := NewOrbit(mgl32.Vec3{}, 5)
+
+    c.Rotate(0, 10000)
+    if c.Pitch != c.MaxPitch {
+        t.Errorf("pitch = %v, want %v", c.Pitch, c.MaxPitch)
+    }
+
+    c.Rotate(0, -20000)
+    if c.Pitch != -c.MaxPitch {
+        t.Errorf("pitch = %v, want %v", c.Pitch, -c.MaxPitch)
+    }
+}
+
+func TestOrbitZoom(t *testing.T) {
+    tests := []struct {
+        offset float32
+        want   float32
+    }{
+        {0, 10},
+        {1, 9},
+        {-1, 11},
+        {1000, 0.1},
+        {-100000, 1000},
+    }
+
+    for _, test := range tests {
+        c := NewOrbit(mgl32.Vec3{}, 10)
+        c.Zoom(test.offset)
+
+        if !mgl32.FloatEqualThreshold(c.Distance, test.want, epsilon) {
+            t.Errorf("Zoom(%v): distance = %v, want %v", test.offset, c.Distance, test.want)
+        }
+    }
+}
@@ -0,0 +1,146 @@
+package camera
+
+import (
+    "github.com/go-gl/glfw/v3.3/glfw"
+    "logl/render"
+)
+
+// the keys driving a fly camera
+type FlyKeys struct {
+    Forward  glfw.Key
+    Backward glfw.Key
+    Left     glfw.Key
+    Right    glfw.Key
+    Up       glfw.Key
+    Down     glfw.Key
+}
+
+// WASD to move, space and left shift to rise and fall
+var DefaultFlyKeys = FlyKeys{
+    Forward:  glfw.KeyW,
+    Backward: glfw.KeyS,
+    Left:     glfw.KeyA,
+    Right:    glfw.KeyD,
+    Up:       glfw.KeySpace,
+    Down:     glfw.KeyLeftShift,
+}
+
+// tracks cursor movement between callbacks
+type cursorTracker struct {
+    x, y  float64
+    moved bool
+}
+
+// the movement since the last position, with y flipped so up is positive
+func (t *cursorTracker) delta(x, y float64) (float32, float32) {
+    if !t.moved {
+        t.x, t.y, t.moved = x, y, true
+        return 0, 0
+    }
+
+    dx, dy := float32(x-t.x), float32(t.y-y)
+    t.x, t.y = x, y
+
+    return dx, dy
+}
+
+// FlyController drives a fly camera from the keyboard and mouse - keys move, the mouse looks and scrolling zooms.
+// With the cursor captured the mouse always looks, otherwise only while the right button is held.
+type FlyController struct {
+    Camera *Fly
+    Keys   FlyKeys
+
+    window   *render.Window
+    cursor   cursorTracker
+    lastTime float64
+}
+
+// Creates a controller for the camera listening to the window input, optionally capturing the cursor
+func NewFlyController(window *render.Window, camera *Fly, capture bool) *FlyController {
+    c := &FlyController{
+        Camera:   camera,
+        Keys:     DefaultFlyKeys,
+        window:   window,
+        lastTime: window.Time(),
+    }
+
+    window.CaptureCursor(capture)
+
+    window.OnCursorMove(func(x, y float64) {
+        dx, dy := c.cursor.delta(x, y)
+
+        if c.window.IsCursorCaptured() || c.window.IsMousePressed(glfw.MouseButtonRight) {
+            c.Camera.Look(dx, dy)
+        }
+    })
+
+    window.OnScroll(func(_, y float64) {
+        c.Camera.Zoom(float32(y))
+    })
+
+    return c
+}
+
+// moves the camera for any held keys, scaled by the time since the previous update - call once per frame
+func (c *FlyController) Update() {
+    now := c.window.Time()
+    seconds := float32(now - c.lastTime)
+    c.lastTime = now
+
+    moves := []struct {
+        key      glfw.Key
+        movement Movement
+    }{
+        {c.Keys.Forward, Forward},
+        {c.Keys.Backward, Backward},
+        {c.Keys.Left, Left},
+        {c.Keys.Right, Right},
+        {c.Keys.Up, Up},
+        {c.Keys.Down, Down},
+    }
+
+    for _, m := range moves {
+        if c.window.IsPressed(m.key) {
+            c.Camera.Move(m.movement, seconds)
+        }
+    }
+}
+
+// OrbitController drives an orbit camera from the mouse - dragging with the left button circles the target, with the
+// right button pans and scrolling zooms
+type OrbitController struct {
+    Camera *Orbit
+
+    // the fraction of the distance panned per unit of cursor movement
+    PanSpeed float32
+
+    window *render.Window
+    cursor cursorTracker
+}
+
+// Creates a controller for the camera listening to the window input
+func NewOrbitController(window *render.Window, camera *Orbit) *OrbitController {
+    c := &OrbitController{
+        Camera:   camera,
+        PanSpeed: 0.002,
+        window:   window,
+    }
+
+    window.OnCursorMove(func(x, y float64) {
+        dx, dy := c.cursor.delta(x, y)
+
+        switch {
+        case c.window.IsMousePressed(glfw.MouseButtonLeft):
+            c.Camera.Rotate(dx, -dy)
+        case c.window.IsMousePressed(glfw.MouseButtonRight):
+            scale := c.Camera.Distance * c.PanSpeed
+            c.Camera.Pan(-dx*scale, -dy*scale)
+        }
+    })
+
+    window.OnScroll(func(_, y float64) {
+        c.Camera.Zoom(float32(y))
+    })
+
+    return c
+}
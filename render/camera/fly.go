@@ -0,0 +1,110 @@
+package camera
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// the directions a fly camera can move in, relative to where it is looking
+type Movement int
+
+const (
+    Forward Movement = iota
+    Backward
+    Left
+    Right
+    Up
+    Down
+)
+
+// Fly is a first person style camera positioned in the world and pointed with yaw and pitch angles
+type Fly struct {
+    Lens
+
+    Position mgl32.Vec3
+
+    // the heading and elevation in degrees - a yaw of -90 looks down -Z
+    Yaw   float32
+    Pitch float32
+
+    // the pitch limit in degrees either side of the horizon, stops the view flipping over the poles
+    MaxPitch float32
+
+    // movement in world units per second
+    Speed float32
+
+    // degrees turned per unit of cursor movement
+    Sensitivity float32
+}
+
+// Creates a fly camera at the given position looking down -Z
+func NewFly(position mgl32.Vec3) *Fly {
+    return &Fly{
+        Lens:        DefaultLens(),
+        Position:    position,
+        Yaw:         -90,
+        MaxPitch:    89,
+        Speed:       2.5,
+        Sensitivity: 0.1,
+    }
+}
+
+// the unit direction the camera is looking in
+func (c *Fly) Front() mgl32.Vec3 {
+    return direction(c.Yaw, c.Pitch)
+}
+
+// the unit direction to the right of the camera, always horizontal
+func (c *Fly) Right() mgl32.Vec3 {
+    return c.Front().Cross(WorldUp).Normalize()
+}
+
+// the unit direction above the camera, perpendicular to Front and Right
+func (c *Fly) Up() mgl32.Vec3 {
+    return c.Right().Cross(c.Front()).Normalize()
+}
+
+// the world to view space transform
+func (c *Fly) View() mgl32.Mat4 {
+    return mgl32.LookAtV(c.Position, c.Position.Add(c.Front()), c.Up())
+}
+
+// moves the camera at its speed for the given number of seconds. Forward and backward follow the view direction
+// (including pitch), up and down follow the world up.
+func (c *Fly) Move(movement Movement, seconds float32) {
+    distance := c.Speed * seconds
+
+    switch movement {
+    case Forward:
+        c.Position = c.Position.Add(c.Front().Mul(distance))
+    case Backward:
+        c.Position = c.Position.Sub(c.Front().Mul(distance))
+    case Left:
+        c.Position = c.Position.Sub(c.Right().Mul(distance))
+    case Right:
+        c.Position = c.Position.Add(c.Right().Mul(distance))
+    case Up:
+        c.Position = c.Position.Add(WorldUp.Mul(distance))
+    case Down:
+        c.Position = c.Position.Sub(WorldUp.Mul(distance))
+    }
+}
+
+// turns the camera by the given cursor movement (positive dy looks up), scaled by the sensitivity with the pitch
+// clamped to MaxPitch
+func (c *Fly) Look(dx, dy float32) {
+    c.Yaw += dx * c.Sensitivity
+    c.Pitch = clamp(c.Pitch+dy*c.Sensitivity, -c.MaxPitch, c.MaxPitch)
+}
+
+// points the camera at the given world position
+func (c *Fly) LookAt(target mgl32.Vec3) {
+    dir := target.Sub(c.Position)
+    if dir.Len() == 0 {
+        return
+    }
+
+    dir = dir.Normalize()
+    c.Pitch = clamp(mgl32.RadToDeg(float32(math.Asin(float64(dir.Y())))), -c.MaxPitch, c.MaxPitch)
+    c.Yaw = mgl32.RadToDeg(float32(math.Atan2(float64(dir.Z()), float64(dir.X()))))
+}
@@ -0,0 +1,89 @@
+package camera
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+)
+
+// Orbit is an arcball style camera circling a target point at a distance
+type Orbit struct {
+    Lens
+
+    Target mgl32.Vec3
+
+    // distance from the target and its limits when zooming
+    Distance    float32
+    MinDistance float32
+    MaxDistance float32
+
+    // the angle around the target and the elevation above its horizon, in degrees. A yaw of 90 places the camera
+    // on the +Z side of the target.
+    Yaw   float32
+    Pitch float32
+
+    // the pitch limit in degrees either side of the horizon
+    MaxPitch float32
+
+    // degrees turned per unit of cursor movement
+    Sensitivity float32
+
+    // the fraction of the distance moved per unit of scrolling
+    ZoomSpeed float32
+}
+
+// Creates an orbit camera looking at the target from the given distance along +Z
+func NewOrbit(target mgl32.Vec3, distance float32) *Orbit {
+    return &Orbit{
+        Lens:        DefaultLens(),
+        Target:      target,
+        Distance:    distance,
+        MinDistance: 0.1,
+        MaxDistance: 1000,
+        Yaw:         90,
+        MaxPitch:    89,
+        Sensitivity: 0.25,
+        ZoomSpeed:   0.1,
+    }
+}
+
+// the world position of the camera
+func (c *Orbit) Position() mgl32.Vec3 {
+    return c.Target.Add(direction(c.Yaw, c.Pitch).Mul(c.Distance))
+}
+
+// the unit direction the camera is looking in
+func (c *Orbit) Front() mgl32.Vec3 {
+    return direction(c.Yaw, c.Pitch).Mul(-1)
+}
+
+// the unit direction to the right of the camera, always horizontal
+func (c *Orbit) Right() mgl32.Vec3 {
+    return c.Front().Cross(WorldUp).Normalize()
+}
+
+// the unit direction above the camera, perpendicular to Front and Right
+func (c *Orbit) Up() mgl32.Vec3 {
+    return c.Right().Cross(c.Front()).Normalize()
+}
+
+// the world to view space transform
+func (c *Orbit) View() mgl32.Mat4 {
+    return mgl32.LookAtV(c.Position(), c.Target, c.Up())
+}
+
+// circles the target by the given cursor movement (positive dy moves the camera up), scaled by the sensitivity with
+// the pitch clamped to MaxPitch
+func (c *Orbit) Rotate(dx, dy float32) {
+    c.Yaw += dx * c.Sensitivity
+    c.Pitch = clamp(c.Pitch+dy*c.Sensitivity, -c.MaxPitch, c.MaxPitch)
+}
+
+// moves towards (positive offset) or away from the target by a fraction of the current distance, clamped to the
+// distance limits
+func (c *Orbit) Zoom(offset float32) {
+    c.Distance = clamp(c.Distance*(1-offset*c.ZoomSpeed), c.MinDistance, c.MaxDistance)
+}
+
+// slides the target (and so the camera) across the view plane by the given amounts in world units
+func (c *Orbit) Pan(dx, dy float32) {
+    c.Target = c.Target.Add(c.Right().Mul(dx)).Add(c.Up().Mul(dy))
+}
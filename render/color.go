@@ -0,0 +1,348 @@
+package render
+
+import (
+    "fmt"
+    "image/color"
+    "math"
+    "strconv"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Color
+// --------------------------------------------------------------------------------------------------------
+
+// holds a colour as straight (non premultiplied) RGBA components, nominally in the range 0 to 1
+type Color struct{ r, g, b, a float32 }
+
+// Creates an opaque colour from the red, green and blue components
+func RGB(r, g, b float32) Color {
+    return Color{r, g, b, 1}
+}
+
+// Creates a colour from the red, green, blue and alpha components
+func RGBA(r, g, b, a float32) Color {
+    return Color{r, g, b, a}
+}
+
+// Creates a colour from 8-bit components
+func RGBA8(r, g, b, a uint8) Color {
+    return Color{float32(r) / 255, float32(g) / 255, float32(b) / 255, float32(a) / 255}
+}
+
+// Creates a colour from any image/color colour
+func FromColor(c color.Color) Color {
+    n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+
+    return Color{
+        float32(n.R) / 0xffff,
+        float32(n.G) / 0xffff,
+        float32(n.B) / 0xffff,
+        float32(n.A) / 0xffff,
+    }
+}
+
+// Parses a hex colour in the form #rgb, #rgba, #rrggbb or #rrggbbaa (the leading # is optional)
+func ParseHex(hex string) (Color, error) {
+    digits := strings.TrimPrefix(hex, "#")
+
+    switch len(digits) {
+    case 3, 4:
+        // expand the shorthand form so each digit is doubled
+        var sb strings.Builder
+        for _, d := range digits {
+            sb.WriteRune(d)
+            sb.WriteRune(d)
+        }
+        digits = sb.String()
+    case 6, 8:
+    default:
+        return Color{}, fmt.Errorf("invalid hex colour: value = %s", hex)
+    }
+
+    if len(digits) == 6 {
+        digits += "ff"
+    }
+
+    value, err := strconv.ParseUint(digits, 16, 32)
+    if err != nil {
+        return Color{}, fmt.Errorf("invalid hex colour: value = %s", hex)
+    }
+
+    return RGBA8(uint8(value>>24), uint8(value>>16), uint8(value>>8), uint8(value)), nil
+}
+
+// Parses either the name of a colour in the Palette, ignoring case, or a hex colour (see ParseHex) with or without
+// the leading # - names are tried first, for those that are also valid hex
+func ParseColor(value string) (Color, error) {
+    if c, ok := Palette[strings.ToLower(value)]; ok {
+        return c, nil
+    }
+
+    if c, err := ParseHex(value); err == nil {
+        return c, nil
+    }
+
+    return Color{}, fmt.Errorf("unknown colour: value = %s", value)
+}
+
+// Creates an opaque colour from hue (degrees), saturation and value (0 to 1)
+func HSV(h, s, v float32) Color {
+    c := v * s
+    return hueToRGB(h, c, v-c)
+}
+
+// Creates an opaque colour from hue (degrees), saturation and lightness (0 to 1)
+func HSL(h, s, l float32) Color {
+    c := (1 - float32(math.Abs(float64(2*l-1)))) * s
+    return hueToRGB(h, c, l-c/2)
+}
+
+// builds a colour from the hue with the given chroma, offset by m in every channel
+func hueToRGB(h, c, m float32) Color {
+    h = float32(math.Mod(float64(h), 360))
+    if h < 0 {
+        h += 360
+    }
+
+    sector := h / 60
+    x := c * (1 - float32(math.Abs(math.Mod(float64(sector), 2)-1)))
+
+    var r, g, b float32
+    switch int(sector) {
+    case 0:
+        r, g, b = c, x, 0
+    case 1:
+        r, g, b = x, c, 0
+    case 2:
+        r, g, b = 0, c, x
+    case 3:
+        r, g, b = 0, x, c
+    case 4:
+        r, g, b = x, 0, c
+    default:
+        r, g, b = c, 0, x
+    }
+
+    return Color{r + m, g + m, b + m, 1}
+}
+
+func (c Color) R() float32 { return c.r }
+func (c Color) G() float32 { return c.g }
+func (c Color) B() float32 { return c.b }
+func (c Color) A() float32 { return c.a }
+
+// the colour with the alpha replaced
+func (c Color) WithAlpha(a float32) Color {
+    c.a = a
+    return c
+}
+
+// Creates a colour from a vector in RGBA order
+func ColorFromVec4(v Vec4) Color {
+    return Color{v[R], v[G], v[B], v[A]}
+}
+
+// Creates an opaque colour from a vector in RGB order
+func ColorFromVec3(v Vec3) Color {
+    return Color{v[R], v[G], v[B], 1}
+}
+
+// the components as a vector in RGBA order
+func (c Color) Vec4() Vec4 {
+    return Vec4{c.r, c.g, c.b, c.a}
+}
+
+// the red, green and blue components as a vector
+func (c Color) Vec3() Vec3 {
+    return Vec3{c.r, c.g, c.b}
+}
+
+// the components clamped to the range 0 to 1
+func (c Color) Clamp() Color {
+    return Color{clamp01(c.r), clamp01(c.g), clamp01(c.b), clamp01(c.a)}
+}
+
+// the components as 8-bit values, clamped and rounded
+func (c Color) RGBA8() (r, g, b, a uint8) {
+    to8 := func(v float32) uint8 {
+        return uint8(clamp01(v)*255 + 0.5)
+    }
+
+    return to8(c.r), to8(c.g), to8(c.b), to8(c.a)
+}
+
+// the colour as #rrggbbaa
+func (c Color) Hex() string {
+    r, g, b, a := c.RGBA8()
+    return fmt.Sprintf("#%02x%02x%02x%02x", r, g, b, a)
+}
+
+func (c Color) String() string {
+    return fmt.Sprintf("Color{r = %g, g = %g, b = %g, a = %g}", c.r, c.g, c.b, c.a)
+}
+
+// RGBA implements color.Color, returning the alpha premultiplied components scaled to 16 bits
+func (c Color) RGBA() (r, g, b, a uint32) {
+    cl := c.Clamp()
+
+    a = uint32(cl.a*0xffff + 0.5)
+    r = uint32(cl.r*cl.a*0xffff + 0.5)
+    g = uint32(cl.g*cl.a*0xffff + 0.5)
+    b = uint32(cl.b*cl.a*0xffff + 0.5)
+
+    return r, g, b, a
+}
+
+// the hue (degrees), saturation and value
+func (c Color) HSV() (h, s, v float32) {
+    max, min := c.maxMin()
+    v = max
+
+    if max > 0 {
+        s = (max - min) / max
+    }
+
+    return c.hue(max, min), s, v
+}
+
+// the hue (degrees), saturation and lightness
+func (c Color) HSL() (h, s, l float32) {
+    max, min := c.maxMin()
+    l = (max + min) / 2
+
+    if d := max - min; d > 0 {
+        s = d / (1 - float32(math.Abs(float64(2*l-1))))
+    }
+
+    return c.hue(max, min), s, l
+}
+
+func (c Color) maxMin() (float32, float32) {
+    max := float32(math.Max(float64(c.r), math.Max(float64(c.g), float64(c.b))))
+    min := float32(math.Min(float64(c.r), math.Min(float64(c.g), float64(c.b))))
+
+    return max, min
+}
+
+func (c Color) hue(max, min float32) float32 {
+    d := max - min
+    if d == 0 {
+        return 0
+    }
+
+    var h float32
+    switch max {
+    case c.r:
+        h = (c.g - c.b) / d
+        if h < 0 {
+            h += 6
+        }
+    case c.g:
+        h = (c.b-c.r)/d + 2
+    default:
+        h = (c.r-c.g)/d + 4
+    }
+
+    return h * 60
+}
+
+// converts the colour from sRGB to linear light, alpha is unchanged
+func (c Color) ToLinear() Color {
+    return Color{srgbToLinear(c.r), srgbToLinear(c.g), srgbToLinear(c.b), c.a}
+}
+
+// converts the colour from linear light to sRGB, alpha is unchanged
+func (c Color) ToSRGB() Color {
+    return Color{linearToSRGB(c.r), linearToSRGB(c.g), linearToSRGB(c.b), c.a}
+}
+
+func srgbToLinear(v float32) float32 {
+    if v <= 0.04045 {
+        return v / 12.92
+    }
+
+    return float32(math.Pow((float64(v)+0.055)/1.055, 2.4))
+}
+
+func linearToSRGB(v float32) float32 {
+    if v <= 0.0031308 {
+        return v * 12.92
+    }
+
+    return float32(1.055*math.Pow(float64(v), 1/2.4) - 0.055)
+}
+
+// linearly interpolates every component towards the other colour, t = 0 gives this colour and t = 1 the other
+func (c Color) Lerp(other Color, t float32) Color {
+    return Color{
+        c.r + (other.r-c.r)*t,
+        c.g + (other.g-c.g)*t,
+        c.b + (other.b-c.b)*t,
+        c.a + (other.a-c.a)*t,
+    }
+}
+
+// the colour with red, green and blue multiplied by alpha, for use with premultiplied blending
+func (c Color) Premultiply() Color {
+    return Color{c.r * c.a, c.g * c.a, c.b * c.a, c.a}
+}
+
+// the component wise product of the two colours
+func (c Color) Mul(other Color) Color {
+    return Color{c.r * other.r, c.g * other.g, c.b * other.b, c.a * other.a}
+}
+
+// the colour with red, green and blue scaled, alpha is unchanged
+func (c Color) Scale(s float32) Color {
+    return Color{c.r * s, c.g * s, c.b * s, c.a}
+}
+
+func clamp01(v float32) float32 {
+    if v < 0 {
+        return 0
+    }
+
+    if v > 1 {
+        return 1
+    }
+
+    return v
+}
+
+// named colours
+var (
+    White       = Color{1, 1, 1, 1}
+    Black       = Color{0, 0, 0, 1}
+    Transparent = Color{0, 0, 0, 0}
+    Red         = Color{1, 0, 0, 1}
+    Green       = Color{0, 1, 0, 1}
+    Blue        = Color{0, 0, 1, 1}
+    Yellow      = Color{1, 1, 0, 1}
+    Cyan        = Color{0, 1, 1, 1}
+    Magenta     = Color{1, 0, 1, 1}
+    Gray        = Color{0.5, 0.5, 0.5, 1}
+    Orange      = Color{1, 0.647, 0, 1}
+    Purple      = Color{0.502, 0, 0.502, 1}
+    Pink        = Color{1, 0.753, 0.796, 1}
+    Brown       = Color{0.647, 0.165, 0.165, 1}
+)
+
+// the named colours by lower case name, used by ParseColor
+var Palette = map[string]Color{
+    "white":       White,
+    "black":       Black,
+    "transparent": Transparent,
+    "red":         Red,
+    "green":       Green,
+    "blue":        Blue,
+    "yellow":      Yellow,
+    "cyan":        Cyan,
+    "magenta":     Magenta,
+    "gray":        Gray,
+    "grey":        Gray,
+    "orange":      Orange,
+    "purple":      Purple,
+    "pink":        Pink,
+    "brown":       Brown,
+}
@@ -0,0 +1,227 @@
+package render
+
+import (
+    "image/color"
+    "testing"
+)
+
+// whether every component of the colours is within the given distance
+func colorNear(a, b Color, epsilon float32) bool {
+    return a.Vec4().Sub(b.Vec4()).Len() < epsilon
+}
+
+func TestChannelOrder(t *testing.T) {
+    // a colour with every channel different, so swapped channels show
+    c := RGBA(0.1, 0.2, 0.3, 0.4)
+
+    if c.R() != 0.1 || c.G() != 0.2 || c.B() != 0.3 || c.A() != 0.4 {
+        t.Errorf("RGBA(0.1, 0.2, 0.3, 0.4) has components %v", c)
+    }
+
+    if v := c.Vec4(); v != (Vec4{0.1, 0.2, 0.3, 0.4}) {
+        t.Errorf("Vec4 = %v", v)
+    }
+
+    if v := RGB(0.1, 0.2, 0.3).Vec3(); v != (Vec3{0.1, 0.2, 0.3}) {
+        t.Errorf("Vec3 = %v", v)
+    }
+
+    if back := ColorFromVec4(c.Vec4()); back != c {
+        t.Errorf("ColorFromVec4 = %v, expected %v", back, c)
+    }
+
+    if r, g, b, a := RGBA8(10, 20, 30, 40).RGBA8(); r != 10 || g != 20 || b != 30 || a != 40 {
+        t.Errorf("RGBA8 round trip = %d, %d, %d, %d", r, g, b, a)
+    }
+}
+
+func TestParseColor(t *testing.T) {
+    tests := []struct {
+        value    string
+        expected Color
+        ok       bool
+    }{
+        {"#ff8000", RGBA8(255, 128, 0, 255), true},
+        {"ff8000", RGBA8(255, 128, 0, 255), true},
+        {"#f80", RGBA8(255, 136, 0, 255), true},
+        {"f80", RGBA8(255, 136, 0, 255), true},
+        {"#f808", RGBA8(255, 136, 0, 136), true},
+        {"#11223344", RGBA8(0x11, 0x22, 0x33, 0x44), true},
+        {"11223344", RGBA8(0x11, 0x22, 0x33, 0x44), true},
+        {"Red", Red, true},
+        {"grey", Gray, true},
+        {"TRANSPARENT", Transparent, true},
+
+        {"", Color{}, false},
+        {"#", Color{}, false},
+        {"#12", Color{}, false},
+        {"#12345", Color{}, false},
+        {"#1234567", Color{}, false},
+        {"#123456789", Color{}, false},
+        {"#gg0000", Color{}, false},
+        {"##ff0000", Color{}, false},
+        {"chartreuse", Color{}, false},
+    }
+
+    for _, test := range tests {
+        c, err := ParseColor(test.value)
+        if (err == nil) != test.ok {
+            t.Errorf("%q: error = %v, expected ok = %v", test.value, err, test.ok)
+            continue
+        }
+
+        if test.ok && !colorNear(c, test.expected, 1e-6) {
+            t.Errorf("%q: parsed %v, expected %v", test.value, c, test.expected)
+        }
+    }
+}
+
+func TestHex(t *testing.T) {
+    for _, hex := range []string{"#00000000", "#ff8000ff", "#12345678", "#fedcba98"} {
+        c, err := ParseHex(hex)
+        if err != nil {
+            t.Errorf("%s: %v", hex, err)
+            continue
+        }
+
+        if back := c.Hex(); back != hex {
+            t.Errorf("%s: formatted as %s", hex, back)
+        }
+    }
+}
+
+func TestHSV(t *testing.T) {
+    tests := []struct {
+        h, s, v  float32
+        expected Color
+    }{
+        {0, 1, 1, Red},
+        {120, 1, 1, Green},
+        {240, 1, 1, Blue},
+        {60, 1, 1, Yellow},
+        {300, 1, 1, Magenta},
+        {-60, 1, 1, Magenta},
+        {420, 1, 1, Yellow},
+        {0, 0, 0.5, Gray},
+        {30, 0.5, 0.8, RGB(0.8, 0.6, 0.4)},
+    }
+
+    for _, test := range tests {
+        c := HSV(test.h, test.s, test.v)
+        if !colorNear(c, test.expected, 1e-5) {
+            t.Errorf("HSV(%v, %v, %v) = %v, expected %v", test.h, test.s, test.v, c, test.expected)
+        }
+    }
+}
+
+func TestHSL(t *testing.T) {
+    tests := []struct {
+        h, s, l  float32
+        expected Color
+    }{
+        {0, 1, 0.5, Red},
+        {120, 1, 0.5, Green},
+        {240, 1, 0.5, Blue},
+        {0, 0, 1, White},
+        {0, 0, 0, Black},
+        {0, 1, 0.25, RGB(0.5, 0, 0)},
+        {30, 0.5, 0.6, RGB(0.8, 0.6, 0.4)},
+    }
+
+    for _, test := range tests {
+        c := HSL(test.h, test.s, test.l)
+        if !colorNear(c, test.expected, 1e-5) {
+            t.Errorf("HSL(%v, %v, %v) = %v, expected %v", test.h, test.s, test.l, c, test.expected)
+        }
+    }
+}
+
+func TestHSVAndHSLRoundTrip(t *testing.T) {
+    colors := []Color{Red, Green, Blue, Orange, Purple, Pink, Brown, RGB(0.1, 0.7, 0.4), RGB(0.9, 0.2, 0.6)}
+
+    for _, c := range colors {
+        if back := HSV(c.HSV()); !colorNear(back, c, 1e-5) {
+            h, s, v := c.HSV()
+            t.Errorf("%v: HSV %v, %v, %v converts back to %v", c, h, s, v, back)
+        }
+
+        if back := HSL(c.HSL()); !colorNear(back, c, 1e-5) {
+            h, s, l := c.HSL()
+            t.Errorf("%v: HSL %v, %v, %v converts back to %v", c, h, s, l, back)
+        }
+    }
+}
+
+func TestSRGB(t *testing.T) {
+    tests := []struct {
+        srgb, linear float32
+    }{
+        {0, 0},
+        {1, 1},
+        {0.04045, 0.04045 / 12.92},
+        {0.5, 0.2140411},
+        {0.2, 0.0331048},
+    }
+
+    for _, test := range tests {
+        c := RGBA(test.srgb, test.srgb, test.srgb, 0.5).ToLinear()
+        if !colorNear(c, RGBA(test.linear, test.linear, test.linear, 0.5), 1e-5) {
+            t.Errorf("%v to linear = %v, expected %v", test.srgb, c, test.linear)
+        }
+    }
+
+    for i := 0; i <= 20; i++ {
+        c := RGBA(float32(i)/20, 1-float32(i)/20, float32(i%3)/3, 0.25)
+
+        if back := c.ToLinear().ToSRGB(); !colorNear(back, c, 1e-5) {
+            t.Errorf("%v converts back from linear as %v", c, back)
+        }
+    }
+}
+
+func TestImageColor(t *testing.T) {
+    c := RGBA(1, 0.5, 0.25, 0.5)
+
+    // the color.Color components are premultiplied
+    r, g, b, a := c.RGBA()
+    if r != 0x8000 || g != 0x4000 || b != 0x2000 || a != 0x8000 {
+        t.Errorf("RGBA() = %x, %x, %x, %x", r, g, b, a)
+    }
+
+    if back := FromColor(c); !colorNear(back, c, 1e-4) {
+        t.Errorf("FromColor(%v) = %v", c, back)
+    }
+
+    if n := FromColor(color.NRGBA{R: 255, G: 128, B: 0, A: 255}); !colorNear(n, RGBA8(255, 128, 0, 255), 1e-4) {
+        t.Errorf("FromColor(NRGBA) = %v", n)
+    }
+}
+
+func TestColorArithmetic(t *testing.T) {
+    a, b := RGBA(0, 0.2, 0.4, 1), RGBA(1, 0.6, 0.4, 0)
+
+    tests := []struct {
+        name          string
+        got, expected Color
+    }{
+        {"lerp start", a.Lerp(b, 0), a},
+        {"lerp middle", a.Lerp(b, 0.5), RGBA(0.5, 0.4, 0.4, 0.5)},
+        {"lerp end", a.Lerp(b, 1), b},
+        {"premultiply", RGBA(1, 0.5, 0.2, 0.5).Premultiply(), RGBA(0.5, 0.25, 0.1, 0.5)},
+        {"mul", RGBA(1, 0.5, 0.2, 0.5).Mul(RGBA(0.5, 0.5, 0.5, 0.5)), RGBA(0.5, 0.25, 0.1, 0.25)},
+        {"scale", RGBA(1, 0.5, 0.2, 0.5).Scale(2), RGBA(2, 1, 0.4, 0.5)},
+        {"clamp", RGBA(2, -1, 0.5, 1.5).Clamp(), RGBA(1, 0, 0.5, 1)},
+        {"with alpha", Red.WithAlpha(0.25), RGBA(1, 0, 0, 0.25)},
+    }
+
+    for _, test := range tests {
+        if !colorNear(test.got, test.expected, 1e-6) {
+            t.Errorf("%s = %v, expected %v", test.name, test.got, test.expected)
+        }
+    }
+
+    // greys have no hue rather than dividing by zero
+    if h, s, _ := Gray.HSV(); h != 0 || s != 0 {
+        t.Errorf("grey has hue %v and saturation %v", h, s)
+    }
+}
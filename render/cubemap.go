@@ -0,0 +1,455 @@
+package render
+
+import (
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "github.com/go-gl/mathgl/mgl32"
+    "image"
+    "image/draw"
+    "path/filepath"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Cube Map
+// --------------------------------------------------------------------------------------------------------
+
+// holds the cube map texture reference
+type CubeMap struct {
+    ptr  uint32
+    size int32
+    res  *resource
+}
+
+// binds this cube map for usage to the given texture unit
+func (c *CubeMap) Bind(textureUnit TextureUnit) {
+    gl.ActiveTexture(uint32(textureUnit.bind))
+    gl.BindTexture(gl.TEXTURE_CUBE_MAP, c.ptr)
+}
+
+// the edge length of the largest face in pixels
+func (c *CubeMap) Size() int32 {
+    return c.size
+}
+
+// deletes the underlying texture
+func (c *CubeMap) Delete() {
+    c.res.release()
+}
+
+// paths of the six individual face images of a cube map
+type CubeFaces struct {
+    Right  string // +X
+    Left   string // -X
+    Top    string // +Y
+    Bottom string // -Y
+    Front  string // +Z
+    Back   string // -Z
+}
+
+// in gl target order: +X, -X, +Y, -Y, +Z, -Z
+func (f CubeFaces) paths() [6]string {
+    return [6]string{f.Right, f.Left, f.Top, f.Bottom, f.Front, f.Back}
+}
+
+// the arrangement of the faces within a single cube map image
+type CubeLayout int
+
+const (
+    // picks the layout from the image aspect ratio
+    AutoLayout CubeLayout = iota
+
+    // 4x3 cells:  _ +Y _ _ / -X +Z +X -Z / _ -Y _ _
+    HorizontalCross
+
+    // 3x4 cells:  _ +Y _ / -X +Z +X / _ -Y _ / _ -Z _ (with -Z upside down)
+    VerticalCross
+
+    // 6x1 cells in the order +X -X +Y -Y +Z -Z
+    HorizontalStrip
+
+    // 1x6 cells in the order +X -X +Y -Y +Z -Z
+    VerticalStrip
+)
+
+func (l CubeLayout) String() string {
+    switch l {
+    case AutoLayout:
+        return "Auto"
+    case HorizontalCross:
+        return "Horizontal Cross"
+    case VerticalCross:
+        return "Vertical Cross"
+    case HorizontalStrip:
+        return "Horizontal Strip"
+    case VerticalStrip:
+        return "Vertical Strip"
+    default:
+        return "Unknown"
+    }
+}
+
+// grid dimensions (columns, rows) of the layout
+func (l CubeLayout) grid() (int, int) {
+    switch l {
+    case HorizontalCross:
+        return 4, 3
+    case VerticalCross:
+        return 3, 4
+    case HorizontalStrip:
+        return 6, 1
+    case VerticalStrip:
+        return 1, 6
+    default:
+        return 0, 0
+    }
+}
+
+// the cell (column, row) holding each face in gl target order, plus whether the face is stored rotated by 180 degrees
+func (l CubeLayout) cells() ([6]image.Point, [6]bool) {
+    var rotated [6]bool
+
+    switch l {
+    case HorizontalCross:
+        return [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {3, 1}}, rotated
+    case VerticalCross:
+        rotated[5] = true
+        return [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {1, 3}}, rotated
+    case HorizontalStrip:
+        return [6]image.Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}}, rotated
+    default:
+        return [6]image.Point{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {0, 4}, {0, 5}}, rotated
+    }
+}
+
+// detects the layout of an image with the given dimensions
+func detectCubeLayout(width, height int) (CubeLayout, error) {
+    for _, layout := range []CubeLayout{HorizontalCross, VerticalCross, HorizontalStrip, VerticalStrip} {
+        cols, rows := layout.grid()
+
+        if width*rows == height*cols && width%cols == 0 {
+            return layout, nil
+        }
+    }
+
+    return AutoLayout, fmt.Errorf("unable to detect cube map layout: width = %d, height = %d", width, height)
+}
+
+// Reads a cube map from six separate face images
+func ReadCubeMap(faces CubeFaces, opts TextureOpts) (*CubeMap, error) {
+    var images [6]*image.RGBA
+
+    for i, path := range faces.paths() {
+        rgba, err := readImage(path, opts.FlipY)
+
+        if err != nil {
+            return nil, err
+        }
+
+        images[i] = rgba
+    }
+
+    return newCubeMap(images, opts)
+}
+
+// Reads a cube map from a single image holding all six faces in a cross or strip arrangement
+func ReadCubeMapLayout(path string, layout CubeLayout, opts TextureOpts) (*CubeMap, error) {
+    rgba, err := readImage(path, false)
+
+    if err != nil {
+        return nil, err
+    }
+
+    size := rgba.Rect.Size()
+
+    if layout == AutoLayout {
+        if layout, err = detectCubeLayout(size.X, size.Y); err != nil {
+            return nil, err
+        }
+    }
+
+    cols, rows := layout.grid()
+    if cols == 0 || size.X/cols != size.Y/rows {
+        return nil, fmt.Errorf("image does not match cube map layout: layout = %s, width = %d, height = %d",
+            layout, size.X, size.Y)
+    }
+
+    cells, rotated := layout.cells()
+    edge := size.X / cols
+
+    var images [6]*image.RGBA
+    for i, cell := range cells {
+        face := image.NewRGBA(image.Rect(0, 0, edge, edge))
+        draw.Draw(face, face.Bounds(), rgba, image.Pt(cell.X*edge, cell.Y*edge), draw.Src)
+
+        if rotated[i] {
+            rotateImage180(face)
+        }
+
+        if opts.FlipY {
+            flipImage(face)
+        }
+
+        images[i] = face
+    }
+
+    return newCubeMap(images, opts)
+}
+
+// Creates a cube map from six in memory face images, in gl target order: +X, -X, +Y, -Y, +Z, -Z. The faces must be
+// square and all the same size.
+func NewCubeMap(faces [6]image.Image, opts TextureOpts) (*CubeMap, error) {
+    var images [6]*image.RGBA
+    for i, face := range faces {
+        images[i] = toRGBA(face, opts.FlipY)
+    }
+
+    return newCubeMap(images, opts)
+}
+
+// Reads an equirectangular (latitude/longitude) panorama and projects it onto the faces of a cube map of the given
+// size on the GPU. Radiance .hdr files are uploaded as floating point data, anything else as 8-bit RGBA - the
+// resulting cube map is always stored as RGB16F.
+func ReadEquirectCubeMap(path string, size int32, opts TextureOpts) (*CubeMap, error) {
+    if size < 1 {
+        return nil, fmt.Errorf("invalid cube map size: size = %d", size)
+    }
+
+    var source uint32
+    gl.GenTextures(1, &source)
+    defer gl.DeleteTextures(1, &source)
+
+    gl.BindTexture(gl.TEXTURE_2D, source)
+    gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+    gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+    gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+    gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+
+    if strings.EqualFold(filepath.Ext(path), ".hdr") {
+        hdr, err := readHDR(path)
+        if err != nil {
+            return nil, err
+        }
+
+        if opts.FlipY {
+            hdr.flip()
+        }
+
+        gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGB16F, int32(hdr.width), int32(hdr.height), 0, gl.RGB, gl.FLOAT,
+            gl.Ptr(hdr.pix))
+    } else {
+        rgba, err := readImage(path, opts.FlipY)
+        if err != nil {
+            return nil, err
+        }
+
+        gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(rgba.Rect.Size().X), int32(rgba.Rect.Size().Y), 0, gl.RGBA,
+            gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+    }
+
+    prog, err := compileProgram(cubeCaptureVertexSource, equirectFragmentSource)
+    if err != nil {
+        return nil, err
+    }
+    defer prog.Delete()
+
+    cube := newEmptyCubeMap(size, gl.RGB16F, opts)
+
+    prog.Use()
+    if err := prog.Integer("equirectangularMap", 0); err != nil {
+        cube.Delete()
+        return nil, err
+    }
+
+    gl.ActiveTexture(gl.TEXTURE0)
+    gl.BindTexture(gl.TEXTURE_2D, source)
+
+    if err := renderCubeFaces(cube, 0, prog); err != nil {
+        cube.Delete()
+        return nil, err
+    }
+
+    if opts.GenMipMap {
+        gl.BindTexture(gl.TEXTURE_CUBE_MAP, cube.ptr)
+        gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP)
+    }
+
+    return cube, nil
+}
+
+// uploads the six faces and applies the texture options, the faces must be square and all the same size
+func newCubeMap(faces [6]*image.RGBA, opts TextureOpts) (*CubeMap, error) {
+    size := int32(faces[0].Rect.Dx())
+
+    for i, face := range faces {
+        width, height := int32(face.Rect.Dx()), int32(face.Rect.Dy())
+
+        if width != height || width != size || width < 1 {
+            return nil, fmt.Errorf("cube map faces must be square and the same size: face = %d, width = %d, "+
+                "height = %d, size = %d", i, width, height, size)
+        }
+    }
+
+    var texture uint32
+    gl.GenTextures(1, &texture)
+    gl.BindTexture(gl.TEXTURE_CUBE_MAP, texture)
+
+    for i, face := range faces {
+        gl.TexImage2D(
+            gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i),
+            0,
+            gl.RGBA,
+            size,
+            size,
+            0,
+            gl.RGBA,
+            gl.UNSIGNED_BYTE,
+            gl.Ptr(face.Pix),
+        )
+    }
+
+    applyTextureOpts(gl.TEXTURE_CUBE_MAP, opts)
+
+    if opts.GenMipMap {
+        gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP)
+    }
+
+    glCheck("creating a cube map")
+
+    c := &CubeMap{ptr: texture, size: size}
+    c.res = track(c, textureResource, texture)
+
+    return c, nil
+}
+
+// allocates a cube map with uninitialised faces of the given internal format, used as a render target
+func newEmptyCubeMap(size int32, internalFormat int32, opts TextureOpts) *CubeMap {
+    var texture uint32
+    gl.GenTextures(1, &texture)
+    gl.BindTexture(gl.TEXTURE_CUBE_MAP, texture)
+
+    for i := uint32(0); i < 6; i++ {
+        gl.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+i, 0, internalFormat, size, size, 0, gl.RGB, gl.FLOAT, nil)
+    }
+
+    applyTextureOpts(gl.TEXTURE_CUBE_MAP, opts)
+
+    glCheck("allocating a cube map")
+
+    c := &CubeMap{ptr: texture, size: size}
+    c.res = track(c, textureResource, texture)
+
+    return c
+}
+
+// rotates the given square image by 180 degrees in place
+func rotateImage180(rgba *image.RGBA) {
+    pix := rgba.Pix
+    for i, j := 0, len(pix)-4; i < j; i, j = i+4, j-4 {
+        for c := 0; c < 4; c++ {
+            pix[i+c], pix[j+c] = pix[j+c], pix[i+c]
+        }
+    }
+}
+
+// the view matrices looking down each face of a cube from its centre, in gl target order
+var cubeCaptureViews = [6]mgl32.Mat4{
+    mgl32.LookAtV(mgl32.Vec3{}, mgl32.Vec3{1, 0, 0}, mgl32.Vec3{0, -1, 0}),
+    mgl32.LookAtV(mgl32.Vec3{}, mgl32.Vec3{-1, 0, 0}, mgl32.Vec3{0, -1, 0}),
+    mgl32.LookAtV(mgl32.Vec3{}, mgl32.Vec3{0, 1, 0}, mgl32.Vec3{0, 0, 1}),
+    mgl32.LookAtV(mgl32.Vec3{}, mgl32.Vec3{0, -1, 0}, mgl32.Vec3{0, 0, -1}),
+    mgl32.LookAtV(mgl32.Vec3{}, mgl32.Vec3{0, 0, 1}, mgl32.Vec3{0, -1, 0}),
+    mgl32.LookAtV(mgl32.Vec3{}, mgl32.Vec3{0, 0, -1}, mgl32.Vec3{0, -1, 0}),
+}
+
+// renders a unit cube into every face of the target at the given mip level using the (already bound) program, which
+// must declare "projection" and "view" uniforms. The previous framebuffer binding and viewport are restored.
+func renderCubeFaces(target *CubeMap, mip int32, prog *Program) error {
+    restore := saveRenderTarget()
+
+    size := target.size >> uint(mip)
+    if size < 1 {
+        size = 1
+    }
+
+    var fbo, rbo uint32
+    gl.GenFramebuffers(1, &fbo)
+    gl.GenRenderbuffers(1, &rbo)
+
+    defer func() {
+        restore()
+        gl.DeleteRenderbuffers(1, &rbo)
+        gl.DeleteFramebuffers(1, &fbo)
+    }()
+
+    gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
+    gl.BindRenderbuffer(gl.RENDERBUFFER, rbo)
+    gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, size, size)
+    gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, rbo)
+
+    if err := prog.Mat4("projection", mgl32.Perspective(mgl32.DegToRad(90), 1, 0.1, 10)); err != nil {
+        return err
+    }
+
+    cube := newUnitCube()
+    defer cube.delete()
+
+    gl.Viewport(0, 0, size, size)
+
+    for i, view := range cubeCaptureViews {
+        gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i),
+            target.ptr, mip)
+
+        if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+            return fmt.Errorf("incomplete cube map framebuffer: face = %d, status = 0x%x", i, status)
+        }
+
+        if err := prog.Mat4("view", view); err != nil {
+            return err
+        }
+
+        gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+        cube.draw()
+    }
+
+    return nil
+}
+
+const cubeCaptureVertexSource = `#version 330 core
+layout (location = 0) in vec3 aPos;
+
+out vec3 localPos;
+
+uniform mat4 projection;
+uniform mat4 view;
+
+void main()
+{
+    localPos = aPos;
+    gl_Position = projection * view * vec4(aPos, 1.0);
+}
+` + "\x00"
+
+const equirectFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec3 localPos;
+
+uniform sampler2D equirectangularMap;
+
+const vec2 invAtan = vec2(0.1591, 0.3183);
+
+vec2 sampleSphericalMap(vec3 v)
+{
+    vec2 uv = vec2(atan(v.z, v.x), asin(v.y));
+    uv *= invAtan;
+    uv += 0.5;
+    return uv;
+}
+
+void main()
+{
+    vec2 uv = sampleSphericalMap(normalize(localPos));
+    FragColor = vec4(texture(equirectangularMap, uv).rgb, 1.0);
+}
+` + "\x00"
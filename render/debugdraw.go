@@ -0,0 +1,320 @@
+package render
+
+import (
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "github.com/go-gl/mathgl/mgl32"
+    "logl/render/model"
+    "math"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Debug Drawing
+// --------------------------------------------------------------------------------------------------------
+
+// position xyz and colour rgba
+const debugVertexFloats = 7
+
+// the segments used for circles and spheres
+const debugCircleSegments = 32
+
+// the shapes queued in one space, drawn with the same depth testing
+type debugBucket struct {
+    lines  []float32
+    points []float32
+}
+
+func (b *debugBucket) reset() {
+    b.lines = b.lines[:0]
+    b.points = b.points[:0]
+}
+
+// the buckets shapes are queued into
+const (
+    debugWorldDepth = iota
+    debugWorldOverlay
+    debugScreen
+    debugBuckets
+)
+
+// DebugDraw collects lines and points in immediate mode - shapes are queued during a frame and drawn together at its
+// end by Window.Render, then forgotten. World space shapes are drawn with the camera set by SetCamera and screen space
+// shapes in pixels from the top left of the window. Get the window's instance with Window.DebugDraw.
+type DebugDraw struct {
+    // whether the world space shapes queued from now on are hidden behind the scene, true by default. Turn it off
+    // for shapes that should always be visible.
+    DepthTest bool
+
+    // the size of points in pixels
+    PointSize float32
+
+    viewProjection Mat4
+    buckets        [debugBuckets]debugBucket
+
+    prog     *Program
+    vao      *VertexArray
+    vbo      *Buffer
+    vertices []float32
+}
+
+func newDebugDraw() (*DebugDraw, error) {
+    prog, err := compileProgram(debugVertexSource, debugFragmentSource)
+    if err != nil {
+        return nil, err
+    }
+
+    d := &DebugDraw{
+        DepthTest:      true,
+        PointSize:      6,
+        viewProjection: mgl32.Ident4(),
+        prog:           prog,
+        vao:            NewVertexArray(),
+        vbo:            NewBuffer(ArrayBuffer),
+    }
+
+    d.vao.Bind()
+    d.vbo.Bind()
+
+    stride := int32(debugVertexFloats * 4)
+    gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, gl.PtrOffset(0))
+    gl.EnableVertexAttribArray(0)
+    gl.VertexAttribPointer(4, 4, gl.FLOAT, false, stride, gl.PtrOffset(3*4))
+    gl.EnableVertexAttribArray(4)
+
+    gl.BindVertexArray(0)
+
+    return d, nil
+}
+
+// sets the camera the world space shapes are drawn with, call this every frame before they are flushed
+func (d *DebugDraw) SetCamera(view, projection Mat4) {
+    d.viewProjection = projection.Mul4(view)
+}
+
+func (d *DebugDraw) world() *debugBucket {
+    if d.DepthTest {
+        return &d.buckets[debugWorldDepth]
+    }
+    return &d.buckets[debugWorldOverlay]
+}
+
+func appendVertex(vertices []float32, p Vec3, c Color) []float32 {
+    return append(vertices, p[0], p[1], p[2], c.r, c.g, c.b, c.a)
+}
+
+// queues a world space line
+func (d *DebugDraw) Line(a, b Vec3, c Color) {
+    bucket := d.world()
+    bucket.lines = appendVertex(appendVertex(bucket.lines, a, c), b, c)
+}
+
+// queues a world space point
+func (d *DebugDraw) Point(p Vec3, c Color) {
+    bucket := d.world()
+    bucket.points = appendVertex(bucket.points, p, c)
+}
+
+// queues the edges of a box in the space given by the transform, mgl32.Ident4() for world space
+func (d *DebugDraw) Box(box model.AABB, transform Mat4, c Color) {
+    corners := box.Corners()
+    for i := range corners {
+        corners[i] = mgl32.TransformCoordinate(corners[i], transform)
+    }
+
+    // each edge joins two corners differing in one bit of their index
+    for i := 0; i < 8; i++ {
+        for bit := 1; bit < 8; bit <<= 1 {
+            if i&bit == 0 {
+                d.Line(corners[i], corners[i|bit], c)
+            }
+        }
+    }
+}
+
+// queues a world space circle around the normal
+func (d *DebugDraw) Circle(center, normal Vec3, radius float32, c Color) {
+    normal = normal.Normalize()
+
+    // any two axes perpendicular to the normal and each other
+    u := normal.Cross(Vec3{0, 1, 0})
+    if u.Len() < 1e-3 {
+        u = normal.Cross(Vec3{1, 0, 0})
+    }
+    u = u.Normalize().Mul(radius)
+    v := normal.Cross(u)
+
+    prev := center.Add(u)
+    for i := 1; i <= debugCircleSegments; i++ {
+        angle := 2 * math.Pi * float64(i) / debugCircleSegments
+        s, co := float32(math.Sin(angle)), float32(math.Cos(angle))
+
+        p := center.Add(u.Mul(co)).Add(v.Mul(s))
+        d.Line(prev, p, c)
+        prev = p
+    }
+}
+
+// queues a world space sphere as three circles around the axes
+func (d *DebugDraw) Sphere(center Vec3, radius float32, c Color) {
+    d.Circle(center, Vec3{1, 0, 0}, radius, c)
+    d.Circle(center, Vec3{0, 1, 0}, radius, c)
+    d.Circle(center, Vec3{0, 0, 1}, radius, c)
+}
+
+// queues the edges of the frustum of a camera, from its projection multiplied by its view
+func (d *DebugDraw) Frustum(viewProjection Mat4, c Color) {
+    inverse := viewProjection.Inv()
+
+    var box model.AABB
+    box.Min, box.Max = Vec3{-1, -1, -1}, Vec3{1, 1, 1}
+
+    d.Box(box, inverse, c)
+}
+
+// queues a grid in the XZ plane centred on the given point, size across with the given number of divisions
+func (d *DebugDraw) Grid(center Vec3, size float32, divisions int, c Color) {
+    if divisions < 1 {
+        divisions = 1
+    }
+
+    half := size / 2
+    step := size / float32(divisions)
+
+    for i := 0; i <= divisions; i++ {
+        offset := -half + float32(i)*step
+
+        d.Line(center.Add(Vec3{offset, 0, -half}), center.Add(Vec3{offset, 0, half}), c)
+        d.Line(center.Add(Vec3{-half, 0, offset}), center.Add(Vec3{half, 0, offset}), c)
+    }
+}
+
+// queues the axes of the space given by the transform, x red, y green and z blue, each size long
+func (d *DebugDraw) Axes(transform Mat4, size float32) {
+    origin := mgl32.TransformCoordinate(Vec3{}, transform)
+
+    d.Line(origin, mgl32.TransformCoordinate(Vec3{size, 0, 0}, transform), Red)
+    d.Line(origin, mgl32.TransformCoordinate(Vec3{0, size, 0}, transform), Green)
+    d.Line(origin, mgl32.TransformCoordinate(Vec3{0, 0, size}, transform), Blue)
+}
+
+// queues a screen space line, in pixels from the top left of the window
+func (d *DebugDraw) ScreenLine(a, b Vec2, c Color) {
+    bucket := &d.buckets[debugScreen]
+    bucket.lines = appendVertex(appendVertex(bucket.lines, a.Vec3(0), c), b.Vec3(0), c)
+}
+
+// queues a screen space point, in pixels from the top left of the window
+func (d *DebugDraw) ScreenPoint(p Vec2, c Color) {
+    bucket := &d.buckets[debugScreen]
+    bucket.points = appendVertex(bucket.points, p.Vec3(0), c)
+}
+
+// queues the outline of a screen space rectangle, in pixels from the top left of the window
+func (d *DebugDraw) ScreenRect(x, y, width, height float32, c Color) {
+    corners := [4]Vec2{{x, y}, {x + width, y}, {x + width, y + height}, {x, y + height}}
+
+    for i := range corners {
+        d.ScreenLine(corners[i], corners[(i+1)%4], c)
+    }
+}
+
+// forgets every queued shape without drawing it
+func (d *DebugDraw) Clear() {
+    for i := range d.buckets {
+        d.buckets[i].reset()
+    }
+}
+
+// Draws every queued shape into the bound framebuffer and forgets them, screen space shapes with the given
+// projection (PixelOrtho of the framebuffer size). All the shapes are uploaded to one buffer and drawn with a call per
+// bucket. Window.Render does this at the end of every frame.
+func (d *DebugDraw) Flush(screen Mat4) error {
+    defer d.Clear()
+
+    // one upload of every bucket, lines then points
+    d.vertices = d.vertices[:0]
+    var ranges [debugBuckets][2][2]int32
+
+    for i := range d.buckets {
+        for kind, vertices := range [2][]float32{d.buckets[i].lines, d.buckets[i].points} {
+            ranges[i][kind] = [2]int32{int32(len(d.vertices) / debugVertexFloats), int32(len(vertices) / debugVertexFloats)}
+            d.vertices = append(d.vertices, vertices...)
+        }
+    }
+
+    if len(d.vertices) == 0 {
+        return nil
+    }
+
+    var state passState
+    state.save()
+    defer state.restore()
+
+    d.vbo.Data(len(d.vertices)*4, d.vertices, StreamDraw)
+
+    gl.Enable(gl.BLEND)
+    gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+    gl.Disable(gl.CULL_FACE)
+    gl.DepthMask(false)
+    gl.DepthFunc(gl.LEQUAL)
+    gl.PointSize(d.PointSize)
+
+    d.prog.Use()
+    d.vao.Bind()
+    defer gl.BindVertexArray(0)
+
+    for i := range d.buckets {
+        matrix := d.viewProjection
+        if i == debugScreen {
+            matrix = screen
+        }
+
+        if err := d.prog.Mat4("transform", matrix); err != nil {
+            return err
+        }
+
+        enable(gl.DEPTH_TEST, i == debugWorldDepth)
+
+        for kind, mode := range [2]uint32{gl.LINES, gl.POINTS} {
+            if r := ranges[i][kind]; r[1] > 0 {
+                gl.DrawArrays(mode, r[0], r[1])
+            }
+        }
+    }
+
+    glCheck("drawing debug shapes")
+
+    return nil
+}
+
+// deletes the program and buffers
+func (d *DebugDraw) Delete() {
+    d.prog.Delete()
+    d.vao.Delete()
+    d.vbo.Delete()
+}
+
+const debugVertexSource = `#version 330 core
+layout (location = 0) in vec3 aPos;
+layout (location = 4) in vec4 aColor;
+
+out vec4 color;
+
+uniform mat4 transform;
+
+void main()
+{
+    color = aColor;
+    gl_Position = transform * vec4(aPos, 1.0);
+}
+` + "\x00"
+
+const debugFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec4 color;
+
+void main()
+{
+    FragColor = color;
+}
+` + "\x00"
@@ -0,0 +1,123 @@
+package render
+
+import (
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Framebuffer
+// --------------------------------------------------------------------------------------------------------
+
+// holds a framebuffer object reference - textures are attached by the render targets built on it
+type Framebuffer struct {
+    ptr uint32
+    res *resource
+}
+
+// Creates a new framebuffer with no attachments
+func NewFramebuffer() *Framebuffer {
+    var fbo uint32
+    gl.GenFramebuffers(1, &fbo)
+
+    f := &Framebuffer{ptr: fbo}
+    f.res = track(f, framebufferResource, fbo)
+
+    return f
+}
+
+// binds the framebuffer for both drawing and reading
+func (f *Framebuffer) Bind() {
+    gl.BindFramebuffer(gl.FRAMEBUFFER, f.ptr)
+}
+
+// binds the window's default framebuffer
+func BindDefaultFramebuffer() {
+    gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// deletes the underlying framebuffer, the attached textures are left alone
+func (f *Framebuffer) Delete() {
+    f.res.release()
+}
+
+// binds the framebuffer and reports whether it is complete, after attaching its targets
+func (f *Framebuffer) Check() error {
+    f.Bind()
+    glCheck("setting up a framebuffer")
+
+    if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+        return fmt.Errorf("incomplete framebuffer: status = 0x%x", status)
+    }
+
+    return nil
+}
+
+// records the bound framebuffer and viewport, returning a function restoring them - for passes rendering off screen
+// in the middle of a frame
+func saveRenderTarget() func() {
+    var fbo int32
+    var viewport [4]int32
+    gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &fbo)
+    gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+
+    return func() {
+        gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(fbo))
+        gl.Viewport(viewport[0], viewport[1], viewport[2], viewport[3])
+    }
+}
+
+// the parts of the gl state changed by passes drawing over the frame, along with the render target
+type passState struct {
+    depthTest bool
+    depthMask bool
+    depthFunc int32
+    blend     bool
+    blendFunc [4]int32
+    cullFace  bool
+    cullMode  int32
+    fbo       int32
+    viewport  [4]int32
+    clear     [4]float32
+}
+
+func (s *passState) save() {
+    var mask bool
+    gl.GetBooleanv(gl.DEPTH_WRITEMASK, &mask)
+
+    s.depthTest = gl.IsEnabled(gl.DEPTH_TEST)
+    s.depthMask = mask
+    s.blend = gl.IsEnabled(gl.BLEND)
+    s.cullFace = gl.IsEnabled(gl.CULL_FACE)
+    gl.GetIntegerv(gl.DEPTH_FUNC, &s.depthFunc)
+    gl.GetIntegerv(gl.BLEND_SRC_RGB, &s.blendFunc[0])
+    gl.GetIntegerv(gl.BLEND_DST_RGB, &s.blendFunc[1])
+    gl.GetIntegerv(gl.BLEND_SRC_ALPHA, &s.blendFunc[2])
+    gl.GetIntegerv(gl.BLEND_DST_ALPHA, &s.blendFunc[3])
+    gl.GetIntegerv(gl.CULL_FACE_MODE, &s.cullMode)
+    gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &s.fbo)
+    gl.GetIntegerv(gl.VIEWPORT, &s.viewport[0])
+    gl.GetFloatv(gl.COLOR_CLEAR_VALUE, &s.clear[0])
+}
+
+func (s *passState) restore() {
+    enable(gl.DEPTH_TEST, s.depthTest)
+    enable(gl.BLEND, s.blend)
+    enable(gl.CULL_FACE, s.cullFace)
+    gl.DepthMask(s.depthMask)
+    gl.DepthFunc(uint32(s.depthFunc))
+    gl.BlendFuncSeparate(uint32(s.blendFunc[0]), uint32(s.blendFunc[1]), uint32(s.blendFunc[2]),
+        uint32(s.blendFunc[3]))
+    gl.CullFace(uint32(s.cullMode))
+    gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(s.fbo))
+    gl.Viewport(s.viewport[0], s.viewport[1], s.viewport[2], s.viewport[3])
+    gl.ClearColor(s.clear[0], s.clear[1], s.clear[2], s.clear[3])
+}
+
+func enable(capability uint32, enabled bool) {
+    if enabled {
+        gl.Enable(capability)
+    } else {
+        gl.Disable(capability)
+    }
+}
@@ -0,0 +1,405 @@
+package render
+
+import (
+    "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// G-Buffer
+// --------------------------------------------------------------------------------------------------------
+
+// a screen sized texture rendered into through a framebuffer, sampled with nearest filtering
+type RenderTexture struct {
+    ptr    uint32
+    format int32
+    res    *resource
+}
+
+func newRenderTexture(format int32, width, height int32) *RenderTexture {
+    var texture uint32
+    gl.GenTextures(1, &texture)
+
+    t := &RenderTexture{ptr: texture, format: format}
+    t.res = track(t, textureResource, texture)
+    t.resize(width, height)
+
+    return t
+}
+
+func (t *RenderTexture) resize(width, height int32) {
+    gl.BindTexture(gl.TEXTURE_2D, t.ptr)
+    gl.TexImage2D(gl.TEXTURE_2D, 0, t.format, width, height, 0, gl.RGBA, gl.FLOAT, nil)
+
+    applyTextureOpts(gl.TEXTURE_2D, TextureOpts{
+        WrapS:     ClampToEdge,
+        WrapT:     ClampToEdge,
+        MinFilter: Nearest,
+        MagFilter: Nearest,
+    })
+
+    glCheck("allocating a render texture")
+}
+
+// binds this texture for usage to the given texture unit
+func (t *RenderTexture) Bind(textureUnit TextureUnit) {
+    gl.ActiveTexture(uint32(textureUnit.bind))
+    gl.BindTexture(gl.TEXTURE_2D, t.ptr)
+}
+
+// deletes the underlying texture
+func (t *RenderTexture) Delete() {
+    t.res.release()
+}
+
+// the colour attachment holding the light, after the position, normal, albedo and material attachments
+const gbufferLight = 4
+
+// GBuffer holds the surface attributes written by the geometry pass of a deferred renderer, read back by the
+// lighting passes which add their results into the light attachment. The light attachment is then tone mapped to the
+// window by Present.
+type GBuffer struct {
+    // world space position in rgb, alpha 1 wherever a surface was drawn
+    Position *RenderTexture
+
+    // world space unit normal in rgb
+    Normal *RenderTexture
+
+    // linear albedo in rgb, stored as sRGB for precision in the darks
+    Albedo *RenderTexture
+
+    // metalness in red, roughness in green and ambient occlusion in blue
+    Material *RenderTexture
+
+    // linear HDR light, starting with the emissive light written by the geometry pass
+    Light *RenderTexture
+
+    fbo    *Framebuffer
+    depth  uint32
+    res    *resource
+    width  int32
+    height int32
+
+    // the state changed by the passes, restored by Present
+    saved passState
+}
+
+// Creates a G-buffer of the given size in pixels, usually the window's framebuffer size
+func NewGBuffer(width, height int32) (*GBuffer, error) {
+    g := &GBuffer{
+        Position: newRenderTexture(gl.RGBA32F, width, height),
+        Normal:   newRenderTexture(gl.RGBA16F, width, height),
+        Albedo:   newRenderTexture(gl.SRGB8_ALPHA8, width, height),
+        Material: newRenderTexture(gl.RGBA8, width, height),
+        Light:    newRenderTexture(gl.RGBA16F, width, height),
+        fbo:      NewFramebuffer(),
+        width:    width,
+        height:   height,
+    }
+
+    gl.GenRenderbuffers(1, &g.depth)
+    g.res = track(g, renderbufferResource, g.depth)
+
+    gl.BindRenderbuffer(gl.RENDERBUFFER, g.depth)
+    gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH24_STENCIL8, width, height)
+
+    g.fbo.Bind()
+    for i, t := range g.attachments() {
+        gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0+uint32(i), gl.TEXTURE_2D, t.ptr, 0)
+    }
+    gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_STENCIL_ATTACHMENT, gl.RENDERBUFFER, g.depth)
+
+    err := g.fbo.Check()
+    BindDefaultFramebuffer()
+
+    if err != nil {
+        g.Delete()
+        return nil, err
+    }
+
+    return g, nil
+}
+
+func (g *GBuffer) attachments() []*RenderTexture {
+    return []*RenderTexture{g.Position, g.Normal, g.Albedo, g.Material, g.Light}
+}
+
+// the size of the G-buffer in pixels
+func (g *GBuffer) Size() (int32, int32) {
+    return g.width, g.height
+}
+
+// reallocates the attachments for a new size, their contents are lost. Hook this up with Window.OnResize.
+func (g *GBuffer) Resize(width, height int32) {
+    if width == g.width && height == g.height {
+        return
+    }
+
+    g.width, g.height = width, height
+
+    for _, t := range g.attachments() {
+        t.resize(width, height)
+    }
+
+    gl.BindRenderbuffer(gl.RENDERBUFFER, g.depth)
+    gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH24_STENCIL8, width, height)
+}
+
+// Sets the position, normal, albedo and material attachments as the textures read by GBufferReadGLSL
+func (g *GBuffer) SetTextures(m *Material) {
+    m.SetTexture("gPosition", g.Position)
+    m.SetTexture("gNormal", g.Normal)
+    m.SetTexture("gAlbedo", g.Albedo)
+    m.SetTexture("gMaterial", g.Material)
+}
+
+// Starts the geometry pass, binding and clearing the G-buffer with depth testing on and blending off. Opaque objects
+// are then drawn with programs writing their surfaces through GBufferGLSL. The gl state is saved and restored by
+// Present, which must end the frame.
+func (g *GBuffer) BeginGeometry() {
+    g.saved.save()
+
+    g.fbo.Bind()
+    gl.Viewport(0, 0, g.width, g.height)
+
+    buffers := []uint32{gl.COLOR_ATTACHMENT0, gl.COLOR_ATTACHMENT1, gl.COLOR_ATTACHMENT2, gl.COLOR_ATTACHMENT3,
+        gl.COLOR_ATTACHMENT4}
+    gl.DrawBuffers(int32(len(buffers)), &buffers[0])
+
+    gl.ClearColor(0, 0, 0, 0)
+    gl.DepthMask(true)
+    gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+
+    gl.Enable(gl.DEPTH_TEST)
+    gl.DepthFunc(gl.LESS)
+    gl.Disable(gl.BLEND)
+}
+
+// Starts the lighting passes, which add into the light attachment without writing depth. Draw each light with
+// DrawVolume or DrawFullscreen.
+func (g *GBuffer) BeginLighting() {
+    g.fbo.Bind()
+    gl.DrawBuffer(gl.COLOR_ATTACHMENT0 + gbufferLight)
+
+    gl.DepthMask(false)
+    gl.Enable(gl.BLEND)
+    gl.BlendEquation(gl.FUNC_ADD)
+    gl.BlendFunc(gl.ONE, gl.ONE)
+}
+
+// Draws a light volume with the program in use, which takes its vertex shader from LightVolumeVertexSource. The
+// mesh must be closed and wound counter clockwise seen from outside, transform takes it to clip space. Only the
+// pixels whose surface lies inside the volume are shaded, whether or not the camera is inside it.
+func (g *GBuffer) DrawVolume(prog *Program, mesh *Mesh, transform Mat4) error {
+    if err := prog.Bool("fullscreen", false); err != nil {
+        return err
+    }
+    if err := prog.Mat4("volume", transform); err != nil {
+        return err
+    }
+
+    // the back faces of the volume lie behind surfaces inside it - clamped rather than clipped at the far plane
+    gl.Enable(gl.DEPTH_TEST)
+    gl.DepthFunc(gl.GEQUAL)
+    gl.Enable(gl.CULL_FACE)
+    gl.CullFace(gl.FRONT)
+    gl.Enable(gl.DEPTH_CLAMP)
+
+    mesh.Draw()
+
+    gl.Disable(gl.DEPTH_CLAMP)
+    gl.CullFace(gl.BACK)
+    gl.Disable(gl.CULL_FACE)
+    gl.DepthFunc(gl.LESS)
+
+    return nil
+}
+
+// Draws over every pixel with the program in use, which takes its vertex shader from LightVolumeVertexSource - for
+// directional lights, unbounded lights and ambient light. Pixels without a surface are left to the fragment shader
+// to discard (readGBuffer reports them).
+func (g *GBuffer) DrawFullscreen(prog *Program) error {
+    if err := prog.Bool("fullscreen", true); err != nil {
+        return err
+    }
+
+    gl.Disable(gl.DEPTH_TEST)
+    drawFullscreenTriangle()
+    gl.Enable(gl.DEPTH_TEST)
+
+    return nil
+}
+
+// Starts the forward pass for transparent objects, drawn with ordinary forward shading into the light attachment.
+// They are depth tested against the opaque surfaces without writing depth and alpha blended, so should be drawn
+// back to front.
+func (g *GBuffer) BeginForward() {
+    g.fbo.Bind()
+    gl.DrawBuffer(gl.COLOR_ATTACHMENT0 + gbufferLight)
+
+    gl.Enable(gl.DEPTH_TEST)
+    gl.DepthFunc(gl.LESS)
+    gl.DepthMask(false)
+    gl.Enable(gl.BLEND)
+    gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+}
+
+// the program tone mapping the light attachment to the window, compiled on first use and deleted with the window
+var presentProgram *Program
+
+// Ends the frame, tone mapping the light attachment with the given exposure into the framebuffer bound before
+// BeginGeometry (with sRGB encoding) and copying the depth buffer across, so later forward drawing is depth tested
+// against the scene. The gl state saved by BeginGeometry is restored.
+func (g *GBuffer) Present(exposure float32) error {
+    defer g.saved.restore()
+
+    if presentProgram == nil {
+        prog, err := compileProgram(fullscreenVertexSource, presentFragmentSource)
+        if err != nil {
+            return err
+        }
+        presentProgram = prog
+
+        OnDestroy(func() {
+            presentProgram.Delete()
+            presentProgram = nil
+        })
+    }
+
+    gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(g.saved.fbo))
+    gl.Viewport(g.saved.viewport[0], g.saved.viewport[1], g.saved.viewport[2], g.saved.viewport[3])
+
+    gl.Disable(gl.DEPTH_TEST)
+    gl.Disable(gl.BLEND)
+
+    presentProgram.Use()
+    if err := presentProgram.Integer("light", 0); err != nil {
+        return err
+    }
+    if err := presentProgram.Float("exposure", exposure); err != nil {
+        return err
+    }
+
+    g.Light.Bind(TextureUnit0)
+    drawFullscreenTriangle()
+
+    gl.BindFramebuffer(gl.READ_FRAMEBUFFER, g.fbo.ptr)
+    gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, uint32(g.saved.fbo))
+    gl.BlitFramebuffer(0, 0, g.width, g.height, g.saved.viewport[0], g.saved.viewport[1],
+        g.saved.viewport[0]+g.saved.viewport[2], g.saved.viewport[1]+g.saved.viewport[3],
+        gl.DEPTH_BUFFER_BIT, gl.NEAREST)
+
+    return nil
+}
+
+// deletes the attachments and framebuffer
+func (g *GBuffer) Delete() {
+    for _, t := range g.attachments() {
+        t.Delete()
+    }
+
+    g.res.release()
+    g.fbo.Delete()
+}
+
+// The vertex shader for lighting passes drawn with GBuffer.DrawVolume and GBuffer.DrawFullscreen, passed to
+// NewShader as is
+const LightVolumeVertexSource = `#version 330 core
+layout (location = 0) in vec3 aPos;
+
+uniform mat4 volume;
+uniform bool fullscreen;
+
+void main()
+{
+    if (fullscreen) {
+        vec2 corner = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+        gl_Position = vec4(corner * 2.0 - 1.0, 0.0, 1.0);
+    } else {
+        gl_Position = volume * vec4(aPos, 1.0);
+    }
+}
+` + "\x00"
+
+const presentFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec2 texCoords;
+
+uniform sampler2D light;
+uniform float exposure;
+
+// the ACES filmic curve fitted by Krzysztof Narkowicz
+vec3 toneMapACES(vec3 c)
+{
+    return clamp((c * (2.51 * c + 0.03)) / (c * (2.43 * c + 0.59) + 0.14), 0.0, 1.0);
+}
+
+void main()
+{
+    vec3 c = toneMapACES(texture(light, texCoords).rgb * exposure);
+    c = mix(c * 12.92, 1.055 * pow(c, vec3(1.0 / 2.4)) - 0.055, step(vec3(0.0031308), c));
+
+    FragColor = vec4(c, 1.0);
+}
+` + "\x00"
+
+// Fragment shader declarations for the geometry pass of a deferred renderer, to paste after the #version line. It
+// declares the G-buffer outputs, written by writeGBuffer() from the fragment's world space position and unit normal,
+// its linear albedo and material, and any linear emissive light.
+const GBufferGLSL = `
+layout (location = 0) out vec4 gPosition;
+layout (location = 1) out vec4 gNormal;
+layout (location = 2) out vec4 gAlbedo;
+layout (location = 3) out vec4 gMaterial;
+layout (location = 4) out vec4 gLight;
+
+void writeGBuffer(vec3 position, vec3 normal, vec3 albedo, float metallic, float roughness, float occlusion,
+    vec3 emissive)
+{
+    gPosition = vec4(position, 1.0);
+    gNormal = vec4(normal, 0.0);
+    gAlbedo = vec4(albedo, 1.0);
+    gMaterial = vec4(metallic, roughness, occlusion, 1.0);
+    gLight = vec4(emissive, 1.0);
+}
+`
+
+// Fragment shader declarations for the lighting passes of a deferred renderer, to paste after the #version line.
+// readGBuffer() fills in the surface under the fragment, returning false where nothing was drawn. The attachments
+// are set on a Material with GBuffer.SetTextures.
+const GBufferReadGLSL = `
+uniform sampler2D gPosition;
+uniform sampler2D gNormal;
+uniform sampler2D gAlbedo;
+uniform sampler2D gMaterial;
+
+struct Surface {
+    vec3 position;
+    vec3 normal;
+    vec3 albedo;
+    float metallic;
+    float roughness;
+    float occlusion;
+};
+
+bool readGBuffer(out Surface s)
+{
+    ivec2 pixel = ivec2(gl_FragCoord.xy);
+
+    vec4 position = texelFetch(gPosition, pixel, 0);
+    if (position.a == 0.0) {
+        return false;
+    }
+
+    vec4 material = texelFetch(gMaterial, pixel, 0);
+
+    s.position = position.xyz;
+    s.normal = texelFetch(gNormal, pixel, 0).xyz;
+    s.albedo = texelFetch(gAlbedo, pixel, 0).rgb;
+    s.metallic = material.r;
+    s.roughness = material.g;
+    s.occlusion = material.b;
+
+    return true;
+}
+`
@@ -4,7 +4,6 @@ import (
     "errors"
     "fmt"
     "github.com/go-gl/gl/v3.3-core/gl"
-    "github.com/go-gl/mathgl/mgl32"
     "image"
     "image/draw"
     _ "image/gif"
@@ -15,31 +14,6 @@ import (
     "strings"
 )
 
-// basic Vector4 type
-type Vec4 [4]float32
-
-const (
-    // Vec4 component synonyms - RGBA
-    R = iota
-    G
-    B
-    A
-
-    // Vec4 component synonyms - XYZW
-    X = iota
-    Y
-    Z
-    W
-)
-
-// holds a colour type
-type Color struct{ r, b, g, a float32 }
-
-var (
-    White = Color{1, 1, 1, 1}
-    Black = Color{0, 0, 0, 1}
-)
-
 // Upgraded shader type constant string with support for printing the type
 type ShaderType uint32
 
@@ -67,10 +41,12 @@ type Shader struct {
     Type   ShaderType
     Source string
     ptr    uint32
+    res    *resource
 }
 
+// deletes the underlying shader, it can be deleted as soon as the programs using it are linked
 func (s *Shader) Delete() {
-    gl.DeleteShader(s.ptr)
+    s.res.release()
 }
 
 // Will read and add the null terminator to the given shader at the specified path
@@ -108,11 +84,14 @@ func NewShader(shaderType ShaderType, source string) (*Shader, error) {
     gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
 
     if status != gl.FALSE {
-        return &Shader{
+        s := &Shader{
             Type:   shaderType,
             Source: source,
             ptr:    shader,
-        }, nil
+        }
+        s.res = track(s, shaderResource, shader)
+
+        return s, nil
     }
 
     var logLength int32
@@ -120,6 +99,7 @@ func NewShader(shaderType ShaderType, source string) (*Shader, error) {
 
     log := strings.Repeat("\x00", int(logLength+1))
     gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+    gl.DeleteShader(shader)
 
     return nil, fmt.Errorf(
         "failed to compile shader: type = %s, source = %s, log = %s",
@@ -139,6 +119,7 @@ const (
     Repeat         TextureWrap = gl.REPEAT
     ClampToEdge    TextureWrap = gl.CLAMP_TO_EDGE
     MirroredRepeat TextureWrap = gl.MIRRORED_REPEAT
+    ClampToBorder  TextureWrap = gl.CLAMP_TO_BORDER
 )
 
 // texture filter options for min/mag functions
@@ -162,6 +143,7 @@ func (t TextureUnit) Index() int32 {
     return int32(t.index)
 }
 
+// fixed texture units for binding by hand - Material assigns units automatically
 var (
     TextureUnit0  = TextureUnit{gl.TEXTURE0, 0}
     TextureUnit1  = TextureUnit{gl.TEXTURE1, 1}
@@ -183,7 +165,10 @@ var (
 
 // holds the actual texture reference
 type Texture struct {
-    ptr uint32
+    ptr    uint32
+    width  int32
+    height int32
+    res    *resource
 }
 
 // binds this texture for usage to the given texture uint
@@ -192,11 +177,22 @@ func (t *Texture) Bind(textureUnit TextureUnit) {
     gl.BindTexture(gl.TEXTURE_2D, t.ptr)
 }
 
+// the size of the texture in pixels
+func (t *Texture) Size() (int32, int32) {
+    return t.width, t.height
+}
+
+// deletes the underlying texture
+func (t *Texture) Delete() {
+    t.res.release()
+}
+
 // options used when creating the texture
 type TextureOpts struct {
     GenMipMap bool
     WrapS     TextureWrap
     WrapT     TextureWrap
+    WrapR     TextureWrap // only used by textures with a third axis such as cube maps
     MinFilter TextureFilter
     MagFilter TextureFilter
     FlipY     bool
@@ -204,43 +200,26 @@ type TextureOpts struct {
 
 // Reads a texture from the given path
 func ReadTexture(path string, opts TextureOpts) (*Texture, error) {
-    f, err := os.Open(path)
-    if err != nil {
-        return nil, err
-    }
-
-    img, _, err := image.Decode(f)
+    rgba, err := readImage(path, opts.FlipY)
 
     if err != nil {
         return nil, err
     }
 
-    rgba := image.NewRGBA(img.Bounds())
-    if rgba.Stride != rgba.Rect.Size().X*4 {
-        return nil, fmt.Errorf("unsupported stride")
-    }
-    draw.Draw(rgba, rgba.Bounds(), img, image.Point{X: 0, Y: 0}, draw.Src)
-
-    if opts.FlipY {
-        for y := 0; y < rgba.Bounds().Size().Y / 2; y++ {
-            for x := 0; x < rgba.Bounds().Size().X; x++ {
-                c0 := rgba.At(x, y)
-                c1 := rgba.At(x, rgba.Bounds().Size().Y - 1 - y)
+    return newTexture(rgba, opts), nil
+}
 
-                rgba.Set(x, y, c1)
-                rgba.Set(x, rgba.Bounds().Size().Y - 1 - y, c0)
-            }
-        }
-    }
+// Creates a texture from an in memory image
+func NewTexture(img image.Image, opts TextureOpts) *Texture {
+    return newTexture(toRGBA(img, opts.FlipY), opts)
+}
 
+func newTexture(rgba *image.RGBA, opts TextureOpts) *Texture {
     var texture uint32
     gl.GenTextures(1, &texture)
     gl.BindTexture(gl.TEXTURE_2D, texture)
 
-    gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, int32(opts.WrapS))
-    gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, int32(opts.WrapT))
-    gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, int32(opts.MinFilter))
-    gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, int32(opts.MagFilter))
+    applyTextureOpts(gl.TEXTURE_2D, opts)
 
     gl.TexImage2D(
         gl.TEXTURE_2D,
@@ -258,8 +237,76 @@ func ReadTexture(path string, opts TextureOpts) (*Texture, error) {
         gl.GenerateMipmap(gl.TEXTURE_2D)
     }
 
-    return &Texture{texture}, nil
+    glCheck("creating a texture")
+
+    t := &Texture{ptr: texture, width: int32(rgba.Rect.Dx()), height: int32(rgba.Rect.Dy())}
+    t.res = track(t, textureResource, texture)
+
+    return t
+}
+
+// applies the wrap and filter options to the texture currently bound to the given target. Targets with a third axis
+// (cube maps and 3D textures) also get the R wrap mode, falling back to the T wrap mode when it is not set.
+func applyTextureOpts(target uint32, opts TextureOpts) {
+    gl.TexParameteri(target, gl.TEXTURE_WRAP_S, int32(opts.WrapS))
+    gl.TexParameteri(target, gl.TEXTURE_WRAP_T, int32(opts.WrapT))
+    gl.TexParameteri(target, gl.TEXTURE_MIN_FILTER, int32(opts.MinFilter))
+    gl.TexParameteri(target, gl.TEXTURE_MAG_FILTER, int32(opts.MagFilter))
+
+    if target == gl.TEXTURE_CUBE_MAP || target == gl.TEXTURE_3D {
+        wrapR := opts.WrapR
+        if wrapR == 0 {
+            wrapR = opts.WrapT
+        }
+
+        gl.TexParameteri(target, gl.TEXTURE_WRAP_R, int32(wrapR))
+    }
+}
+
+// decodes the image at the given path into a tightly packed RGBA image, optionally flipping it vertically
+func readImage(path string, flipY bool) (*image.RGBA, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+
+    defer f.Close()
+
+    img, _, err := image.Decode(f)
+
+    if err != nil {
+        return nil, err
+    }
+
+    return toRGBA(img, flipY), nil
+}
+
+// copies the image into a tightly packed RGBA image with its origin at 0,0, optionally flipping it vertically
+func toRGBA(img image.Image, flipY bool) *image.RGBA {
+    size := img.Bounds().Size()
+    rgba := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+    draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+    if flipY {
+        flipImage(rgba)
+    }
+
+    return rgba
+}
+
+// flips the given image vertically in place
+func flipImage(rgba *image.RGBA) {
+    rows := rgba.Rect.Size().Y
+    row := make([]uint8, rgba.Stride)
 
+    for y := 0; y < rows/2; y++ {
+        top := rgba.Pix[y*rgba.Stride : (y+1)*rgba.Stride]
+        bottom := rgba.Pix[(rows-1-y)*rgba.Stride : (rows-y)*rgba.Stride]
+
+        copy(row, top)
+        copy(top, bottom)
+        copy(bottom, row)
+    }
 }
 
 // --------------------------------------------------------------------------------------------------------
@@ -267,6 +314,7 @@ func ReadTexture(path string, opts TextureOpts) (*Texture, error) {
 // --------------------------------------------------------------------------------------------------------
 type Program struct {
     ptr uint32
+    res *resource
 }
 
 // sets this as the active program
@@ -274,6 +322,11 @@ func (p *Program) Use() {
     gl.UseProgram(p.ptr)
 }
 
+// deletes the underlying program
+func (p *Program) Delete() {
+    p.res.release()
+}
+
 // sets a uniform boolean value specified by the given name - this will set the value as an integer - 1 = true, 0 = false
 func (p *Program) Bool(name string, value bool) error {
     if value {
@@ -287,6 +340,7 @@ func (p *Program) Bool(name string, value bool) error {
 func (p *Program) Integer(name string, value int32) error {
     if location, err := p.uniform(name); err == nil {
         gl.Uniform1i(location, value)
+        uniformCheck(name)
         return nil
     } else {
         return err
@@ -297,6 +351,29 @@ func (p *Program) Integer(name string, value int32) error {
 func (p *Program) Float(name string, value float32) error {
     if location, err := p.uniform(name); err == nil {
         gl.Uniform1f(location, value)
+        uniformCheck(name)
+        return nil
+    } else {
+        return err
+    }
+}
+
+// sets a vec2 uniform value
+func (p *Program) Vec2(name string, value Vec2) error {
+    if location, err := p.uniform(name); err == nil {
+        gl.Uniform2f(location, value[0], value[1])
+        uniformCheck(name)
+        return nil
+    } else {
+        return err
+    }
+}
+
+// sets a vec3 uniform value
+func (p *Program) Vec3(name string, value Vec3) error {
+    if location, err := p.uniform(name); err == nil {
+        gl.Uniform3f(location, value[0], value[1], value[2])
+        uniformCheck(name)
         return nil
     } else {
         return err
@@ -307,21 +384,102 @@ func (p *Program) Float(name string, value float32) error {
 func (p *Program) Vec4(name string, value Vec4) error {
     if location, err := p.uniform(name); err == nil {
         gl.Uniform4f(location, value[0], value[1], value[2], value[3])
+        uniformCheck(name)
+        return nil
+    } else {
+        return err
+    }
+}
+
+// sets a vec4 uniform value from the colour components in RGBA order
+func (p *Program) Color(name string, value Color) error {
+    if location, err := p.uniform(name); err == nil {
+        gl.Uniform4f(location, value.r, value.g, value.b, value.a)
+        uniformCheck(name)
+        return nil
+    } else {
+        return err
+    }
+}
+
+// sets a mat3 uniform value
+func (p *Program) Mat3(name string, value Mat3) error {
+    if location, err := p.uniform(name); err == nil {
+        gl.UniformMatrix3fv(location, 1, false, &value[0])
+        uniformCheck(name)
         return nil
     } else {
         return err
     }
 }
 
-func (p *Program) Mat4(name string, value mgl32.Mat4) error {
+// sets a mat4 uniform value
+func (p *Program) Mat4(name string, value Mat4) error {
     if location, err := p.uniform(name); err == nil {
         gl.UniformMatrix4fv(location, 1, false, &value[0])
+        uniformCheck(name)
         return nil
     } else {
         return err
     }
 }
 
+// sets the elements of a mat4 array uniform, starting from the first
+func (p *Program) Mat4Array(name string, values []Mat4) error {
+    if len(values) == 0 {
+        return nil
+    }
+
+    if location, err := p.uniform(name); err == nil {
+        gl.UniformMatrix4fv(location, int32(len(values)), false, &values[0][0])
+        uniformCheck(name)
+        return nil
+    } else {
+        return err
+    }
+}
+
+// UniformSetter sets uniforms of a program one after another, keeping the first failure so a run of them can be
+// checked once at the end
+type UniformSetter struct {
+    prog *Program
+    err  error
+}
+
+// Returns a setter for the program's uniforms, which skips every call after the first that fails
+func (p *Program) Uniforms() *UniformSetter {
+    return &UniformSetter{prog: p}
+}
+
+// the first failure, nil when every uniform was set
+func (u *UniformSetter) Err() error {
+    return u.err
+}
+
+func (u *UniformSetter) Integer(name string, value int32) {
+    if u.err == nil {
+        u.err = u.prog.Integer(name, value)
+    }
+}
+
+func (u *UniformSetter) Float(name string, value float32) {
+    if u.err == nil {
+        u.err = u.prog.Float(name, value)
+    }
+}
+
+func (u *UniformSetter) Vec3(name string, value Vec3) {
+    if u.err == nil {
+        u.err = u.prog.Vec3(name, value)
+    }
+}
+
+func (u *UniformSetter) Vec4(name string, value Vec4) {
+    if u.err == nil {
+        u.err = u.prog.Vec4(name, value)
+    }
+}
+
 // gets the uniform location for the given name
 func (p *Program) uniform(name string) (int32, error) {
     location := gl.GetUniformLocation(p.ptr, gl.Str(name+"\x00"))
@@ -333,6 +491,24 @@ func (p *Program) uniform(name string) (int32, error) {
     return location, nil
 }
 
+// compiles and links a program from the given null terminated vertex and fragment sources, the intermediate shaders
+// are deleted once linked
+func compileProgram(vertexSource, fragmentSource string) (*Program, error) {
+    vsh, err := NewShader(VertexShader, vertexSource)
+    if err != nil {
+        return nil, err
+    }
+    defer vsh.Delete()
+
+    fsh, err := NewShader(FragmentShader, fragmentSource)
+    if err != nil {
+        return nil, err
+    }
+    defer fsh.Delete()
+
+    return NewProgram(vsh, fsh)
+}
+
 // Creates a new program instance from the given shader set. Callers to this function are required to manage the
 // shader cleanup (Delete) - this is not done here.
 func NewProgram(shaders ...*Shader) (*Program, error) {
@@ -357,7 +533,10 @@ func NewProgram(shaders ...*Shader) (*Program, error) {
     gl.GetProgramiv(prog, gl.LINK_STATUS, &status)
 
     if status != gl.FALSE {
-        return &Program{prog}, nil
+        p := &Program{ptr: prog}
+        p.res = track(p, programResource, prog)
+
+        return p, nil
     }
 
     var logLength int32
@@ -365,6 +544,7 @@ func NewProgram(shaders ...*Shader) (*Program, error) {
 
     olog := strings.Repeat("\x00", int(logLength+1))
     gl.GetProgramInfoLog(prog, logLength, nil, gl.Str(olog))
+    gl.DeleteProgram(prog)
 
     return nil, fmt.Errorf(
         "failed to link program: log = %s",
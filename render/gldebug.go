@@ -0,0 +1,365 @@
+package render
+
+import (
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "os"
+    "runtime"
+    "strconv"
+    "strings"
+    "unsafe"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// GL Debug Output
+// --------------------------------------------------------------------------------------------------------
+
+// setting this environment variable to a true value as read by strconv.ParseBool (1, t, true...) enables gl debug
+// output, as does building with -tags debug
+const DebugEnv = "LOGL_GL_DEBUG"
+
+// how serious a debug message is, in increasing order
+type DebugSeverity int
+
+const (
+    SeverityNotification DebugSeverity = iota
+    SeverityLow
+    SeverityMedium
+    SeverityHigh
+)
+
+func (s DebugSeverity) String() string {
+    switch s {
+    case SeverityNotification:
+        return "Notification"
+    case SeverityLow:
+        return "Low"
+    case SeverityMedium:
+        return "Medium"
+    case SeverityHigh:
+        return "High"
+    default:
+        return "Unknown"
+    }
+}
+
+// the part of the system a debug message came from
+type DebugSource uint32
+
+const (
+    SourceAPI            DebugSource = gl.DEBUG_SOURCE_API
+    SourceWindowSystem   DebugSource = gl.DEBUG_SOURCE_WINDOW_SYSTEM
+    SourceShaderCompiler DebugSource = gl.DEBUG_SOURCE_SHADER_COMPILER
+    SourceThirdParty     DebugSource = gl.DEBUG_SOURCE_THIRD_PARTY
+    SourceApplication    DebugSource = gl.DEBUG_SOURCE_APPLICATION
+    SourceOther          DebugSource = gl.DEBUG_SOURCE_OTHER
+)
+
+func (s DebugSource) String() string {
+    switch s {
+    case SourceAPI:
+        return "API"
+    case SourceWindowSystem:
+        return "Window System"
+    case SourceShaderCompiler:
+        return "Shader Compiler"
+    case SourceThirdParty:
+        return "Third Party"
+    case SourceApplication:
+        return "Application"
+    case SourceOther:
+        return "Other"
+    default:
+        return "Unknown"
+    }
+}
+
+// what a debug message is about
+type DebugType uint32
+
+const (
+    TypeError              DebugType = gl.DEBUG_TYPE_ERROR
+    TypeDeprecatedBehavior DebugType = gl.DEBUG_TYPE_DEPRECATED_BEHAVIOR
+    TypeUndefinedBehavior  DebugType = gl.DEBUG_TYPE_UNDEFINED_BEHAVIOR
+    TypePortability        DebugType = gl.DEBUG_TYPE_PORTABILITY
+    TypePerformance        DebugType = gl.DEBUG_TYPE_PERFORMANCE
+    TypeMarker             DebugType = gl.DEBUG_TYPE_MARKER
+    TypePushGroup          DebugType = gl.DEBUG_TYPE_PUSH_GROUP
+    TypePopGroup           DebugType = gl.DEBUG_TYPE_POP_GROUP
+    TypeOther              DebugType = gl.DEBUG_TYPE_OTHER
+)
+
+func (t DebugType) String() string {
+    switch t {
+    case TypeError:
+        return "Error"
+    case TypeDeprecatedBehavior:
+        return "Deprecated Behavior"
+    case TypeUndefinedBehavior:
+        return "Undefined Behavior"
+    case TypePortability:
+        return "Portability"
+    case TypePerformance:
+        return "Performance"
+    case TypeMarker:
+        return "Marker"
+    case TypePushGroup:
+        return "Push Group"
+    case TypePopGroup:
+        return "Pop Group"
+    case TypeOther:
+        return "Other"
+    default:
+        return "Unknown"
+    }
+}
+
+// a message reported by the driver, or by checking glGetError on contexts without debug output
+type DebugMessage struct {
+    Source   DebugSource
+    Type     DebugType
+    Severity DebugSeverity
+    ID       uint32
+    Message  string
+
+    // the file and line of the code outside the render package that made the failing call, when it could be found
+    Caller string
+}
+
+func (m DebugMessage) String() string {
+    s := fmt.Sprintf("%s %s [%s] %d: %s", m.Source, m.Type, m.Severity, m.ID, m.Message)
+    if m.Caller != "" {
+        s += " (at " + m.Caller + ")"
+    }
+    return s
+}
+
+// receives the debug messages at or above the severity set by SetDebugSeverity
+type DebugLogger func(msg DebugMessage)
+
+// writes debug messages to stderr
+func StderrDebugLogger(msg DebugMessage) {
+    fmt.Fprintf(os.Stderr, "render: gl %s\n", msg)
+}
+
+var glDebug = struct {
+    logger   DebugLogger
+    severity DebugSeverity
+
+    // whether the driver reports messages itself, otherwise errors are polled with glGetError
+    callback bool
+    enabled  bool
+}{
+    logger:   StderrDebugLogger,
+    severity: SeverityLow,
+}
+
+// whether gl debug output is requested by the build tag or environment
+func DebugOutputEnabled() bool {
+    if debugBuild {
+        return true
+    }
+
+    enabled, err := strconv.ParseBool(os.Getenv(DebugEnv))
+    return err == nil && enabled
+}
+
+// routes debug messages to the given logger, nil for StderrDebugLogger
+func SetDebugLogger(logger DebugLogger) {
+    if logger == nil {
+        logger = StderrDebugLogger
+    }
+    glDebug.logger = logger
+}
+
+// drops debug messages less severe than the given severity, SeverityLow by default
+func SetDebugSeverity(severity DebugSeverity) {
+    glDebug.severity = severity
+}
+
+func logDebugMessage(msg DebugMessage) {
+    if msg.Severity >= glDebug.severity {
+        glDebug.logger(msg)
+    }
+}
+
+// Turns on debug output for the current context, called by NewWindow when DebugOutputEnabled. Contexts with KHR_debug
+// (core in 4.3) or ARB_debug_output report through a synchronous callback so the call site is on the stack;
+// otherwise the render package checks glGetError after its draws, texture and framebuffer setup and uniform setters,
+// and once a frame. Which of the two is in use is logged at SeverityNotification.
+func enableDebugOutput() {
+    glDebug.enabled = true
+
+    var major, minor int32
+    gl.GetIntegerv(gl.MAJOR_VERSION, &major)
+    gl.GetIntegerv(gl.MINOR_VERSION, &minor)
+
+    khr := major > 4 || major == 4 && minor >= 3 || hasExtension("GL_KHR_debug")
+
+    switch {
+    case khr:
+        gl.Enable(gl.DEBUG_OUTPUT)
+        gl.Enable(gl.DEBUG_OUTPUT_SYNCHRONOUS)
+        gl.DebugMessageCallback(debugCallback, nil)
+    case hasExtension("GL_ARB_debug_output"):
+        gl.Enable(gl.DEBUG_OUTPUT_SYNCHRONOUS_ARB)
+        gl.DebugMessageCallbackARB(debugCallback, nil)
+    default:
+        logDebugMode("glGetError")
+        return
+    }
+
+    glDebug.callback = true
+    logDebugMode("callback")
+}
+
+func logDebugMode(mode string) {
+    logDebugMessage(DebugMessage{
+        Source:   SourceOther,
+        Type:     TypeOther,
+        Severity: SeverityNotification,
+        Message:  "debug output: mode = " + mode,
+    })
+}
+
+// whether the context supports the named extension
+func hasExtension(name string) bool {
+    var count int32
+    gl.GetIntegerv(gl.NUM_EXTENSIONS, &count)
+
+    for i := int32(0); i < count; i++ {
+        if gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i))) == name {
+            return true
+        }
+    }
+
+    return false
+}
+
+func debugCallback(source, gltype, id, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+    logDebugMessage(DebugMessage{
+        Source:   DebugSource(source),
+        Type:     DebugType(gltype),
+        Severity: decodeSeverity(severity),
+        ID:       id,
+        Message:  strings.TrimSpace(message),
+        Caller:   callSite(),
+    })
+}
+
+func decodeSeverity(severity uint32) DebugSeverity {
+    switch severity {
+    case gl.DEBUG_SEVERITY_HIGH:
+        return SeverityHigh
+    case gl.DEBUG_SEVERITY_MEDIUM:
+        return SeverityMedium
+    case gl.DEBUG_SEVERITY_LOW:
+        return SeverityLow
+    default:
+        return SeverityNotification
+    }
+}
+
+// the name of a glGetError code
+func glErrorName(code uint32) string {
+    switch code {
+    case gl.INVALID_ENUM:
+        return "GL_INVALID_ENUM"
+    case gl.INVALID_VALUE:
+        return "GL_INVALID_VALUE"
+    case gl.INVALID_OPERATION:
+        return "GL_INVALID_OPERATION"
+    case gl.INVALID_FRAMEBUFFER_OPERATION:
+        return "GL_INVALID_FRAMEBUFFER_OPERATION"
+    case gl.OUT_OF_MEMORY:
+        return "GL_OUT_OF_MEMORY"
+    default:
+        return fmt.Sprintf("0x%x", code)
+    }
+}
+
+// every error flag set since the last call, clearing them
+func glErrors() []string {
+    var errs []string
+
+    // more than one flag can be set at once, but a lost context reports errors forever
+    for i := 0; i < 16; i++ {
+        code := gl.GetError()
+        if code == gl.NO_ERROR {
+            break
+        }
+        errs = append(errs, glErrorName(code))
+    }
+
+    return errs
+}
+
+// Returns an error naming the gl error flags set since the last check (clearing them) and the calling file and line,
+// or nil when there are none. Works on any context, whether or not debug output is enabled.
+func CheckGL() error {
+    errs := glErrors()
+    if len(errs) == 0 {
+        return nil
+    }
+
+    caller := "unknown"
+    if _, file, line, ok := runtime.Caller(1); ok {
+        caller = fmt.Sprintf("%s:%d", file, line)
+    }
+
+    return fmt.Errorf("gl error: errors = %s, at = %s", strings.Join(errs, ", "), caller)
+}
+
+// Logs any gl errors raised by the named operation when debug output is on but the context has no callback. The flags
+// collect every error since the last check, so the caller reported is only approximate - an error from a raw gl call
+// made outside the render package is put down to the next render call that checks.
+func glCheck(op string) {
+    if !glDebug.enabled || glDebug.callback {
+        return
+    }
+
+    errs := glErrors()
+    if len(errs) == 0 {
+        return
+    }
+
+    logDebugMessage(DebugMessage{
+        Source:   SourceAPI,
+        Type:     TypeError,
+        Severity: SeverityHigh,
+        Message:  fmt.Sprintf("%s while %s", strings.Join(errs, ", "), op),
+        Caller:   callSite(),
+    })
+}
+
+// logs any gl errors raised setting the named uniform, see glCheck
+func uniformCheck(name string) {
+    if glDebug.enabled && !glDebug.callback {
+        glCheck("setting uniform " + name)
+    }
+}
+
+// the file and line of the innermost caller outside the gl bindings, the runtime and the render packages - so a
+// call made by a helper such as the glTF loader is reported at the application code that used the helper
+func callSite() string {
+    callers := make([]uintptr, 64)
+    frames := runtime.CallersFrames(callers[:runtime.Callers(2, callers)])
+
+    for {
+        frame, more := frames.Next()
+
+        internal := strings.HasPrefix(frame.Function, "runtime.") ||
+            strings.HasPrefix(frame.Function, "github.com/go-gl/") ||
+            strings.HasPrefix(frame.Function, "logl/render.") ||
+            strings.HasPrefix(frame.Function, "logl/render/") ||
+            strings.HasPrefix(frame.Function, "_cgo")
+
+        if !internal && frame.Function != "" {
+            return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+        }
+
+        if !more {
+            return ""
+        }
+    }
+}
@@ -0,0 +1,340 @@
+package gltf
+
+import (
+    "encoding/binary"
+    "fmt"
+    "math"
+)
+
+// accessor component types
+const (
+    componentByte          = 5120
+    componentUnsignedByte  = 5121
+    componentShort         = 5122
+    componentUnsignedShort = 5123
+    componentUnsignedInt   = 5125
+    componentFloat         = 5126
+)
+
+// the size in bytes of each component type
+var componentSizes = map[int]int{
+    componentByte:          1,
+    componentUnsignedByte:  1,
+    componentShort:         2,
+    componentUnsignedShort: 2,
+    componentUnsignedInt:   4,
+    componentFloat:         4,
+}
+
+// the rows and columns of each accessor type, vectors and scalars being a single column
+var accessorShapes = map[string][2]int{
+    "SCALAR": {1, 1},
+    "VEC2":   {2, 1},
+    "VEC3":   {3, 1},
+    "VEC4":   {4, 1},
+    "MAT2":   {2, 2},
+    "MAT3":   {3, 3},
+    "MAT4":   {4, 4},
+}
+
+// the position of elements within a buffer view. Matrix columns start on 4 byte boundaries, which pads the columns of
+// MAT2 with byte components and MAT3 with byte or short components.
+type elementLayout struct {
+    data          []byte
+    componentType int
+    size          int
+    rows          int
+    columnStride  int
+    stride        int
+}
+
+func newElementLayout(data []byte, offset, count, componentType int, shape [2]int, stride int,
+    path string) (elementLayout, error) {
+
+    size := componentSizes[componentType]
+    rows, columns := shape[0], shape[1]
+
+    columnStride := rows * size
+    if columns > 1 {
+        columnStride = (columnStride + 3) &^ 3
+    }
+
+    elementSize := columnStride * columns
+    if stride == 0 {
+        stride = elementSize
+    } else if stride < elementSize {
+        return elementLayout{}, invalid(path, "byte stride %d is smaller than the %d byte elements", stride, elementSize)
+    }
+
+    // compared by division, as the end of the last element can overflow an int for huge counts or strides
+    if offset < 0 || count < 1 || offset > len(data) || len(data)-offset < elementSize ||
+        (len(data)-offset-elementSize)/stride < count-1 {
+        return elementLayout{}, invalid(path, "%d elements at offset %d exceed the %d byte buffer view", count,
+            offset, len(data))
+    }
+
+    return elementLayout{
+        data:          data[offset:],
+        componentType: componentType,
+        size:          size,
+        rows:          rows,
+        columnStride:  columnStride,
+        stride:        stride,
+    }, nil
+}
+
+// the bytes of component c of element i
+func (l elementLayout) component(i, c int) []byte {
+    offset := i*l.stride + (c/l.rows)*l.columnStride + (c%l.rows)*l.size
+    return l.data[offset : offset+l.size]
+}
+
+// reads a component as a float, normalising integers to 0..1 (unsigned) or -1..1 (signed) when asked
+func (l elementLayout) float(i, c int, normalized bool) float32 {
+    b := l.component(i, c)
+
+    switch l.componentType {
+    case componentFloat:
+        return math.Float32frombits(binary.LittleEndian.Uint32(b))
+    case componentByte:
+        if normalized {
+            return float32(math.Max(float64(int8(b[0]))/127, -1))
+        }
+        return float32(int8(b[0]))
+    case componentUnsignedByte:
+        if normalized {
+            return float32(b[0]) / 255
+        }
+        return float32(b[0])
+    case componentShort:
+        v := int16(binary.LittleEndian.Uint16(b))
+        if normalized {
+            return float32(math.Max(float64(v)/32767, -1))
+        }
+        return float32(v)
+    case componentUnsignedShort:
+        v := binary.LittleEndian.Uint16(b)
+        if normalized {
+            return float32(v) / 65535
+        }
+        return float32(v)
+    default:
+        v := binary.LittleEndian.Uint32(b)
+        if normalized {
+            return float32(float64(v) / math.MaxUint32)
+        }
+        return float32(v)
+    }
+}
+
+// reads an integer component - only meaningful for the unsigned integer component types
+func (l elementLayout) uint(i, c int) uint32 {
+    b := l.component(i, c)
+
+    switch l.size {
+    case 1:
+        return uint32(b[0])
+    case 2:
+        return uint32(binary.LittleEndian.Uint16(b))
+    default:
+        return binary.LittleEndian.Uint32(b)
+    }
+}
+
+// reads the elements of an accessor, including any sparse substitutions
+type accessorReader struct {
+    def        accessorDef
+    path       string
+    components int
+    normalized bool
+
+    // nil when the accessor has no buffer view, in which case the values are all zero
+    dense *elementLayout
+
+    // the sparse values by element index
+    sparse       map[int]int
+    sparseValues elementLayout
+}
+
+// validates an accessor and prepares to read it, path naming the property referring to it
+func (d *Document) accessor(index int, path string) (*accessorReader, error) {
+    if err := requiredIndex(index, len(d.doc.Accessors), path); err != nil {
+        return nil, err
+    }
+
+    def := d.doc.Accessors[index]
+    path = fmt.Sprintf("accessors[%d]", index)
+
+    if _, ok := componentSizes[def.ComponentType]; !ok {
+        return nil, invalid(path+".componentType", "invalid component type %d", def.ComponentType)
+    }
+
+    shape, ok := accessorShapes[def.Type]
+    if !ok {
+        return nil, invalid(path+".type", "invalid type %q", def.Type)
+    }
+
+    if def.Count < 1 {
+        return nil, invalid(path+".count", "invalid count %d", def.Count)
+    }
+
+    if def.Normalized && (def.ComponentType == componentFloat || def.ComponentType == componentUnsignedInt) {
+        return nil, invalid(path+".normalized", "component type %d can't be normalized", def.ComponentType)
+    }
+
+    r := &accessorReader{
+        def:        def,
+        path:       path,
+        components: shape[0] * shape[1],
+        normalized: def.Normalized,
+    }
+
+    if def.BufferView != nil {
+        view, err := optionalIndex(def.BufferView, len(d.doc.BufferViews), path+".bufferView")
+        if err != nil {
+            return nil, err
+        }
+
+        layout, err := newElementLayout(d.bufferView(view), def.ByteOffset, def.Count, def.ComponentType, shape,
+            d.doc.BufferViews[view].ByteStride, path)
+        if err != nil {
+            return nil, err
+        }
+
+        r.dense = &layout
+    }
+
+    if def.Sparse != nil {
+        if err := d.readSparse(r, shape); err != nil {
+            return nil, err
+        }
+    }
+
+    return r, nil
+}
+
+func (d *Document) readSparse(r *accessorReader, shape [2]int) error {
+    sparse := r.def.Sparse
+    path := r.path + ".sparse"
+
+    if sparse.Count < 1 || sparse.Count > r.def.Count {
+        return invalid(path+".count", "invalid count %d for %d elements", sparse.Count, r.def.Count)
+    }
+
+    indices := sparse.Indices
+    switch indices.ComponentType {
+    case componentUnsignedByte, componentUnsignedShort, componentUnsignedInt:
+    default:
+        return invalid(path+".indices.componentType", "invalid component type %d", indices.ComponentType)
+    }
+
+    if err := requiredIndex(indices.BufferView, len(d.doc.BufferViews), path+".indices.bufferView"); err != nil {
+        return err
+    }
+
+    if err := requiredIndex(sparse.Values.BufferView, len(d.doc.BufferViews), path+".values.bufferView"); err != nil {
+        return err
+    }
+
+    indexLayout, err := newElementLayout(d.bufferView(indices.BufferView), indices.ByteOffset, sparse.Count,
+        indices.ComponentType, accessorShapes["SCALAR"], 0, path+".indices")
+    if err != nil {
+        return err
+    }
+
+    r.sparseValues, err = newElementLayout(d.bufferView(sparse.Values.BufferView), sparse.Values.ByteOffset,
+        sparse.Count, r.def.ComponentType, shape, 0, path+".values")
+    if err != nil {
+        return err
+    }
+
+    r.sparse = make(map[int]int, sparse.Count)
+
+    previous := -1
+    for i := 0; i < sparse.Count; i++ {
+        index := int(indexLayout.uint(i, 0))
+        if index <= previous || index >= r.def.Count {
+            return invalid(path+".indices", "index %d at %d is out of range or not increasing", index, i)
+        }
+
+        r.sparse[index] = i
+        previous = index
+    }
+
+    return nil
+}
+
+// the layout and element index holding element i
+func (r *accessorReader) source(i int) (*elementLayout, int) {
+    if slot, ok := r.sparse[i]; ok {
+        return &r.sparseValues, slot
+    }
+
+    return r.dense, i
+}
+
+// component c of element i as a float
+func (r *accessorReader) float(i, c int) float32 {
+    layout, i := r.source(i)
+    if layout == nil {
+        return 0
+    }
+
+    return layout.float(i, c, r.normalized)
+}
+
+// component c of element i as an unsigned integer
+func (r *accessorReader) uint(i, c int) uint32 {
+    layout, i := r.source(i)
+    if layout == nil {
+        return 0
+    }
+
+    return layout.uint(i, c)
+}
+
+// checks the accessor has one of the given types
+func (r *accessorReader) expectType(types ...string) error {
+    for _, t := range types {
+        if r.def.Type == t {
+            return nil
+        }
+    }
+
+    return invalid(r.path+".type", "expected %v, found %s", types, r.def.Type)
+}
+
+// checks the accessor has one of the given component types, with normalized integers counting as floats
+func (r *accessorReader) expectComponent(float bool, types ...int) error {
+    for _, t := range types {
+        if r.def.ComponentType == t && (!float || t == componentFloat || r.normalized) {
+            return nil
+        }
+    }
+
+    return invalid(r.path+".componentType", "unexpected component type %d", r.def.ComponentType)
+}
+
+// all the values of the accessor as floats, element by element
+func (r *accessorReader) floats() []float32 {
+    values := make([]float32, r.def.Count*r.components)
+    for i := 0; i < r.def.Count; i++ {
+        for c := 0; c < r.components; c++ {
+            values[i*r.components+c] = r.float(i, c)
+        }
+    }
+
+    return values
+}
+
+// all the values of the accessor as unsigned integers, element by element
+func (r *accessorReader) uints() []uint32 {
+    values := make([]uint32, r.def.Count*r.components)
+    for i := 0; i < r.def.Count; i++ {
+        for c := 0; c < r.components; c++ {
+            values[i*r.components+c] = r.uint(i, c)
+        }
+    }
+
+    return values
+}
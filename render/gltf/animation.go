@@ -0,0 +1,226 @@
+package gltf
+
+import (
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "logl/render/anim"
+    "logl/render/scene"
+)
+
+// Returns the scene graph nodes of a graph built by SceneGraph indexed like Nodes, with nil for nodes outside the
+// scene. This is the slice animation players and skins are given to find the nodes they animate.
+func (d *Document) SceneNodes(root *scene.Node) []*scene.Node {
+    indices := make(map[*Node]int, len(d.Nodes))
+    for i, n := range d.Nodes {
+        indices[n] = i
+    }
+
+    nodes := make([]*scene.Node, len(d.Nodes))
+
+    var visit func(n *scene.Node)
+    visit = func(n *scene.Node) {
+        if node, ok := n.Data.(*Node); ok {
+            if i, ok := indices[node]; ok {
+                nodes[i] = n
+            }
+        }
+
+        for _, child := range n.Children() {
+            visit(child)
+        }
+    }
+
+    visit(root)
+
+    return nodes
+}
+
+func (d *Document) loadSkins() error {
+    for i, def := range d.doc.Skins {
+        path := fmt.Sprintf("skins[%d]", i)
+
+        if len(def.Joints) == 0 {
+            return invalid(path+".joints", "no joints")
+        }
+
+        for j, joint := range def.Joints {
+            if err := requiredIndex(joint, len(d.Nodes), fmt.Sprintf("%s.joints[%d]", path, j)); err != nil {
+                return err
+            }
+        }
+
+        skeleton, err := optionalIndex(def.Skeleton, len(d.Nodes), path+".skeleton")
+        if err != nil {
+            return err
+        }
+
+        skin := &anim.Skin{Name: def.Name, Joints: def.Joints, Skeleton: skeleton}
+
+        if def.InverseBindMatrices != nil {
+            r, err := d.accessor(*def.InverseBindMatrices, path+".inverseBindMatrices")
+            if err != nil {
+                return err
+            }
+
+            if err := r.expectType("MAT4"); err != nil {
+                return err
+            }
+
+            if err := r.expectComponent(true, componentFloat); err != nil {
+                return err
+            }
+
+            if r.def.Count < len(def.Joints) {
+                return invalid(r.path+".count", "%d matrices for %d joints", r.def.Count, len(def.Joints))
+            }
+
+            values := r.floats()
+            for j := range def.Joints {
+                var m mgl32.Mat4
+                copy(m[:], values[j*16:(j+1)*16])
+                skin.InverseBindMatrices = append(skin.InverseBindMatrices, m)
+            }
+        } else {
+            for range def.Joints {
+                skin.InverseBindMatrices = append(skin.InverseBindMatrices, mgl32.Ident4())
+            }
+        }
+
+        d.Skins = append(d.Skins, skin)
+    }
+
+    return nil
+}
+
+var interpolations = map[string]anim.Interpolation{
+    "":            anim.Linear,
+    "LINEAR":      anim.Linear,
+    "STEP":        anim.Step,
+    "CUBICSPLINE": anim.CubicSpline,
+}
+
+var animationPaths = map[string]anim.Path{
+    "translation": anim.Translation,
+    "rotation":    anim.Rotation,
+    "scale":       anim.Scale,
+    "weights":     anim.Weights,
+}
+
+func (d *Document) loadAnimations() error {
+    for i, def := range d.doc.Animations {
+        path := fmt.Sprintf("animations[%d]", i)
+
+        // samplers are shared between channels animating the same property, and decoded for the first
+        samplers := make([]*anim.Sampler, len(def.Samplers))
+        var channels []anim.Channel
+
+        for j, ch := range def.Channels {
+            channelPath := fmt.Sprintf("%s.channels[%d]", path, j)
+
+            // channels without a node target something defined by an extension
+            if ch.Target.Node == nil {
+                continue
+            }
+
+            node, err := optionalIndex(ch.Target.Node, len(d.Nodes), channelPath+".target.node")
+            if err != nil {
+                return err
+            }
+
+            target, ok := animationPaths[ch.Target.Path]
+            if !ok {
+                return invalid(channelPath+".target.path", "invalid path %q", ch.Target.Path)
+            }
+
+            if err := requiredIndex(ch.Sampler, len(def.Samplers), channelPath+".sampler"); err != nil {
+                return err
+            }
+
+            if samplers[ch.Sampler] == nil {
+                samplerPath := fmt.Sprintf("%s.samplers[%d]", path, ch.Sampler)
+
+                samplers[ch.Sampler], err = d.loadAnimationSampler(def.Samplers[ch.Sampler], target, samplerPath)
+                if err != nil {
+                    return err
+                }
+            }
+
+            channels = append(channels, anim.Channel{Node: node, Path: target, Sampler: samplers[ch.Sampler]})
+        }
+
+        d.Animations = append(d.Animations, anim.NewClip(def.Name, channels))
+    }
+
+    return nil
+}
+
+func (d *Document) loadAnimationSampler(def animationSamplerDef, target anim.Path, path string) (*anim.Sampler, error) {
+    interpolation, ok := interpolations[def.Interpolation]
+    if !ok {
+        return nil, invalid(path+".interpolation", "invalid interpolation %q", def.Interpolation)
+    }
+
+    input, err := d.accessor(def.Input, path+".input")
+    if err != nil {
+        return nil, err
+    }
+
+    if err := input.expectType("SCALAR"); err != nil {
+        return nil, err
+    }
+
+    if err := input.expectComponent(true, componentFloat); err != nil {
+        return nil, err
+    }
+
+    times := input.floats()
+    for k := 1; k < len(times); k++ {
+        if times[k] <= times[k-1] {
+            return nil, invalid(input.path, "keyframe times must increase, %g follows %g", times[k], times[k-1])
+        }
+    }
+
+    output, err := d.accessor(def.Output, path+".output")
+    if err != nil {
+        return nil, err
+    }
+
+    switch target {
+    case anim.Translation, anim.Scale:
+        err = output.expectType("VEC3")
+        if err == nil {
+            err = output.expectComponent(true, componentFloat)
+        }
+    case anim.Rotation:
+        err = output.expectType("VEC4")
+        if err == nil {
+            err = output.expectComponent(true, componentFloat, componentByte, componentUnsignedByte, componentShort,
+                componentUnsignedShort)
+        }
+    default:
+        err = output.expectType("SCALAR")
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    values := output.floats()
+
+    // values per keyframe - morph target weights hold one scalar per target
+    perKey := len(times)
+    if interpolation == anim.CubicSpline {
+        perKey *= 3
+    }
+
+    if len(values)%perKey != 0 || (target != anim.Weights && len(values) != perKey*output.components) {
+        return nil, invalid(output.path+".count", "%d values don't match %d keyframes", output.def.Count, len(times))
+    }
+
+    return &anim.Sampler{
+        Times:         times,
+        Values:        values,
+        Components:    len(values) / perKey,
+        Interpolation: interpolation,
+    }, nil
+}
@@ -0,0 +1,342 @@
+// Package gltf imports glTF 2.0 assets, both .gltf JSON (with external or data URI buffers and images) and binary
+// .glb files. Meshes are decoded into model meshes, images into image.Image ready for render.NewTexture, the node
+// hierarchy into scene graph nodes and skins and animations into their anim package equivalents.
+package gltf
+
+import (
+    "bytes"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/json"
+    "fmt"
+    "image"
+    _ "image/jpeg"
+    _ "image/png"
+    "io"
+    "io/ioutil"
+    "logl/render/anim"
+    "net/url"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+)
+
+// Document is an imported glTF asset. Everything refers to everything else by index into these slices, as in the
+// file itself, with -1 for none.
+type Document struct {
+    Meshes    []*Mesh
+    Materials []*Material
+    Textures  []*Texture
+    Images    []image.Image
+    Samplers  []*Sampler
+    Cameras   []*Camera
+    Nodes     []*Node
+    Scenes    []*Scene
+    Skins     []*anim.Skin
+
+    // animation channels target nodes by their index in Nodes - see SceneNodes
+    Animations []*anim.Clip
+
+    // the scene to show by default, -1 when the file doesn't say
+    Scene int
+
+    doc     *document
+    buffers [][]byte
+}
+
+// ValidationError reports content that breaks the glTF specification, naming where it was found as a JSON path
+// such as meshes[0].primitives[1].attributes.POSITION
+type ValidationError struct {
+    Path    string
+    Message string
+}
+
+func (e *ValidationError) Error() string {
+    return fmt.Sprintf("gltf: %s: %s", e.Path, e.Message)
+}
+
+func invalid(path string, format string, args ...interface{}) error {
+    return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// opens an external resource referenced by uri from a document
+type Opener func(uri string) (io.ReadCloser, error)
+
+// Reads a .gltf or .glb file, resolving external resources relative to its directory
+func Read(path string) (*Document, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    dir := filepath.Dir(path)
+
+    return Parse(data, func(uri string) (io.ReadCloser, error) {
+        return os.Open(filepath.Join(dir, filepath.FromSlash(uri)))
+    })
+}
+
+// Parses a glTF document from either JSON or GLB data. External buffers and images are loaded through the opener,
+// which may be nil when the document is self contained.
+func Parse(data []byte, opener Opener) (*Document, error) {
+    var bin []byte
+
+    if len(data) >= 4 && string(data[:4]) == "glTF" {
+        var err error
+        if data, bin, err = splitGLB(data); err != nil {
+            return nil, err
+        }
+    }
+
+    doc := &document{}
+    if err := json.Unmarshal(data, doc); err != nil {
+        return nil, fmt.Errorf("gltf: invalid JSON: %v", err)
+    }
+
+    if !strings.HasPrefix(doc.Asset.Version, "2.") {
+        return nil, invalid("asset.version", "unsupported version %q, expected 2.x", doc.Asset.Version)
+    }
+
+    // no extensions are implemented, so any the asset can't be loaded without are fatal
+    if len(doc.ExtensionsRequired) > 0 {
+        return nil, invalid("extensionsRequired[0]", "unsupported extension %s", doc.ExtensionsRequired[0])
+    }
+
+    d := &Document{doc: doc, Scene: -1}
+
+    if err := d.loadBuffers(bin, opener); err != nil {
+        return nil, err
+    }
+
+    if err := d.checkBufferViews(); err != nil {
+        return nil, err
+    }
+
+    if err := d.loadImages(opener); err != nil {
+        return nil, err
+    }
+
+    // later steps only refer back to what is already loaded
+    steps := []func() error{
+        d.loadSamplers,
+        d.loadTextures,
+        d.loadMaterials,
+        d.loadMeshes,
+        d.loadCameras,
+        d.loadNodes,
+        d.loadSkins,
+        d.loadAnimations,
+        d.loadScenes,
+    }
+
+    for _, step := range steps {
+        if err := step(); err != nil {
+            return nil, err
+        }
+    }
+
+    return d, nil
+}
+
+// GLB chunk types
+const (
+    glbJSON = 0x4e4f534a
+    glbBIN  = 0x004e4942
+)
+
+// splits a binary glTF container into its JSON and (optional) binary chunks
+func splitGLB(data []byte) ([]byte, []byte, error) {
+    if len(data) < 20 {
+        return nil, nil, fmt.Errorf("gltf: truncated GLB header")
+    }
+
+    version := binary.LittleEndian.Uint32(data[4:])
+    length := binary.LittleEndian.Uint32(data[8:])
+
+    if version != 2 {
+        return nil, nil, fmt.Errorf("gltf: unsupported GLB version: version = %d", version)
+    }
+
+    if int(length) > len(data) {
+        return nil, nil, fmt.Errorf("gltf: truncated GLB: length = %d, size = %d", length, len(data))
+    }
+
+    var jsonChunk, binChunk []byte
+
+    for offset := 12; offset+8 <= int(length); {
+        size := int(binary.LittleEndian.Uint32(data[offset:]))
+        kind := binary.LittleEndian.Uint32(data[offset+4:])
+        offset += 8
+
+        if offset+size > int(length) {
+            return nil, nil, fmt.Errorf("gltf: GLB chunk overruns the file: offset = %d, size = %d", offset, size)
+        }
+
+        chunk := data[offset : offset+size]
+        offset += size
+
+        switch {
+        case kind == glbJSON && jsonChunk == nil:
+            jsonChunk = chunk
+        case kind == glbBIN && binChunk == nil && jsonChunk != nil:
+            binChunk = chunk
+        }
+        // unknown chunks are skipped as the specification requires
+    }
+
+    if jsonChunk == nil {
+        return nil, nil, fmt.Errorf("gltf: GLB has no JSON chunk")
+    }
+
+    return jsonChunk, binChunk, nil
+}
+
+// reads the data a uri refers to - either an embedded base64 data URI or an external resource
+func readURI(uri string, opener Opener) ([]byte, error) {
+    if strings.HasPrefix(uri, "data:") {
+        comma := strings.IndexByte(uri, ',')
+        if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+            return nil, fmt.Errorf("only base64 data URIs are supported")
+        }
+
+        return base64.StdEncoding.DecodeString(uri[comma+1:])
+    }
+
+    if opener == nil {
+        return nil, fmt.Errorf("no opener for external resource %s", uri)
+    }
+
+    name, err := url.PathUnescape(uri)
+    if err != nil {
+        return nil, err
+    }
+
+    // an asset may only refer to files within its own directory
+    clean := path.Clean(strings.Replace(name, "\\", "/", -1))
+    if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+        return nil, fmt.Errorf("external resource %s is outside the asset directory", uri)
+    }
+
+    rc, err := opener(name)
+    if err != nil {
+        return nil, err
+    }
+
+    defer rc.Close()
+
+    return ioutil.ReadAll(rc)
+}
+
+func (d *Document) loadBuffers(bin []byte, opener Opener) error {
+    d.buffers = make([][]byte, len(d.doc.Buffers))
+
+    for i, def := range d.doc.Buffers {
+        path := fmt.Sprintf("buffers[%d]", i)
+
+        var data []byte
+        if def.URI == "" {
+            // only the first buffer of a GLB may omit its uri, taking the binary chunk
+            if i != 0 || bin == nil {
+                return invalid(path+".uri", "missing uri")
+            }
+            data = bin
+        } else {
+            var err error
+            if data, err = readURI(def.URI, opener); err != nil {
+                return invalid(path+".uri", "%v", err)
+            }
+        }
+
+        if def.ByteLength < 0 {
+            return invalid(path+".byteLength", "negative length %d", def.ByteLength)
+        }
+
+        if len(data) < def.ByteLength {
+            return invalid(path+".byteLength", "%d bytes expected, found %d", def.ByteLength, len(data))
+        }
+
+        d.buffers[i] = data[:def.ByteLength]
+    }
+
+    return nil
+}
+
+func (d *Document) checkBufferViews() error {
+    for i, view := range d.doc.BufferViews {
+        path := fmt.Sprintf("bufferViews[%d]", i)
+
+        if view.Buffer < 0 || view.Buffer >= len(d.buffers) {
+            return invalid(path+".buffer", "index %d out of range", view.Buffer)
+        }
+
+        // compared without adding the two, which could overflow
+        size := len(d.buffers[view.Buffer])
+        if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteOffset > size || view.ByteLength > size-view.ByteOffset {
+            return invalid(path, "range %d+%d exceeds buffer %d of %d bytes", view.ByteOffset, view.ByteLength,
+                view.Buffer, size)
+        }
+
+        if view.ByteStride != 0 && (view.ByteStride < 4 || view.ByteStride > 252 || view.ByteStride%4 != 0) {
+            return invalid(path+".byteStride", "invalid stride %d", view.ByteStride)
+        }
+    }
+
+    return nil
+}
+
+// the bytes of a buffer view, which checkBufferViews has bounded
+func (d *Document) bufferView(index int) []byte {
+    view := d.doc.BufferViews[index]
+    return d.buffers[view.Buffer][view.ByteOffset:][:view.ByteLength]
+}
+
+// checks an optional index refers to one of count elements, returning it or -1 when absent
+func optionalIndex(index *int, count int, path string) (int, error) {
+    if index == nil {
+        return -1, nil
+    }
+
+    if *index < 0 || *index >= count {
+        return -1, invalid(path, "index %d out of range", *index)
+    }
+
+    return *index, nil
+}
+
+// checks a required index refers to one of count elements
+func requiredIndex(index, count int, path string) error {
+    if index < 0 || index >= count {
+        return invalid(path, "index %d out of range", index)
+    }
+
+    return nil
+}
+
+// reads an image from either its uri or a buffer view
+func (d *Document) readImage(def imageDef, path string, opener Opener) (image.Image, error) {
+    var data []byte
+
+    switch {
+    case def.BufferView != nil:
+        index, err := optionalIndex(def.BufferView, len(d.doc.BufferViews), path+".bufferView")
+        if err != nil {
+            return nil, err
+        }
+        data = d.bufferView(index)
+    case def.URI != "":
+        var err error
+        if data, err = readURI(def.URI, opener); err != nil {
+            return nil, invalid(path+".uri", "%v", err)
+        }
+    default:
+        return nil, invalid(path, "neither uri nor bufferView given")
+    }
+
+    img, _, err := image.Decode(bytes.NewReader(data))
+    if err != nil {
+        return nil, invalid(path, "decoding image: %v", err)
+    }
+
+    return img, nil
+}
@@ -0,0 +1,139 @@
+package gltf
+
+import (
+    "bytes"
+    "io"
+    "io/ioutil"
+    "math"
+    "testing"
+)
+
+func TestElementLayoutBounds(t *testing.T) {
+    data := make([]byte, 64)
+
+    tests := []struct {
+        name          string
+        offset, count int
+        stride        int
+        ok            bool
+    }{
+        {"exact fit", 0, 16, 0, true},
+        {"strided", 4, 4, 16, true},
+        {"one too many", 0, 17, 0, false},
+        {"past the end", 64, 1, 0, false},
+        {"negative offset", -4, 1, 0, false},
+        {"overflowing count", 0, math.MaxInt64/4 + 2, 4, false},
+        {"overflowing stride", 0, 3, math.MaxInt64 / 2, false},
+    }
+
+    for _, test := range tests {
+        _, err := newElementLayout(data, test.offset, test.count, componentFloat, accessorShapes["SCALAR"],
+            test.stride, "accessors[0]")
+        if (err == nil) != test.ok {
+            t.Errorf("%s: error = %v, expected ok = %v", test.name, err, test.ok)
+        }
+    }
+}
+
+func TestBufferBounds(t *testing.T) {
+    opener := func(uri string) (io.ReadCloser, error) {
+        return ioutil.NopCloser(bytes.NewReader(make([]byte, 16))), nil
+    }
+
+    tests := []struct {
+        name       string
+        byteLength string
+        views      string
+        ok         bool
+    }{
+        {"whole buffer", "16", `[{"buffer":0,"byteLength":16}]`, true},
+        {"end of the buffer", "16", `[{"buffer":0,"byteOffset":12,"byteLength":4}]`, true},
+        {"shorter buffer", "8", `[{"buffer":0,"byteLength":8}]`, true},
+        {"buffer too long", "20", `[]`, false},
+        {"negative buffer length", "-1", `[]`, false},
+        {"view too long", "16", `[{"buffer":0,"byteOffset":4,"byteLength":16}]`, false},
+        {"view past the end", "16", `[{"buffer":0,"byteOffset":17,"byteLength":0}]`, false},
+        {"negative view offset", "16", `[{"buffer":0,"byteOffset":-4,"byteLength":4}]`, false},
+        {"negative view length", "16", `[{"buffer":0,"byteLength":-4}]`, false},
+        {"overflowing view", "16", `[{"buffer":0,"byteOffset":9223372036854775807,"byteLength":1}]`, false},
+    }
+
+    for _, test := range tests {
+        json := `{"asset":{"version":"2.0"},"buffers":[{"uri":"data.bin","byteLength":` + test.byteLength +
+            `}],"bufferViews":` + test.views + `}`
+
+        _, err := Parse([]byte(json), opener)
+        if (err == nil) != test.ok {
+            t.Errorf("%s: error = %v, expected ok = %v", test.name, err, test.ok)
+        }
+    }
+}
+
+func TestSceneRoots(t *testing.T) {
+    tests := []struct {
+        name string
+        json string
+        path string
+    }{
+        {
+            "root is a child",
+            `{"asset":{"version":"2.0"},"nodes":[{"children":[1]},{}],"scenes":[{"nodes":[0,1]}]}`,
+            "scenes[0].nodes[1]",
+        },
+        {
+            "root listed twice",
+            `{"asset":{"version":"2.0"},"nodes":[{}],"scenes":[{"nodes":[0,0]}]}`,
+            "scenes[0].nodes[1]",
+        },
+        {
+            "valid",
+            `{"asset":{"version":"2.0"},"nodes":[{"children":[1]},{},{}],"scenes":[{"nodes":[0,2]}]}`,
+            "",
+        },
+    }
+
+    for _, test := range tests {
+        _, err := Parse([]byte(test.json), nil)
+
+        if test.path == "" {
+            if err != nil {
+                t.Errorf("%s: unexpected error %v", test.name, err)
+            }
+            continue
+        }
+
+        verr, ok := err.(*ValidationError)
+        if !ok || verr.Path != test.path {
+            t.Errorf("%s: error = %v, expected a validation error at %s", test.name, err, test.path)
+        }
+    }
+}
+
+func TestExternalURIs(t *testing.T) {
+    opener := func(uri string) (io.ReadCloser, error) {
+        return ioutil.NopCloser(bytes.NewReader(make([]byte, 4))), nil
+    }
+
+    tests := []struct {
+        uri string
+        ok  bool
+    }{
+        {"data.bin", true},
+        {"sub/data.bin", true},
+        {"sub/../data.bin", true},
+        {"../data.bin", false},
+        {"sub/../../data.bin", false},
+        {"%2E%2E/data.bin", false},
+        {"..\\\\data.bin", false},
+        {"/etc/data.bin", false},
+    }
+
+    for _, test := range tests {
+        json := `{"asset":{"version":"2.0"},"buffers":[{"uri":"` + test.uri + `","byteLength":4}]}`
+
+        _, err := Parse([]byte(json), opener)
+        if (err == nil) != test.ok {
+            t.Errorf("%s: error = %v, expected ok = %v", test.uri, err, test.ok)
+        }
+    }
+}
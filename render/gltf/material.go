@@ -0,0 +1,313 @@
+package gltf
+
+import (
+    "fmt"
+    "logl/render"
+)
+
+// how the alpha of the base colour is treated
+type AlphaMode int
+
+const (
+    // alpha is ignored and the surface fully opaque
+    AlphaOpaque AlphaMode = iota
+
+    // fragments with alpha below the cutoff are discarded, the rest are opaque
+    AlphaMask
+
+    // alpha blended with what is behind
+    AlphaBlend
+)
+
+func (m AlphaMode) String() string {
+    switch m {
+    case AlphaOpaque:
+        return "OPAQUE"
+    case AlphaMask:
+        return "MASK"
+    case AlphaBlend:
+        return "BLEND"
+    default:
+        return "Unknown"
+    }
+}
+
+// TextureRef is a material's use of a texture
+type TextureRef struct {
+    // the index of the texture, -1 when the material doesn't use one
+    Texture int
+
+    // which set of texture coordinates to sample with (TEXCOORD_n)
+    TexCoord int
+
+    // the normal map scale or occlusion strength, 1 for other textures
+    Scale float32
+}
+
+// Material is a PBR metallic-roughness material. The colour factors are linear and multiply the (sRGB encoded)
+// texture values once those are converted to linear.
+type Material struct {
+    Name string
+
+    BaseColor        render.Color
+    BaseColorTexture TextureRef
+
+    Metallic                 float32
+    Roughness                float32
+    MetallicRoughnessTexture TextureRef // metalness in blue, roughness in green
+
+    NormalTexture    TextureRef // tangent space, scale applies to x and y
+    OcclusionTexture TextureRef // occlusion in red
+
+    Emissive        render.Color
+    EmissiveTexture TextureRef
+
+    AlphaMode   AlphaMode
+    AlphaCutoff float32
+
+    // back faces should not be culled and are lit with reversed normals
+    DoubleSided bool
+}
+
+// the glTF default material, used by primitives without one
+func DefaultMaterial() *Material {
+    return &Material{
+        BaseColor:                render.White,
+        BaseColorTexture:         TextureRef{Texture: -1},
+        Metallic:                 1,
+        Roughness:                1,
+        MetallicRoughnessTexture: TextureRef{Texture: -1},
+        NormalTexture:            TextureRef{Texture: -1},
+        OcclusionTexture:         TextureRef{Texture: -1},
+        Emissive:                 render.Black,
+        EmissiveTexture:          TextureRef{Texture: -1},
+        AlphaCutoff:              0.5,
+    }
+}
+
+// Texture pairs an image with how it is sampled
+type Texture struct {
+    Name string
+
+    // the index of the image, -1 when not given
+    Image int
+
+    // the index of the sampler, -1 for repeat wrapping and filtering left to the implementation
+    Sampler int
+}
+
+// Sampler holds the filtering and wrapping of a texture, as gl enum values
+type Sampler struct {
+    Name string
+
+    // zero when the file leaves filtering to the implementation
+    MagFilter render.TextureFilter
+    MinFilter render.TextureFilter
+
+    WrapS render.TextureWrap
+    WrapT render.TextureWrap
+}
+
+// the texture options matching the sampler - unspecified filtering becomes trilinear with mipmaps
+func (s *Sampler) TextureOpts() render.TextureOpts {
+    opts := render.TextureOpts{
+        WrapS:     s.WrapS,
+        WrapT:     s.WrapT,
+        MinFilter: s.MinFilter,
+        MagFilter: s.MagFilter,
+    }
+
+    if opts.MinFilter == 0 {
+        opts.MinFilter = render.LinearMipmapLinear
+    }
+
+    if opts.MagFilter == 0 {
+        opts.MagFilter = render.Linear
+    }
+
+    switch opts.MinFilter {
+    case render.Nearest, render.Linear:
+    default:
+        opts.GenMipMap = true
+    }
+
+    return opts
+}
+
+// Uploads a texture with the options of its sampler. The image is not flipped - glTF texture coordinates have their
+// origin at the top left, which matches the first row of pixels uploaded.
+func (d *Document) NewTexture(index int) (*render.Texture, error) {
+    if index < 0 || index >= len(d.Textures) {
+        return nil, fmt.Errorf("gltf: no such texture: index = %d", index)
+    }
+
+    texture := d.Textures[index]
+    if texture.Image < 0 {
+        return nil, fmt.Errorf("gltf: texture has no image: index = %d", index)
+    }
+
+    sampler := &Sampler{WrapS: render.Repeat, WrapT: render.Repeat}
+    if texture.Sampler >= 0 {
+        sampler = d.Samplers[texture.Sampler]
+    }
+
+    return render.NewTexture(d.Images[texture.Image], sampler.TextureOpts()), nil
+}
+
+func (d *Document) loadImages(opener Opener) error {
+    for i, def := range d.doc.Images {
+        img, err := d.readImage(def, fmt.Sprintf("images[%d]", i), opener)
+        if err != nil {
+            return err
+        }
+
+        d.Images = append(d.Images, img)
+    }
+
+    return nil
+}
+
+func (d *Document) loadSamplers() error {
+    for i, def := range d.doc.Samplers {
+        path := fmt.Sprintf("samplers[%d]", i)
+
+        sampler := &Sampler{
+            Name:      def.Name,
+            MagFilter: render.TextureFilter(def.MagFilter),
+            MinFilter: render.TextureFilter(def.MinFilter),
+            WrapS:     render.Repeat,
+            WrapT:     render.Repeat,
+        }
+
+        switch sampler.MagFilter {
+        case 0, render.Nearest, render.Linear:
+        default:
+            return invalid(path+".magFilter", "invalid filter %d", def.MagFilter)
+        }
+
+        switch sampler.MinFilter {
+        case 0, render.Nearest, render.Linear, render.NearestMipmapNearest, render.LinearMipmapNearest,
+            render.NearestMipmapLinear, render.LinearMipmapLinear:
+        default:
+            return invalid(path+".minFilter", "invalid filter %d", def.MinFilter)
+        }
+
+        for _, wrap := range []struct {
+            value *int
+            dst   *render.TextureWrap
+            name  string
+        }{{def.WrapS, &sampler.WrapS, "wrapS"}, {def.WrapT, &sampler.WrapT, "wrapT"}} {
+            if wrap.value == nil {
+                continue
+            }
+
+            switch w := render.TextureWrap(*wrap.value); w {
+            case render.Repeat, render.ClampToEdge, render.MirroredRepeat:
+                *wrap.dst = w
+            default:
+                return invalid(path+"."+wrap.name, "invalid wrap mode %d", *wrap.value)
+            }
+        }
+
+        d.Samplers = append(d.Samplers, sampler)
+    }
+
+    return nil
+}
+
+func (d *Document) loadTextures() error {
+    for i, def := range d.doc.Textures {
+        path := fmt.Sprintf("textures[%d]", i)
+
+        img, err := optionalIndex(def.Source, len(d.Images), path+".source")
+        if err != nil {
+            return err
+        }
+
+        sampler, err := optionalIndex(def.Sampler, len(d.Samplers), path+".sampler")
+        if err != nil {
+            return err
+        }
+
+        d.Textures = append(d.Textures, &Texture{Name: def.Name, Image: img, Sampler: sampler})
+    }
+
+    return nil
+}
+
+func (d *Document) loadMaterials() error {
+    for i, def := range d.doc.Materials {
+        path := fmt.Sprintf("materials[%d]", i)
+
+        m := DefaultMaterial()
+        m.Name = def.Name
+        m.DoubleSided = def.DoubleSided
+        m.Emissive = render.RGB(def.EmissiveFactor[0], def.EmissiveFactor[1], def.EmissiveFactor[2])
+
+        var err error
+        textureRef := func(info *textureInfoDef, name string) TextureRef {
+            if info == nil || err != nil {
+                return TextureRef{Texture: -1}
+            }
+
+            if err = requiredIndex(info.Index, len(d.Textures), path+"."+name+".index"); err != nil {
+                return TextureRef{Texture: -1}
+            }
+
+            ref := TextureRef{Texture: info.Index, TexCoord: info.TexCoord, Scale: 1}
+            if info.Scale != nil {
+                ref.Scale = *info.Scale
+            }
+            if info.Strength != nil {
+                ref.Scale = *info.Strength
+            }
+
+            return ref
+        }
+
+        if pbr := def.PBRMetallicRoughness; pbr != nil {
+            if f := pbr.BaseColorFactor; f != nil {
+                m.BaseColor = render.RGBA(f[0], f[1], f[2], f[3])
+            }
+
+            if pbr.MetallicFactor != nil {
+                m.Metallic = *pbr.MetallicFactor
+            }
+
+            if pbr.RoughnessFactor != nil {
+                m.Roughness = *pbr.RoughnessFactor
+            }
+
+            m.BaseColorTexture = textureRef(pbr.BaseColorTexture, "pbrMetallicRoughness.baseColorTexture")
+            m.MetallicRoughnessTexture = textureRef(pbr.MetallicRoughnessTexture,
+                "pbrMetallicRoughness.metallicRoughnessTexture")
+        }
+
+        m.NormalTexture = textureRef(def.NormalTexture, "normalTexture")
+        m.OcclusionTexture = textureRef(def.OcclusionTexture, "occlusionTexture")
+        m.EmissiveTexture = textureRef(def.EmissiveTexture, "emissiveTexture")
+
+        if err != nil {
+            return err
+        }
+
+        switch def.AlphaMode {
+        case "", "OPAQUE":
+            m.AlphaMode = AlphaOpaque
+        case "MASK":
+            m.AlphaMode = AlphaMask
+        case "BLEND":
+            m.AlphaMode = AlphaBlend
+        default:
+            return invalid(path+".alphaMode", "invalid alpha mode %q", def.AlphaMode)
+        }
+
+        if def.AlphaCutoff != nil {
+            m.AlphaCutoff = *def.AlphaCutoff
+        }
+
+        d.Materials = append(d.Materials, m)
+    }
+
+    return nil
+}
@@ -0,0 +1,269 @@
+package gltf
+
+import (
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "logl/render/model"
+)
+
+// primitive topologies
+const (
+    modePoints        = 0
+    modeLines         = 1
+    modeLineLoop      = 2
+    modeLineStrip     = 3
+    modeTriangles     = 4
+    modeTriangleStrip = 5
+    modeTriangleFan   = 6
+)
+
+// Mesh is a named set of primitives drawn together by a node
+type Mesh struct {
+    Name       string
+    Primitives []*Primitive
+}
+
+// Primitive is a part of a mesh drawn with a single material. Strips and fans are converted to triangle lists, and
+// non indexed primitives are given sequential indices.
+type Primitive struct {
+    Mesh *model.Mesh
+
+    // the index of the material, -1 for the default material
+    Material int
+}
+
+func (d *Document) loadMeshes() error {
+    for i, def := range d.doc.Meshes {
+        mesh := &Mesh{Name: def.Name}
+
+        for j, primDef := range def.Primitives {
+            path := fmt.Sprintf("meshes[%d].primitives[%d]", i, j)
+
+            prim, err := d.loadPrimitive(primDef, path)
+            if err != nil {
+                return err
+            }
+
+            // points and lines have no place in a triangle mesh and are dropped
+            if prim == nil {
+                continue
+            }
+
+            prim.Mesh.Name = def.Name
+            mesh.Primitives = append(mesh.Primitives, prim)
+        }
+
+        d.Meshes = append(d.Meshes, mesh)
+    }
+
+    return nil
+}
+
+func (d *Document) loadPrimitive(def primitiveDef, path string) (*Primitive, error) {
+    mode := modeTriangles
+    if def.Mode != nil {
+        mode = *def.Mode
+    }
+
+    switch mode {
+    case modePoints, modeLines, modeLineLoop, modeLineStrip:
+        return nil, nil
+    case modeTriangles, modeTriangleStrip, modeTriangleFan:
+    default:
+        return nil, invalid(path+".mode", "invalid mode %d", mode)
+    }
+
+    material, err := optionalIndex(def.Material, len(d.Materials), path+".material")
+    if err != nil {
+        return nil, err
+    }
+
+    mesh := &model.Mesh{}
+    if material >= 0 {
+        mesh.Material = d.Materials[material].Name
+    }
+
+    if _, ok := def.Attributes["POSITION"]; !ok {
+        return nil, invalid(path+".attributes", "missing POSITION")
+    }
+
+    count := -1
+    attribute := func(name string, types []string, float bool, components ...int) ([]float32, int, error) {
+        index, ok := def.Attributes[name]
+        if !ok {
+            return nil, 0, nil
+        }
+
+        r, err := d.accessor(index, path+".attributes."+name)
+        if err != nil {
+            return nil, 0, err
+        }
+
+        if err := r.expectType(types...); err != nil {
+            return nil, 0, err
+        }
+
+        if err := r.expectComponent(float, components...); err != nil {
+            return nil, 0, err
+        }
+
+        if count >= 0 && r.def.Count != count {
+            return nil, 0, invalid(path+".attributes."+name, "has %d elements, POSITION has %d", r.def.Count, count)
+        }
+        count = r.def.Count
+
+        return r.floats(), r.components, nil
+    }
+
+    floatOnly := []int{componentFloat}
+    floatOrNormalized := []int{componentFloat, componentUnsignedByte, componentUnsignedShort}
+
+    positions, _, err := attribute("POSITION", []string{"VEC3"}, true, floatOnly...)
+    if err != nil {
+        return nil, err
+    }
+
+    mesh.Positions = toVec3s(positions)
+
+    normals, _, err := attribute("NORMAL", []string{"VEC3"}, true, floatOnly...)
+    if err != nil {
+        return nil, err
+    }
+
+    mesh.Normals = toVec3s(normals)
+
+    texCoords, _, err := attribute("TEXCOORD_0", []string{"VEC2"}, true, floatOrNormalized...)
+    if err != nil {
+        return nil, err
+    }
+
+    for i := 0; i+1 < len(texCoords); i += 2 {
+        mesh.TexCoords = append(mesh.TexCoords, mgl32.Vec2{texCoords[i], texCoords[i+1]})
+    }
+
+    tangents, _, err := attribute("TANGENT", []string{"VEC4"}, true, floatOnly...)
+    if err != nil {
+        return nil, err
+    }
+
+    mesh.Tangents = toVec4s(tangents, 4)
+
+    colors, components, err := attribute("COLOR_0", []string{"VEC3", "VEC4"}, true, floatOrNormalized...)
+    if err != nil {
+        return nil, err
+    }
+
+    mesh.Colors = toVec4s(colors, components)
+
+    joints, _, err := attribute("JOINTS_0", []string{"VEC4"}, false, componentUnsignedByte, componentUnsignedShort)
+    if err != nil {
+        return nil, err
+    }
+
+    for i := 0; i+3 < len(joints); i += 4 {
+        mesh.Joints = append(mesh.Joints,
+            [4]uint16{uint16(joints[i]), uint16(joints[i+1]), uint16(joints[i+2]), uint16(joints[i+3])})
+    }
+
+    weights, _, err := attribute("WEIGHTS_0", []string{"VEC4"}, true, floatOrNormalized...)
+    if err != nil {
+        return nil, err
+    }
+
+    mesh.Weights = toVec4s(weights, 4)
+
+    indices, err := d.loadIndices(def.Indices, len(mesh.Positions), path)
+    if err != nil {
+        return nil, err
+    }
+
+    mesh.Indices = toTriangleList(indices, mode)
+
+    return &Primitive{Mesh: mesh, Material: material}, nil
+}
+
+// reads the indices of a primitive, or generates sequential indices when it has none
+func (d *Document) loadIndices(index *int, vertices int, path string) ([]uint32, error) {
+    if index == nil {
+        indices := make([]uint32, vertices)
+        for i := range indices {
+            indices[i] = uint32(i)
+        }
+        return indices, nil
+    }
+
+    r, err := d.accessor(*index, path+".indices")
+    if err != nil {
+        return nil, err
+    }
+
+    if err := r.expectType("SCALAR"); err != nil {
+        return nil, err
+    }
+
+    if err := r.expectComponent(false, componentUnsignedByte, componentUnsignedShort, componentUnsignedInt); err != nil {
+        return nil, err
+    }
+
+    indices := r.uints()
+    for i, v := range indices {
+        if int(v) >= vertices {
+            return nil, invalid(r.path, "index %d at %d out of range for %d vertices", v, i, vertices)
+        }
+    }
+
+    return indices, nil
+}
+
+// converts strip and fan indices to a triangle list, keeping the winding of the first triangle throughout
+func toTriangleList(indices []uint32, mode int) []uint32 {
+    switch mode {
+    case modeTriangleStrip:
+        triangles := make([]uint32, 0, 3*len(indices))
+        for i := 0; i+2 < len(indices); i++ {
+            if i%2 == 0 {
+                triangles = append(triangles, indices[i], indices[i+1], indices[i+2])
+            } else {
+                triangles = append(triangles, indices[i+1], indices[i], indices[i+2])
+            }
+        }
+        return triangles
+    case modeTriangleFan:
+        triangles := make([]uint32, 0, 3*len(indices))
+        for i := 1; i+1 < len(indices); i++ {
+            triangles = append(triangles, indices[i], indices[i+1], indices[0])
+        }
+        return triangles
+    default:
+        return indices[:len(indices)/3*3]
+    }
+}
+
+func toVec3s(values []float32) []mgl32.Vec3 {
+    if len(values) == 0 {
+        return nil
+    }
+
+    vectors := make([]mgl32.Vec3, len(values)/3)
+    for i := range vectors {
+        vectors[i] = mgl32.Vec3{values[i*3], values[i*3+1], values[i*3+2]}
+    }
+
+    return vectors
+}
+
+// packs 3 or 4 component values into vectors, with a w of 1 for 3 component values
+func toVec4s(values []float32, components int) []mgl32.Vec4 {
+    if len(values) == 0 {
+        return nil
+    }
+
+    vectors := make([]mgl32.Vec4, len(values)/components)
+    for i := range vectors {
+        v := mgl32.Vec4{0, 0, 0, 1}
+        copy(v[:], values[i*components:(i+1)*components])
+        vectors[i] = v
+    }
+
+    return vectors
+}
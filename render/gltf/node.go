@@ -0,0 +1,310 @@
+package gltf
+
+import (
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "logl/render/scene"
+    "math"
+)
+
+// Node is an element of the node hierarchy with its local transform. Nodes given a matrix in the file have it
+// decomposed into translation, rotation and scale.
+type Node struct {
+    Name     string
+    Children []int
+
+    // the indices of the mesh, camera and skin attached to the node, -1 for none
+    Mesh   int
+    Camera int
+    Skin   int
+
+    Translation mgl32.Vec3
+    Rotation    mgl32.Quat
+    Scale       mgl32.Vec3
+}
+
+// Scene is a set of root nodes
+type Scene struct {
+    Name  string
+    Nodes []int
+}
+
+// the kind of projection a camera uses
+type CameraType int
+
+const (
+    PerspectiveCamera CameraType = iota
+    OrthographicCamera
+)
+
+// Camera holds a projection, looking down -Z of the node it is attached to
+type Camera struct {
+    Name string
+    Type CameraType
+
+    // perspective: the vertical field of view in radians, and the aspect ratio (0 to follow the viewport)
+    YFov        float32
+    AspectRatio float32
+
+    // orthographic: half the width and height of the view
+    XMag float32
+    YMag float32
+
+    // the far plane is 0 for an infinite perspective projection
+    ZNear float32
+    ZFar  float32
+}
+
+// the projection matrix of the camera, using the given aspect ratio when the camera doesn't fix its own
+func (c *Camera) Projection(aspect float32) mgl32.Mat4 {
+    if c.Type == OrthographicCamera {
+        return mgl32.Ortho(-c.XMag, c.XMag, -c.YMag, c.YMag, c.ZNear, c.ZFar)
+    }
+
+    if c.AspectRatio > 0 {
+        aspect = c.AspectRatio
+    }
+
+    if c.ZFar > 0 {
+        return mgl32.Perspective(c.YFov, aspect, c.ZNear, c.ZFar)
+    }
+
+    // the limit of the perspective matrix as the far plane goes to infinity
+    f := float32(1 / math.Tan(float64(c.YFov)/2))
+
+    return mgl32.Mat4{
+        f / aspect, 0, 0, 0,
+        0, f, 0, 0,
+        0, 0, -1, -1,
+        0, 0, -2 * c.ZNear, 0,
+    }
+}
+
+// Builds a scene graph of the given scene (-1 for the default scene, or the first when there is no default). The
+// returned root holds the scene's root nodes as children, and every node's Data is its *Node from the document.
+func (d *Document) SceneGraph(index int) (*scene.Node, error) {
+    if index < 0 {
+        index = d.Scene
+    }
+
+    if index < 0 {
+        index = 0
+    }
+
+    if index >= len(d.Scenes) {
+        return nil, fmt.Errorf("gltf: no such scene: index = %d", index)
+    }
+
+    s := d.Scenes[index]
+
+    root := scene.NewNode(s.Name)
+
+    for _, n := range s.Nodes {
+        if err := root.AddChild(d.sceneNode(n)); err != nil {
+            return nil, err
+        }
+    }
+
+    return root, nil
+}
+
+func (d *Document) sceneNode(index int) *scene.Node {
+    n := d.Nodes[index]
+
+    node := scene.NewNode(n.Name)
+    node.Data = n
+    node.SetTransform(n.Translation, n.Rotation, n.Scale)
+
+    for _, child := range n.Children {
+        // cycles are rejected when loading, so this can't fail
+        _ = node.AddChild(d.sceneNode(child))
+    }
+
+    return node
+}
+
+func (d *Document) loadCameras() error {
+    for i, def := range d.doc.Cameras {
+        path := fmt.Sprintf("cameras[%d]", i)
+        camera := &Camera{Name: def.Name}
+
+        switch def.Type {
+        case "perspective":
+            p := def.Perspective
+            if p == nil {
+                return invalid(path+".perspective", "missing")
+            }
+
+            if p.YFov <= 0 || p.ZNear <= 0 || (p.ZFar != 0 && p.ZFar <= p.ZNear) {
+                return invalid(path+".perspective", "invalid projection")
+            }
+
+            camera.Type = PerspectiveCamera
+            camera.YFov, camera.AspectRatio, camera.ZNear, camera.ZFar = p.YFov, p.AspectRatio, p.ZNear, p.ZFar
+
+        case "orthographic":
+            o := def.Orthographic
+            if o == nil {
+                return invalid(path+".orthographic", "missing")
+            }
+
+            if o.XMag == 0 || o.YMag == 0 || o.ZNear < 0 || o.ZFar <= o.ZNear {
+                return invalid(path+".orthographic", "invalid projection")
+            }
+
+            camera.Type = OrthographicCamera
+            camera.XMag, camera.YMag, camera.ZNear, camera.ZFar = o.XMag, o.YMag, o.ZNear, o.ZFar
+
+        default:
+            return invalid(path+".type", "invalid camera type %q", def.Type)
+        }
+
+        d.Cameras = append(d.Cameras, camera)
+    }
+
+    return nil
+}
+
+func (d *Document) loadNodes() error {
+    parents := make([]int, len(d.doc.Nodes))
+    for i := range parents {
+        parents[i] = -1
+    }
+
+    for i, def := range d.doc.Nodes {
+        path := fmt.Sprintf("nodes[%d]", i)
+
+        node := &Node{
+            Name:     def.Name,
+            Rotation: mgl32.QuatIdent(),
+            Scale:    mgl32.Vec3{1, 1, 1},
+        }
+
+        var err error
+        if node.Mesh, err = optionalIndex(def.Mesh, len(d.Meshes), path+".mesh"); err != nil {
+            return err
+        }
+
+        if node.Camera, err = optionalIndex(def.Camera, len(d.Cameras), path+".camera"); err != nil {
+            return err
+        }
+
+        // skins are loaded after the nodes they refer to
+        if node.Skin, err = optionalIndex(def.Skin, len(d.doc.Skins), path+".skin"); err != nil {
+            return err
+        }
+
+        for j, child := range def.Children {
+            childPath := fmt.Sprintf("%s.children[%d]", path, j)
+            if err := requiredIndex(child, len(d.doc.Nodes), childPath); err != nil {
+                return err
+            }
+
+            if parents[child] >= 0 {
+                return invalid(childPath, "node %d already has parent %d", child, parents[child])
+            }
+            parents[child] = i
+        }
+        node.Children = def.Children
+
+        if def.Matrix != nil {
+            if def.Translation != nil || def.Rotation != nil || def.Scale != nil {
+                return invalid(path+".matrix", "can't be combined with translation, rotation or scale")
+            }
+
+            node.Translation, node.Rotation, node.Scale = decompose(mgl32.Mat4(*def.Matrix))
+        }
+
+        if def.Translation != nil {
+            node.Translation = mgl32.Vec3(*def.Translation)
+        }
+
+        if def.Rotation != nil {
+            r := def.Rotation
+            node.Rotation = mgl32.Quat{W: r[3], V: mgl32.Vec3{r[0], r[1], r[2]}}
+        }
+
+        if def.Scale != nil {
+            node.Scale = mgl32.Vec3(*def.Scale)
+        }
+
+        d.Nodes = append(d.Nodes, node)
+    }
+
+    // with one parent per node, a cycle is a chain of parents that never reaches a root
+    for i := range parents {
+        steps := 0
+        for p := parents[i]; p >= 0; p = parents[p] {
+            if steps++; steps > len(parents) {
+                return invalid(fmt.Sprintf("nodes[%d]", i), "is part of a cycle")
+            }
+        }
+    }
+
+    return nil
+}
+
+func (d *Document) loadScenes() error {
+    for i, def := range d.doc.Scenes {
+        path := fmt.Sprintf("scenes[%d]", i)
+
+        for j, n := range def.Nodes {
+            nodePath := fmt.Sprintf("%s.nodes[%d]", path, j)
+            if err := requiredIndex(n, len(d.Nodes), nodePath); err != nil {
+                return err
+            }
+
+            if p := d.parent(n); p >= 0 {
+                return invalid(nodePath, "node %d is a child of node %d, not a root", n, p)
+            }
+
+            for _, m := range def.Nodes[:j] {
+                if m == n {
+                    return invalid(nodePath, "node %d is listed twice", n)
+                }
+            }
+        }
+
+        d.Scenes = append(d.Scenes, &Scene{Name: def.Name, Nodes: def.Nodes})
+    }
+
+    var err error
+    d.Scene, err = optionalIndex(d.doc.Scene, len(d.Scenes), "scene")
+
+    return err
+}
+
+// the index of the node with the given node as a child, or -1 for none
+func (d *Document) parent(index int) int {
+    for i, n := range d.Nodes {
+        for _, child := range n.Children {
+            if child == index {
+                return i
+            }
+        }
+    }
+
+    return -1
+}
+
+// splits an affine matrix without shear into translation, rotation and scale. A negative determinant is taken as a
+// reflection in x.
+func decompose(m mgl32.Mat4) (mgl32.Vec3, mgl32.Quat, mgl32.Vec3) {
+    translation := m.Col(3).Vec3()
+
+    scale := mgl32.Vec3{m.Col(0).Vec3().Len(), m.Col(1).Vec3().Len(), m.Col(2).Vec3().Len()}
+    if m.Mat3().Det() < 0 {
+        scale[0] = -scale[0]
+    }
+
+    var rotation mgl32.Mat3
+    for c := 0; c < 3; c++ {
+        column := m.Col(c).Vec3()
+        if scale[c] != 0 {
+            column = column.Mul(1 / scale[c])
+        }
+        rotation.SetCol(c, column)
+    }
+
+    return translation, mgl32.Mat4ToQuat(rotation.Mat4()).Normalize(), scale
+}
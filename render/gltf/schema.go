@@ -0,0 +1,188 @@
+package gltf
+
+// the JSON structure of a glTF 2.0 document - optional indices are pointers so that absent can be told apart from 0
+
+type document struct {
+    Asset struct {
+        Version    string `json:"version"`
+        MinVersion string `json:"minVersion"`
+    } `json:"asset"`
+
+    ExtensionsRequired []string `json:"extensionsRequired"`
+
+    Buffers     []bufferDef     `json:"buffers"`
+    BufferViews []bufferViewDef `json:"bufferViews"`
+    Accessors   []accessorDef   `json:"accessors"`
+    Images      []imageDef      `json:"images"`
+    Samplers    []samplerDef    `json:"samplers"`
+    Textures    []textureDef    `json:"textures"`
+    Materials   []materialDef   `json:"materials"`
+    Meshes      []meshDef       `json:"meshes"`
+    Cameras     []cameraDef     `json:"cameras"`
+    Nodes       []nodeDef       `json:"nodes"`
+    Scenes      []sceneDef      `json:"scenes"`
+    Scene       *int            `json:"scene"`
+    Skins       []skinDef       `json:"skins"`
+    Animations  []animationDef  `json:"animations"`
+}
+
+type bufferDef struct {
+    Name       string `json:"name"`
+    URI        string `json:"uri"`
+    ByteLength int    `json:"byteLength"`
+}
+
+type bufferViewDef struct {
+    Name       string `json:"name"`
+    Buffer     int    `json:"buffer"`
+    ByteOffset int    `json:"byteOffset"`
+    ByteLength int    `json:"byteLength"`
+    ByteStride int    `json:"byteStride"`
+}
+
+type accessorDef struct {
+    Name          string     `json:"name"`
+    BufferView    *int       `json:"bufferView"`
+    ByteOffset    int        `json:"byteOffset"`
+    ComponentType int        `json:"componentType"`
+    Normalized    bool       `json:"normalized"`
+    Count         int        `json:"count"`
+    Type          string     `json:"type"`
+    Sparse        *sparseDef `json:"sparse"`
+}
+
+type sparseDef struct {
+    Count   int `json:"count"`
+    Indices struct {
+        BufferView    int `json:"bufferView"`
+        ByteOffset    int `json:"byteOffset"`
+        ComponentType int `json:"componentType"`
+    } `json:"indices"`
+    Values struct {
+        BufferView int `json:"bufferView"`
+        ByteOffset int `json:"byteOffset"`
+    } `json:"values"`
+}
+
+type imageDef struct {
+    Name       string `json:"name"`
+    URI        string `json:"uri"`
+    MimeType   string `json:"mimeType"`
+    BufferView *int   `json:"bufferView"`
+}
+
+type samplerDef struct {
+    Name      string `json:"name"`
+    MagFilter int    `json:"magFilter"`
+    MinFilter int    `json:"minFilter"`
+    WrapS     *int   `json:"wrapS"`
+    WrapT     *int   `json:"wrapT"`
+}
+
+type textureDef struct {
+    Name    string `json:"name"`
+    Sampler *int   `json:"sampler"`
+    Source  *int   `json:"source"`
+}
+
+type textureInfoDef struct {
+    Index    int      `json:"index"`
+    TexCoord int      `json:"texCoord"`
+    Scale    *float32 `json:"scale"`
+    Strength *float32 `json:"strength"`
+}
+
+type materialDef struct {
+    Name string `json:"name"`
+
+    PBRMetallicRoughness *struct {
+        BaseColorFactor          *[4]float32     `json:"baseColorFactor"`
+        BaseColorTexture         *textureInfoDef `json:"baseColorTexture"`
+        MetallicFactor           *float32        `json:"metallicFactor"`
+        RoughnessFactor          *float32        `json:"roughnessFactor"`
+        MetallicRoughnessTexture *textureInfoDef `json:"metallicRoughnessTexture"`
+    } `json:"pbrMetallicRoughness"`
+
+    NormalTexture    *textureInfoDef `json:"normalTexture"`
+    OcclusionTexture *textureInfoDef `json:"occlusionTexture"`
+    EmissiveTexture  *textureInfoDef `json:"emissiveTexture"`
+    EmissiveFactor   [3]float32      `json:"emissiveFactor"`
+    AlphaMode        string          `json:"alphaMode"`
+    AlphaCutoff      *float32        `json:"alphaCutoff"`
+    DoubleSided      bool            `json:"doubleSided"`
+}
+
+type meshDef struct {
+    Name       string         `json:"name"`
+    Primitives []primitiveDef `json:"primitives"`
+}
+
+type primitiveDef struct {
+    Attributes map[string]int `json:"attributes"`
+    Indices    *int           `json:"indices"`
+    Material   *int           `json:"material"`
+    Mode       *int           `json:"mode"`
+}
+
+type cameraDef struct {
+    Name string `json:"name"`
+    Type string `json:"type"`
+
+    Perspective *struct {
+        AspectRatio float32 `json:"aspectRatio"`
+        YFov        float32 `json:"yfov"`
+        ZNear       float32 `json:"znear"`
+        ZFar        float32 `json:"zfar"`
+    } `json:"perspective"`
+
+    Orthographic *struct {
+        XMag  float32 `json:"xmag"`
+        YMag  float32 `json:"ymag"`
+        ZNear float32 `json:"znear"`
+        ZFar  float32 `json:"zfar"`
+    } `json:"orthographic"`
+}
+
+type nodeDef struct {
+    Name        string       `json:"name"`
+    Children    []int        `json:"children"`
+    Mesh        *int         `json:"mesh"`
+    Camera      *int         `json:"camera"`
+    Skin        *int         `json:"skin"`
+    Matrix      *[16]float32 `json:"matrix"`
+    Translation *[3]float32  `json:"translation"`
+    Rotation    *[4]float32  `json:"rotation"`
+    Scale       *[3]float32  `json:"scale"`
+}
+
+type sceneDef struct {
+    Name  string `json:"name"`
+    Nodes []int  `json:"nodes"`
+}
+
+type skinDef struct {
+    Name                string `json:"name"`
+    InverseBindMatrices *int   `json:"inverseBindMatrices"`
+    Skeleton            *int   `json:"skeleton"`
+    Joints              []int  `json:"joints"`
+}
+
+type animationDef struct {
+    Name     string                `json:"name"`
+    Channels []animationChannelDef `json:"channels"`
+    Samplers []animationSamplerDef `json:"samplers"`
+}
+
+type animationChannelDef struct {
+    Sampler int `json:"sampler"`
+    Target  struct {
+        Node *int   `json:"node"`
+        Path string `json:"path"`
+    } `json:"target"`
+}
+
+type animationSamplerDef struct {
+    Input         int    `json:"input"`
+    Interpolation string `json:"interpolation"`
+    Output        int    `json:"output"`
+}
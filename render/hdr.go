@@ -0,0 +1,190 @@
+package render
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "os"
+    "strings"
+)
+
+// holds a decoded high dynamic range image as tightly packed RGB float32 triples, top row first
+type hdrImage struct {
+    width  int
+    height int
+    pix    []float32
+}
+
+// flips the image vertically in place
+func (h *hdrImage) flip() {
+    stride := h.width * 3
+    row := make([]float32, stride)
+
+    for y := 0; y < h.height/2; y++ {
+        top := h.pix[y*stride : (y+1)*stride]
+        bottom := h.pix[(h.height-1-y)*stride : (h.height-y)*stride]
+
+        copy(row, top)
+        copy(top, bottom)
+        copy(bottom, row)
+    }
+}
+
+// reads a Radiance RGBE (.hdr) image from the given path
+func readHDR(path string) (*hdrImage, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+
+    defer f.Close()
+
+    return decodeHDR(f)
+}
+
+// decodes a Radiance RGBE image - both flat and new-style run length encoded scanlines are supported, only the
+// standard "-Y height +X width" orientation is accepted
+func decodeHDR(r io.Reader) (*hdrImage, error) {
+    br := bufio.NewReader(r)
+
+    magic, err := br.ReadString('\n')
+    if err != nil {
+        return nil, err
+    }
+
+    if !strings.HasPrefix(magic, "#?") {
+        return nil, errors.New("invalid hdr header: missing #? signature")
+    }
+
+    // header variables run until the first blank line
+    for {
+        line, err := br.ReadString('\n')
+        if err != nil {
+            return nil, err
+        }
+
+        line = strings.TrimSpace(line)
+        if line == "" {
+            break
+        }
+
+        if strings.HasPrefix(line, "FORMAT=") && line != "FORMAT=32-bit_rle_rgbe" {
+            return nil, fmt.Errorf("unsupported hdr format: %s", line)
+        }
+    }
+
+    resolution, err := br.ReadString('\n')
+    if err != nil {
+        return nil, err
+    }
+
+    var width, height int
+    if _, err := fmt.Sscanf(resolution, "-Y %d +X %d", &height, &width); err != nil {
+        return nil, fmt.Errorf("unsupported hdr resolution line: %q", strings.TrimSpace(resolution))
+    }
+
+    if width < 1 || height < 1 {
+        return nil, fmt.Errorf("invalid hdr dimensions: width = %d, height = %d", width, height)
+    }
+
+    img := &hdrImage{
+        width:  width,
+        height: height,
+        pix:    make([]float32, width*height*3),
+    }
+
+    scanline := make([]byte, width*4)
+
+    for y := 0; y < height; y++ {
+        if err := readScanline(br, scanline, width); err != nil {
+            return nil, fmt.Errorf("failed to read hdr scanline: row = %d, error = %s", y, err)
+        }
+
+        out := img.pix[y*width*3 : (y+1)*width*3]
+        for x := 0; x < width; x++ {
+            rgbe := scanline[x*4 : x*4+4]
+
+            if rgbe[3] == 0 {
+                out[x*3], out[x*3+1], out[x*3+2] = 0, 0, 0
+                continue
+            }
+
+            scale := float32(math.Ldexp(1, int(rgbe[3])-(128+8)))
+            out[x*3] = float32(rgbe[0]) * scale
+            out[x*3+1] = float32(rgbe[1]) * scale
+            out[x*3+2] = float32(rgbe[2]) * scale
+        }
+    }
+
+    return img, nil
+}
+
+// reads one scanline of RGBE pixels into dst (interleaved, 4 bytes per pixel)
+func readScanline(br *bufio.Reader, dst []byte, width int) error {
+    head, err := br.Peek(4)
+    if err != nil {
+        return err
+    }
+
+    // anything other than the new rle marker is a flat scanline
+    rle := width >= 8 && width < 0x8000 && head[0] == 2 && head[1] == 2 && head[2]&0x80 == 0
+    if !rle {
+        _, err := io.ReadFull(br, dst)
+        return err
+    }
+
+    if _, err := br.Discard(4); err != nil {
+        return err
+    }
+
+    if int(head[2])<<8|int(head[3]) != width {
+        return errors.New("scanline width mismatch")
+    }
+
+    // each of the four channels is run length encoded separately
+    for c := 0; c < 4; c++ {
+        for x := 0; x < width; {
+            count, err := br.ReadByte()
+            if err != nil {
+                return err
+            }
+
+            if count > 128 {
+                run := int(count - 128)
+                if x+run > width {
+                    return errors.New("run overflows scanline")
+                }
+
+                value, err := br.ReadByte()
+                if err != nil {
+                    return err
+                }
+
+                for ; run > 0; run-- {
+                    dst[x*4+c] = value
+                    x++
+                }
+                continue
+            }
+
+            n := int(count)
+            if n == 0 || x+n > width {
+                return errors.New("invalid literal run")
+            }
+
+            for ; n > 0; n-- {
+                value, err := br.ReadByte()
+                if err != nil {
+                    return err
+                }
+
+                dst[x*4+c] = value
+                x++
+            }
+        }
+    }
+
+    return nil
+}
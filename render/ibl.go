@@ -0,0 +1,744 @@
+package render
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/sha1"
+    "encoding/binary"
+    "encoding/hex"
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "io"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "unsafe"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Image Based Lighting
+// --------------------------------------------------------------------------------------------------------
+
+// the sizes of the maps making up an environment
+type EnvironmentOpts struct {
+    // the edge length of the environment cube map projected from a panorama
+    CubeSize int32
+
+    // the edge length of the diffuse irradiance cube map - irradiance varies slowly so it can be tiny
+    IrradianceSize int32
+
+    // the edge length of the top level of the prefiltered specular cube map, each mip level holding a rougher
+    // reflection
+    PrefilterSize int32
+
+    // the edge length of the BRDF lookup texture
+    BRDFSize int32
+}
+
+// the sizes commonly used for real time image based lighting
+func DefaultEnvironmentOpts() EnvironmentOpts {
+    return EnvironmentOpts{CubeSize: 512, IrradianceSize: 32, PrefilterSize: 128, BRDFSize: 512}
+}
+
+// Environment holds the maps used to light PBR surfaces with an environment - the environment itself (for drawing as
+// a skybox), the diffuse irradiance, the specular reflections prefiltered by roughness and the split sum BRDF
+// lookup texture.
+type Environment struct {
+    Cube        *CubeMap
+    Irradiance  *CubeMap
+    Prefiltered *CubeMap
+
+    // scale (red) and bias (green) applied to F0 by the split sum approximation, by n.v across and roughness up
+    BRDF *Texture
+
+    // the number of mip levels of Prefiltered, the last holding roughness 1
+    PrefilterLevels int32
+}
+
+// Precomputes the lighting maps of an environment cube map on the GPU. The environment takes ownership of the cube
+// map, whose mipmaps are generated to smooth out the prefiltering, and deletes it with the rest.
+func NewEnvironment(cube *CubeMap, opts EnvironmentOpts) (*Environment, error) {
+    if opts.IrradianceSize < 1 || opts.PrefilterSize < 1 || opts.BRDFSize < 1 {
+        return nil, fmt.Errorf("invalid environment sizes: irradiance = %d, prefilter = %d, brdf = %d",
+            opts.IrradianceSize, opts.PrefilterSize, opts.BRDFSize)
+    }
+
+    gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+
+    gl.BindTexture(gl.TEXTURE_CUBE_MAP, cube.ptr)
+    gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
+    gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP)
+
+    env := &Environment{Cube: cube, PrefilterLevels: prefilterLevels(opts.PrefilterSize)}
+
+    var err error
+    if env.Irradiance, err = convolveIrradiance(cube, opts.IrradianceSize); err != nil {
+        env.Delete()
+        return nil, err
+    }
+
+    if env.Prefiltered, err = prefilterSpecular(cube, opts.PrefilterSize, env.PrefilterLevels); err != nil {
+        env.Delete()
+        return nil, err
+    }
+
+    if env.BRDF, err = integrateBRDF(opts.BRDFSize); err != nil {
+        env.Delete()
+        return nil, err
+    }
+
+    return env, nil
+}
+
+// Reads an equirectangular panorama (typically a Radiance .hdr file) and precomputes its lighting maps. When cacheDir
+// is not empty the maps are loaded from a cache file there if one exists for the same panorama and sizes, and
+// written there otherwise, so only the first run pays for the precomputation.
+func ReadEnvironment(path string, cacheDir string, opts EnvironmentOpts) (*Environment, error) {
+    var cachePath string
+
+    if cacheDir != "" {
+        key, err := environmentCacheKey(path, opts)
+        if err != nil {
+            return nil, err
+        }
+
+        cachePath = filepath.Join(cacheDir, key+".ibl")
+
+        if env, err := LoadEnvironment(cachePath); err == nil {
+            return env, nil
+        } else if !os.IsNotExist(err) {
+            fmt.Fprintf(os.Stderr, "render: ignoring unreadable environment cache: %v\n", err)
+        }
+    }
+
+    cube, err := ReadEquirectCubeMap(path, opts.CubeSize, TextureOpts{
+        WrapS:     ClampToEdge,
+        WrapT:     ClampToEdge,
+        MinFilter: LinearMipmapLinear,
+        MagFilter: Linear,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    env, err := NewEnvironment(cube, opts)
+    if err != nil {
+        cube.Delete()
+        return nil, err
+    }
+
+    if cachePath != "" {
+        if err := os.MkdirAll(cacheDir, 0755); err != nil {
+            env.Delete()
+            return nil, err
+        }
+
+        if err := env.Save(cachePath); err != nil {
+            env.Delete()
+            return nil, err
+        }
+    }
+
+    return env, nil
+}
+
+// a name for the cache file of a panorama, changing with its contents or the sizes
+func environmentCacheKey(path string, opts EnvironmentOpts) (string, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return "", err
+    }
+    defer f.Close()
+
+    h := sha1.New()
+    if _, err := io.Copy(h, f); err != nil {
+        return "", err
+    }
+
+    fmt.Fprintf(h, "%d %d %d %d", opts.CubeSize, opts.IrradianceSize, opts.PrefilterSize, opts.BRDFSize)
+
+    return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// binds the irradiance, prefiltered and BRDF maps to the given texture units
+func (e *Environment) Bind(irradiance, prefiltered, brdf TextureUnit) {
+    e.Irradiance.Bind(irradiance)
+    e.Prefiltered.Bind(prefiltered)
+    e.BRDF.Bind(brdf)
+}
+
+// deletes every map of the environment
+func (e *Environment) Delete() {
+    for _, cube := range []*CubeMap{e.Cube, e.Irradiance, e.Prefiltered} {
+        if cube != nil {
+            cube.Delete()
+        }
+    }
+
+    if e.BRDF != nil {
+        e.BRDF.Delete()
+    }
+}
+
+// identifies environment cache files and their version
+var environmentMagic = [8]byte{'L', 'O', 'G', 'L', 'I', 'B', 'L', '1'}
+
+// the sizes and mip levels of each map in an environment cache file
+type environmentHeader struct {
+    Magic           [8]byte
+    CubeSize        int32
+    IrradianceSize  int32
+    PrefilterSize   int32
+    PrefilterLevels int32
+    BRDFSize        int32
+}
+
+// the largest map an environment cache may hold, well above what any gpu allows for a cube map
+const maxEnvironmentSize = 1 << 14
+
+// checks the sizes and levels are ones Save could have written, so they can be allocated and read safely
+func (h *environmentHeader) validate() error {
+    for _, size := range []int32{h.CubeSize, h.IrradianceSize, h.PrefilterSize, h.BRDFSize} {
+        if size < 1 || size > maxEnvironmentSize {
+            return fmt.Errorf("invalid map size: size = %d", size)
+        }
+    }
+
+    if h.PrefilterLevels < 1 || h.PrefilterLevels > mipLevels(h.PrefilterSize) {
+        return fmt.Errorf("invalid prefilter levels: levels = %d, size = %d", h.PrefilterLevels, h.PrefilterSize)
+    }
+
+    return nil
+}
+
+// Writes the maps to a cache file read back by LoadEnvironment. The contents are raw little endian floats - the file
+// is only meant for the machine that wrote it.
+func (e *Environment) Save(path string) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+
+    w := bufio.NewWriter(f)
+
+    header := environmentHeader{
+        Magic:           environmentMagic,
+        CubeSize:        e.Cube.size,
+        IrradianceSize:  e.Irradiance.size,
+        PrefilterSize:   e.Prefiltered.size,
+        PrefilterLevels: e.PrefilterLevels,
+        BRDFSize:        e.BRDF.width,
+    }
+
+    err = binary.Write(w, binary.LittleEndian, header)
+
+    // the environment is saved at its top level only, its mipmaps are regenerated on load
+    for _, c := range []struct {
+        cube   *CubeMap
+        levels int32
+    }{{e.Cube, 1}, {e.Irradiance, 1}, {e.Prefiltered, e.PrefilterLevels}} {
+        for level := int32(0); level < c.levels && err == nil; level++ {
+            err = writeCubeLevel(w, c.cube, level)
+        }
+    }
+
+    if err == nil {
+        pix := make([]float32, header.BRDFSize*header.BRDFSize*2)
+        gl.BindTexture(gl.TEXTURE_2D, e.BRDF.ptr)
+        gl.GetTexImage(gl.TEXTURE_2D, 0, gl.RG, gl.FLOAT, gl.Ptr(pix))
+        err = binary.Write(w, binary.LittleEndian, pix)
+    }
+
+    if err == nil {
+        err = w.Flush()
+    }
+
+    if closeErr := f.Close(); err == nil {
+        err = closeErr
+    }
+
+    if err != nil {
+        os.Remove(path)
+    }
+
+    return err
+}
+
+// Reads an environment written by Save
+func LoadEnvironment(path string) (*Environment, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    var header environmentHeader
+    headerSize := binary.Size(header)
+
+    if len(data) < headerSize {
+        return nil, fmt.Errorf("environment cache truncated: path = %s", path)
+    }
+
+    if err := binary.Read(bytes.NewReader(data[:headerSize]), binary.LittleEndian, &header); err != nil {
+        return nil, err
+    }
+
+    if header.Magic != environmentMagic {
+        return nil, fmt.Errorf("not an environment cache: path = %s", path)
+    }
+
+    if err := header.validate(); err != nil {
+        return nil, fmt.Errorf("corrupt environment cache: path = %s, error = %v", path, err)
+    }
+
+    // the floats following the header, in the order written
+    floats := 0
+    for _, c := range []struct{ size, levels int32 }{
+        {header.CubeSize, 1}, {header.IrradianceSize, 1}, {header.PrefilterSize, header.PrefilterLevels},
+    } {
+        for level := int32(0); level < c.levels; level++ {
+            edge := int(mipSize(c.size, level))
+            floats += 6 * edge * edge * 3
+        }
+    }
+    floats += int(header.BRDFSize) * int(header.BRDFSize) * 2
+
+    if len(data) != headerSize+floats*4 {
+        return nil, fmt.Errorf("environment cache has the wrong size: path = %s, size = %d, expected = %d",
+            path, len(data), headerSize+floats*4)
+    }
+
+    pix := make([]float32, floats)
+    if err := binary.Read(bytes.NewReader(data[headerSize:]), binary.LittleEndian, pix); err != nil {
+        return nil, err
+    }
+
+    gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+
+    env := &Environment{PrefilterLevels: header.PrefilterLevels}
+
+    env.Cube = newEmptyCubeMap(header.CubeSize, gl.RGB16F, environmentCubeOpts)
+    pix = readCubeLevel(env.Cube, 0, pix)
+    gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP)
+
+    env.Irradiance = newEmptyCubeMap(header.IrradianceSize, gl.RGB16F, irradianceOpts)
+    pix = readCubeLevel(env.Irradiance, 0, pix)
+
+    env.Prefiltered = newEmptyCubeMap(header.PrefilterSize, gl.RGB16F, environmentCubeOpts)
+    gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MAX_LEVEL, header.PrefilterLevels-1)
+    for level := int32(0); level < header.PrefilterLevels; level++ {
+        pix = readCubeLevel(env.Prefiltered, level, pix)
+    }
+
+    env.BRDF = newBRDFTexture(header.BRDFSize, pix)
+
+    return env, nil
+}
+
+// the texture options of the environment and prefiltered cube maps
+var environmentCubeOpts = TextureOpts{
+    WrapS:     ClampToEdge,
+    WrapT:     ClampToEdge,
+    MinFilter: LinearMipmapLinear,
+    MagFilter: Linear,
+}
+
+// the texture options of the irradiance cube map
+var irradianceOpts = TextureOpts{
+    WrapS:     ClampToEdge,
+    WrapT:     ClampToEdge,
+    MinFilter: Linear,
+    MagFilter: Linear,
+}
+
+// the number of mip levels down to 1x1 for the given size
+func mipLevels(size int32) int32 {
+    levels := int32(1)
+    for size > 1 {
+        size >>= 1
+        levels++
+    }
+    return levels
+}
+
+// the number of roughness levels prefiltered - rougher reflections need fewer texels, but not so few that the
+// smallest level shows its texels
+func prefilterLevels(size int32) int32 {
+    if levels := mipLevels(size); levels < 5 {
+        return levels
+    }
+    return 5
+}
+
+// the edge length of the given mip level
+func mipSize(size, level int32) int32 {
+    size >>= uint(level)
+    if size < 1 {
+        size = 1
+    }
+    return size
+}
+
+// writes the 6 faces of one level of a cube map as RGB floats
+func writeCubeLevel(w io.Writer, cube *CubeMap, level int32) error {
+    edge := mipSize(cube.size, level)
+    pix := make([]float32, edge*edge*3)
+
+    gl.BindTexture(gl.TEXTURE_CUBE_MAP, cube.ptr)
+    for face := uint32(0); face < 6; face++ {
+        gl.GetTexImage(gl.TEXTURE_CUBE_MAP_POSITIVE_X+face, level, gl.RGB, gl.FLOAT, gl.Ptr(pix))
+        if err := binary.Write(w, binary.LittleEndian, pix); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// uploads the 6 faces of one level of a cube map from the front of pix, returning the rest
+func readCubeLevel(cube *CubeMap, level int32, pix []float32) []float32 {
+    edge := mipSize(cube.size, level)
+    count := int(edge * edge * 3)
+
+    gl.BindTexture(gl.TEXTURE_CUBE_MAP, cube.ptr)
+    for face := uint32(0); face < 6; face++ {
+        gl.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+face, level, gl.RGB16F, edge, edge, 0, gl.RGB, gl.FLOAT,
+            gl.Ptr(pix[:count]))
+        pix = pix[count:]
+    }
+
+    return pix
+}
+
+// allocates the RG16F BRDF lookup texture, with the given contents or uninitialised for nil
+func newBRDFTexture(size int32, pix []float32) *Texture {
+    var texture uint32
+    gl.GenTextures(1, &texture)
+    gl.BindTexture(gl.TEXTURE_2D, texture)
+
+    applyTextureOpts(gl.TEXTURE_2D, TextureOpts{
+        WrapS:     ClampToEdge,
+        WrapT:     ClampToEdge,
+        MinFilter: Linear,
+        MagFilter: Linear,
+    })
+
+    var data unsafe.Pointer
+    if pix != nil {
+        data = gl.Ptr(pix)
+    }
+
+    gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RG16F, size, size, 0, gl.RG, gl.FLOAT, data)
+    glCheck("creating the BRDF lookup texture")
+
+    t := &Texture{ptr: texture, width: size, height: size}
+    t.res = track(t, textureResource, texture)
+
+    return t
+}
+
+// convolves the environment over the hemisphere around each direction, giving the diffuse light arriving there
+func convolveIrradiance(cube *CubeMap, size int32) (*CubeMap, error) {
+    prog, err := compileProgram(cubeCaptureVertexSource, irradianceFragmentSource)
+    if err != nil {
+        return nil, err
+    }
+    defer prog.Delete()
+
+    irradiance := newEmptyCubeMap(size, gl.RGB16F, irradianceOpts)
+
+    prog.Use()
+    if err := prog.Integer("environmentMap", 0); err != nil {
+        irradiance.Delete()
+        return nil, err
+    }
+
+    cube.Bind(TextureUnit0)
+
+    if err := renderCubeFaces(irradiance, 0, prog); err != nil {
+        irradiance.Delete()
+        return nil, err
+    }
+
+    return irradiance, nil
+}
+
+// importance samples the GGX distribution around each direction, one mip level per roughness step
+func prefilterSpecular(cube *CubeMap, size, levels int32) (*CubeMap, error) {
+    prog, err := compileProgram(cubeCaptureVertexSource, prefilterFragmentSource)
+    if err != nil {
+        return nil, err
+    }
+    defer prog.Delete()
+
+    prefiltered := newEmptyCubeMap(size, gl.RGB16F, environmentCubeOpts)
+    gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MAX_LEVEL, levels-1)
+    gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP)
+
+    prog.Use()
+
+    err = prog.Integer("environmentMap", 0)
+    if err == nil {
+        err = prog.Float("resolution", float32(cube.size))
+    }
+
+    cube.Bind(TextureUnit0)
+
+    for level := int32(0); level < levels && err == nil; level++ {
+        roughness := float32(level) / float32(levels-1)
+        if levels == 1 {
+            roughness = 0
+        }
+
+        if err = prog.Float("roughness", roughness); err == nil {
+            err = renderCubeFaces(prefiltered, level, prog)
+        }
+    }
+
+    if err != nil {
+        prefiltered.Delete()
+        return nil, err
+    }
+
+    return prefiltered, nil
+}
+
+// renders the split sum BRDF lookup texture with a full screen triangle
+func integrateBRDF(size int32) (*Texture, error) {
+    prog, err := compileProgram(fullscreenVertexSource, brdfFragmentSource)
+    if err != nil {
+        return nil, err
+    }
+    defer prog.Delete()
+
+    lut := newBRDFTexture(size, nil)
+
+    restore := saveRenderTarget()
+    defer restore()
+
+    fbo := NewFramebuffer()
+    defer fbo.Delete()
+
+    fbo.Bind()
+    gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, lut.ptr, 0)
+
+    if err := fbo.Check(); err != nil {
+        lut.Delete()
+        return nil, err
+    }
+
+    gl.Viewport(0, 0, size, size)
+    gl.Clear(gl.COLOR_BUFFER_BIT)
+
+    prog.Use()
+    drawFullscreenTriangle()
+
+    return lut, nil
+}
+
+// a vertex array with no attributes, drawn for full screen passes that generate positions from gl_VertexID. Created
+// on first use and deleted with the window.
+var emptyVertexArray *VertexArray
+
+// draws a single triangle covering the viewport with the program in use, which should take its vertex shader from
+// fullscreenVertexSource
+func drawFullscreenTriangle() {
+    if emptyVertexArray == nil {
+        emptyVertexArray = NewVertexArray()
+
+        OnDestroy(func() {
+            emptyVertexArray.Delete()
+            emptyVertexArray = nil
+        })
+    }
+
+    emptyVertexArray.Bind()
+    gl.DrawArrays(gl.TRIANGLES, 0, 3)
+    gl.BindVertexArray(0)
+
+    glCheck("drawing a fullscreen triangle")
+}
+
+// a triangle covering the viewport, with texture coordinates 0 to 1 across it
+const fullscreenVertexSource = `#version 330 core
+out vec2 texCoords;
+
+void main()
+{
+    vec2 corner = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+    texCoords = corner;
+    gl_Position = vec4(corner * 2.0 - 1.0, 0.0, 1.0);
+}
+` + "\x00"
+
+const irradianceFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec3 localPos;
+
+uniform samplerCube environmentMap;
+
+const float PI = 3.14159265359;
+
+void main()
+{
+    vec3 normal = normalize(localPos);
+    vec3 up = abs(normal.y) < 0.999 ? vec3(0.0, 1.0, 0.0) : vec3(1.0, 0.0, 0.0);
+    vec3 right = normalize(cross(up, normal));
+    up = cross(normal, right);
+
+    // riemann sum over the hemisphere, weighted by cos(theta) for the incoming angle and sin(theta) for the smaller
+    // area of rings near the pole
+    vec3 irradiance = vec3(0.0);
+    float samples = 0.0;
+    float delta = 0.025;
+
+    for (float phi = 0.0; phi < 2.0 * PI; phi += delta) {
+        for (float theta = 0.0; theta < 0.5 * PI; theta += delta) {
+            vec3 tangent = vec3(sin(theta) * cos(phi), sin(theta) * sin(phi), cos(theta));
+            vec3 direction = tangent.x * right + tangent.y * up + tangent.z * normal;
+
+            irradiance += texture(environmentMap, direction).rgb * cos(theta) * sin(theta);
+            samples++;
+        }
+    }
+
+    FragColor = vec4(PI * irradiance / samples, 1.0);
+}
+` + "\x00"
+
+// GGX importance sampling shared by the prefilter and BRDF shaders
+const importanceSampleGLSL = `
+const float PI = 3.14159265359;
+
+// the Hammersley point i of n, a low discrepancy sequence spreading samples evenly
+vec2 hammersley(uint i, uint n)
+{
+    uint bits = i;
+    bits = (bits << 16u) | (bits >> 16u);
+    bits = ((bits & 0x55555555u) << 1u) | ((bits & 0xAAAAAAAAu) >> 1u);
+    bits = ((bits & 0x33333333u) << 2u) | ((bits & 0xCCCCCCCCu) >> 2u);
+    bits = ((bits & 0x0F0F0F0Fu) << 4u) | ((bits & 0xF0F0F0F0u) >> 4u);
+    bits = ((bits & 0x00FF00FFu) << 8u) | ((bits & 0xFF00FF00u) >> 8u);
+
+    return vec2(float(i) / float(n), float(bits) * 2.3283064365386963e-10);
+}
+
+// a half vector around n distributed by the GGX lobe of the given roughness
+vec3 importanceSampleGGX(vec2 xi, vec3 n, float roughness)
+{
+    float a = roughness * roughness;
+
+    float phi = 2.0 * PI * xi.x;
+    float cosTheta = sqrt((1.0 - xi.y) / (1.0 + (a * a - 1.0) * xi.y));
+    float sinTheta = sqrt(1.0 - cosTheta * cosTheta);
+
+    vec3 h = vec3(cos(phi) * sinTheta, sin(phi) * sinTheta, cosTheta);
+
+    vec3 up = abs(n.z) < 0.999 ? vec3(0.0, 0.0, 1.0) : vec3(1.0, 0.0, 0.0);
+    vec3 tangent = normalize(cross(up, n));
+    vec3 bitangent = cross(n, tangent);
+
+    return normalize(tangent * h.x + bitangent * h.y + n * h.z);
+}
+`
+
+const prefilterFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec3 localPos;
+
+uniform samplerCube environmentMap;
+uniform float roughness;
+
+// the edge length of the environment map faces
+uniform float resolution;
+` + importanceSampleGLSL + `
+float distributionGGX(float nDotH, float roughness)
+{
+    float a = roughness * roughness;
+    float a2 = a * a;
+    float d = nDotH * nDotH * (a2 - 1.0) + 1.0;
+
+    return a2 / (PI * d * d);
+}
+
+void main()
+{
+    // the view and reflection directions are assumed to match the normal, which loses the stretched reflections at
+    // grazing angles but lets one map serve every view
+    vec3 n = normalize(localPos);
+    vec3 v = n;
+
+    const uint sampleCount = 1024u;
+    vec3 color = vec3(0.0);
+    float weight = 0.0;
+
+    for (uint i = 0u; i < sampleCount; i++) {
+        vec3 h = importanceSampleGGX(hammersley(i, sampleCount), n, roughness);
+        vec3 l = normalize(2.0 * dot(v, h) * h - v);
+
+        float nDotL = max(dot(n, l), 0.0);
+        if (nDotL > 0.0) {
+            // sample a blurrier mip level where samples are sparse, avoiding bright dots from undersampling
+            float nDotH = max(dot(n, h), 0.0);
+            float hDotV = max(dot(h, v), 0.0);
+            float pdf = distributionGGX(nDotH, roughness) * nDotH / (4.0 * hDotV) + 0.0001;
+
+            float saTexel = 4.0 * PI / (6.0 * resolution * resolution);
+            float saSample = 1.0 / (float(sampleCount) * pdf + 0.0001);
+            float level = roughness == 0.0 ? 0.0 : 0.5 * log2(saSample / saTexel);
+
+            color += textureLod(environmentMap, l, level).rgb * nDotL;
+            weight += nDotL;
+        }
+    }
+
+    FragColor = vec4(color / weight, 1.0);
+}
+` + "\x00"
+
+const brdfFragmentSource = `#version 330 core
+out vec2 FragColor;
+
+in vec2 texCoords;
+` + importanceSampleGLSL + `
+float geometrySchlickGGX(float nDotV, float roughness)
+{
+    // image based lighting uses k = a / 2 rather than the (a + 1)^2 / 8 of direct lights
+    float k = (roughness * roughness) / 2.0;
+    return nDotV / (nDotV * (1.0 - k) + k);
+}
+
+void main()
+{
+    float nDotV = max(texCoords.x, 1e-4);
+    float roughness = texCoords.y;
+
+    vec3 v = vec3(sqrt(1.0 - nDotV * nDotV), 0.0, nDotV);
+    vec3 n = vec3(0.0, 0.0, 1.0);
+
+    float scale = 0.0;
+    float bias = 0.0;
+
+    const uint sampleCount = 1024u;
+    for (uint i = 0u; i < sampleCount; i++) {
+        vec3 h = importanceSampleGGX(hammersley(i, sampleCount), n, roughness);
+        vec3 l = normalize(2.0 * dot(v, h) * h - v);
+
+        float nDotL = max(l.z, 0.0);
+        float nDotH = max(h.z, 0.0);
+        float vDotH = max(dot(v, h), 0.0);
+
+        if (nDotL > 0.0) {
+            float g = geometrySchlickGGX(nDotV, roughness) * geometrySchlickGGX(nDotL, roughness);
+            float gVis = g * vDotH / (nDotH * nDotV);
+            float fc = pow(1.0 - vDotH, 5.0);
+
+            scale += (1.0 - fc) * gVis;
+            bias += fc * gVis;
+        }
+    }
+
+    FragColor = vec2(scale, bias) / float(sampleCount);
+}
+` + "\x00"
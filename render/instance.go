@@ -0,0 +1,194 @@
+package render
+
+import (
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Instancing
+// --------------------------------------------------------------------------------------------------------
+
+// the attribute locations of the per instance data, after the vertex attributes of Mesh. A mat4 attribute takes a
+// location for each of its columns, so the model matrix uses 8 to 11.
+const (
+    InstanceModelLocation = 8
+    InstanceColorLocation = 12
+)
+
+// model matrix and colour
+const instanceFloats = 16 + 4
+
+// the attributes of InstanceBuffer, for vertex shaders drawing instanced meshes
+const InstanceGLSL = `
+layout (location = 8) in mat4 aInstanceModel;
+layout (location = 12) in vec4 aInstanceColor;
+`
+
+// the data of one instance drawn by Mesh.DrawInstanced
+type Instance struct {
+    Model Mat4
+    Color Color
+}
+
+// InstanceBuffer holds per instance model matrices and colours, read once per instance rather than once per vertex.
+// The instances are kept in memory and changes are uploaded when the buffer is next drawn - only the instances
+// changed since, and reusing the buffer storage until more instances are added than it has room for.
+type InstanceBuffer struct {
+    vbo      *Buffer
+    data     []float32
+    capacity int
+
+    // the range of instances changed since the last upload, empty when lo >= hi
+    lo, hi int
+}
+
+// Creates a new instance buffer with room for the given number of instances before it needs to grow
+func NewInstanceBuffer(capacity int) *InstanceBuffer {
+    if capacity < 1 {
+        capacity = 1
+    }
+
+    b := &InstanceBuffer{vbo: NewBuffer(ArrayBuffer), capacity: capacity}
+    b.vbo.Data(capacity*instanceFloats*4, nil, DynamicDraw)
+
+    return b
+}
+
+// the number of instances
+func (b *InstanceBuffer) Len() int {
+    return len(b.data) / instanceFloats
+}
+
+// adds an instance, returning its index
+func (b *InstanceBuffer) Append(instance Instance) int {
+    b.data = append(b.data, make([]float32, instanceFloats)...)
+
+    i := b.Len() - 1
+    b.Set(i, instance)
+
+    return i
+}
+
+// replaces the instance at the given index
+func (b *InstanceBuffer) Set(i int, instance Instance) {
+    b.write(i, instance.Model[:], 0)
+    c := instance.Color
+    b.write(i, []float32{c.r, c.g, c.b, c.a}, 16)
+}
+
+// replaces the model matrix of the instance at the given index
+func (b *InstanceBuffer) SetModel(i int, model Mat4) {
+    b.write(i, model[:], 0)
+}
+
+// replaces the colour of the instance at the given index
+func (b *InstanceBuffer) SetColor(i int, c Color) {
+    b.write(i, []float32{c.r, c.g, c.b, c.a}, 16)
+}
+
+// copies floats into an instance at the given offset, marking it changed. Panics if there is no such instance,
+// which would otherwise write into a neighbouring instance or the spare capacity of the slice.
+func (b *InstanceBuffer) write(i int, values []float32, offset int) {
+    if i < 0 || i >= b.Len() {
+        panic(fmt.Sprintf("instance index out of range: index = %d, instances = %d", i, b.Len()))
+    }
+
+    copy(b.data[i*instanceFloats+offset:], values)
+
+    if b.lo >= b.hi {
+        b.lo, b.hi = i, i+1
+        return
+    }
+    if i < b.lo {
+        b.lo = i
+    }
+    if i >= b.hi {
+        b.hi = i + 1
+    }
+}
+
+// removes every instance from the given index onwards
+func (b *InstanceBuffer) Truncate(n int) {
+    if n < b.Len() {
+        b.data = b.data[:n*instanceFloats]
+    }
+    if b.hi > n {
+        b.hi = n
+    }
+}
+
+// removes every instance, keeping the buffer storage for the next frame
+func (b *InstanceBuffer) Clear() {
+    b.Truncate(0)
+}
+
+// uploads the changed instances
+func (b *InstanceBuffer) upload() {
+    if b.lo >= b.hi {
+        return
+    }
+
+    if n := b.Len(); n > b.capacity {
+        // grow to the next power of two so instances added a few at a time don't reallocate every frame
+        for b.capacity < n {
+            b.capacity *= 2
+        }
+
+        b.vbo.Data(b.capacity*instanceFloats*4, nil, DynamicDraw)
+        b.vbo.SubData(0, len(b.data)*4, b.data)
+    } else if b.lo == 0 && b.hi == n {
+        // every instance changed, orphan the previous contents so the driver needn't wait for draws still reading them
+        b.vbo.Data(b.capacity*instanceFloats*4, nil, DynamicDraw)
+        b.vbo.SubData(0, len(b.data)*4, b.data)
+    } else {
+        b.vbo.SubData(b.lo*instanceFloats*4, (b.hi-b.lo)*instanceFloats*4, b.data[b.lo*instanceFloats:])
+    }
+
+    b.lo, b.hi = 0, 0
+}
+
+// points the instance attributes of the bound vertex array at the buffer, advancing once per instance
+func (b *InstanceBuffer) attach() {
+    b.vbo.Bind()
+
+    stride := int32(instanceFloats * 4)
+    for col := uint32(0); col < 4; col++ {
+        location := InstanceModelLocation + col
+        gl.VertexAttribPointer(location, 4, gl.FLOAT, false, stride, gl.PtrOffset(int(col)*4*4))
+        gl.EnableVertexAttribArray(location)
+        gl.VertexAttribDivisor(location, 1)
+    }
+
+    gl.VertexAttribPointer(InstanceColorLocation, 4, gl.FLOAT, false, stride, gl.PtrOffset(16*4))
+    gl.EnableVertexAttribArray(InstanceColorLocation)
+    gl.VertexAttribDivisor(InstanceColorLocation, 1)
+}
+
+// deletes the underlying buffer
+func (b *InstanceBuffer) Delete() {
+    b.vbo.Delete()
+}
+
+// Draws a copy of the mesh for every instance in one draw call, with the program currently in use. The instance data
+// is read by the vertex shader from the attributes in InstanceGLSL.
+func (m *Mesh) DrawInstanced(instances *InstanceBuffer) {
+    if instances.Len() == 0 {
+        return
+    }
+
+    instances.upload()
+
+    m.vao.Bind()
+
+    // a vertex array reads its instance attributes from one buffer at a time
+    if m.instances != instances {
+        instances.attach()
+        m.instances = instances
+    }
+
+    gl.DrawElementsInstanced(gl.TRIANGLES, m.count, m.index, nil, int32(instances.Len()))
+    gl.BindVertexArray(0)
+
+    glCheck("drawing mesh instances")
+}
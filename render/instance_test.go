@@ -0,0 +1,101 @@
+package render
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "testing"
+)
+
+// the range of instances waiting to be uploaded, 0, 0 when there are none
+func dirtyRange(b *InstanceBuffer) (int, int) {
+    if b.lo >= b.hi {
+        return 0, 0
+    }
+    return b.lo, b.hi
+}
+
+func TestInstanceDirtyRange(t *testing.T) {
+    // no vbo, so the buffer must not be uploaded
+    b := &InstanceBuffer{}
+
+    steps := []struct {
+        name   string
+        change func()
+        lo, hi int
+        count  int
+    }{
+        {"append", func() {
+            for i := 0; i < 8; i++ {
+                b.Append(Instance{Model: mgl32.Ident4(), Color: White})
+            }
+        }, 0, 8, 8},
+        {"uploaded", func() { b.lo, b.hi = 0, 0 }, 0, 0, 8},
+        {"set", func() { b.Set(3, Instance{Color: Red}) }, 3, 4, 8},
+        {"set below", func() { b.SetColor(1, Blue) }, 1, 4, 8},
+        {"set above", func() { b.SetModel(5, mgl32.Ident4()) }, 1, 6, 8},
+        {"set inside", func() { b.SetColor(2, Green) }, 1, 6, 8},
+        {"truncate within the range", func() { b.Truncate(4) }, 1, 4, 4},
+        {"truncate past the end", func() { b.Truncate(10) }, 1, 4, 4},
+        {"truncate below the range", func() { b.Truncate(1) }, 0, 0, 1},
+        {"append after truncating", func() { b.Append(Instance{}) }, 1, 2, 2},
+        {"clear", func() { b.Clear() }, 0, 0, 0},
+        {"append after clearing", func() { b.Append(Instance{}) }, 0, 1, 1},
+    }
+
+    for _, step := range steps {
+        step.change()
+
+        if lo, hi := dirtyRange(b); lo != step.lo || hi != step.hi {
+            t.Errorf("%s: changed range %d-%d, expected %d-%d", step.name, lo, hi, step.lo, step.hi)
+        }
+
+        if b.Len() != step.count {
+            t.Errorf("%s: %d instances, expected %d", step.name, b.Len(), step.count)
+        }
+    }
+}
+
+func TestInstanceData(t *testing.T) {
+    b := &InstanceBuffer{}
+    b.Append(Instance{Model: mgl32.Translate3D(1, 2, 3), Color: RGBA(0.1, 0.2, 0.3, 0.4)})
+    b.Append(Instance{})
+    b.SetColor(1, Red)
+
+    first := b.data[:instanceFloats]
+    if first[12] != 1 || first[13] != 2 || first[14] != 3 {
+        t.Errorf("translation stored as %v", first[12:15])
+    }
+
+    if c := first[16:]; c[0] != 0.1 || c[1] != 0.2 || c[2] != 0.3 || c[3] != 0.4 {
+        t.Errorf("colour stored as %v", c)
+    }
+
+    if c := b.data[instanceFloats+16:]; c[0] != 1 || c[1] != 0 || c[2] != 0 || c[3] != 1 {
+        t.Errorf("second colour stored as %v", c)
+    }
+}
+
+func TestInstanceIndexOutOfRange(t *testing.T) {
+    b := &InstanceBuffer{}
+    b.Append(Instance{})
+    b.Append(Instance{})
+
+    // the slice keeps the truncated instance as spare capacity, which an unchecked write would reach
+    b.Truncate(1)
+    b.lo, b.hi = 0, 0
+
+    for _, i := range []int{-1, 1, 2} {
+        func() {
+            defer func() {
+                if recover() == nil {
+                    t.Errorf("index %d: no panic", i)
+                }
+            }()
+
+            b.SetColor(i, Red)
+        }()
+    }
+
+    if lo, hi := dirtyRange(b); lo != 0 || hi != 0 {
+        t.Errorf("rejected writes changed the range to %d-%d", lo, hi)
+    }
+}
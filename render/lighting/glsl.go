@@ -0,0 +1,146 @@
+package lighting
+
+// Fragment shader declarations for Phong and Blinn-Phong lighting, to paste after the #version line. It declares
+// the light arrays, material and viewer position uploaded by Lights.Upload and Material.Bind, and blinnPhong() sums
+// every light's contribution for a fragment given its world space position, unit normal and texture coordinate.
+const BlinnPhongGLSL = `
+#define MAX_DIRECTIONAL_LIGHTS 4
+#define MAX_POINT_LIGHTS 16
+#define MAX_SPOT_LIGHTS 8
+
+struct DirectionalLight {
+    vec3 direction;
+
+    vec3 ambient;
+    vec3 diffuse;
+    vec3 specular;
+};
+
+struct PointLight {
+    vec3 position;
+
+    float constant;
+    float linear;
+    float quadratic;
+
+    vec3 ambient;
+    vec3 diffuse;
+    vec3 specular;
+};
+
+struct SpotLight {
+    vec3 position;
+    vec3 direction;
+    float innerCutoff;
+    float outerCutoff;
+
+    float constant;
+    float linear;
+    float quadratic;
+
+    vec3 ambient;
+    vec3 diffuse;
+    vec3 specular;
+};
+
+struct Material {
+    vec3 ambient;
+    vec3 diffuse;
+    vec3 specular;
+
+    sampler2D ambientMap;
+    sampler2D diffuseMap;
+    sampler2D specularMap;
+
+    float shininess;
+};
+
+uniform DirectionalLight directionalLights[MAX_DIRECTIONAL_LIGHTS];
+uniform PointLight pointLights[MAX_POINT_LIGHTS];
+uniform SpotLight spotLights[MAX_SPOT_LIGHTS];
+
+uniform int directionalLightCount;
+uniform int pointLightCount;
+uniform int spotLightCount;
+
+// 0 for Blinn-Phong, 1 for Phong
+uniform int lightingModel;
+
+uniform vec3 viewPos;
+uniform Material material;
+
+// the surface colours of the fragment being lit
+struct Surface {
+    vec3 ambient;
+    vec3 diffuse;
+    vec3 specular;
+};
+
+float specularFactor(vec3 lightDir, vec3 normal, vec3 viewDir)
+{
+    if (lightingModel == 1) {
+        vec3 reflectDir = reflect(-lightDir, normal);
+        return pow(max(dot(viewDir, reflectDir), 0.0), material.shininess);
+    }
+
+    vec3 halfway = normalize(lightDir + viewDir);
+    return pow(max(dot(normal, halfway), 0.0), material.shininess);
+}
+
+// the light from a single source, with lightDir pointing from the fragment towards the light
+vec3 shade(vec3 lightDir, vec3 normal, vec3 viewDir, Surface s, vec3 ambient, vec3 diffuse, vec3 specular)
+{
+    float diff = max(dot(normal, lightDir), 0.0);
+    float spec = diff > 0.0 ? specularFactor(lightDir, normal, viewDir) : 0.0;
+
+    return ambient * s.ambient + diffuse * diff * s.diffuse + specular * spec * s.specular;
+}
+
+float attenuate(float constant, float linear, float quadratic, float distance)
+{
+    return 1.0 / (constant + linear * distance + quadratic * distance * distance);
+}
+
+vec3 blinnPhong(vec3 fragPos, vec3 normal, vec2 texCoord)
+{
+    Surface s;
+    s.ambient = material.ambient * texture(material.ambientMap, texCoord).rgb;
+    s.diffuse = material.diffuse * texture(material.diffuseMap, texCoord).rgb;
+    s.specular = material.specular * texture(material.specularMap, texCoord).rgb;
+
+    vec3 viewDir = normalize(viewPos - fragPos);
+    vec3 result = vec3(0.0);
+
+    for (int i = 0; i < directionalLightCount; i++) {
+        DirectionalLight light = directionalLights[i];
+        result += shade(-light.direction, normal, viewDir, s, light.ambient, light.diffuse, light.specular);
+    }
+
+    for (int i = 0; i < pointLightCount; i++) {
+        PointLight light = pointLights[i];
+
+        vec3 toLight = light.position - fragPos;
+        float a = attenuate(light.constant, light.linear, light.quadratic, length(toLight));
+
+        result += a * shade(normalize(toLight), normal, viewDir, s, light.ambient, light.diffuse, light.specular);
+    }
+
+    for (int i = 0; i < spotLightCount; i++) {
+        SpotLight light = spotLights[i];
+
+        vec3 toLight = light.position - fragPos;
+        vec3 lightDir = normalize(toLight);
+        float a = attenuate(light.constant, light.linear, light.quadratic, length(toLight));
+
+        // full strength inside the inner cone, fading out to the edge of the outer
+        float theta = dot(lightDir, normalize(-light.direction));
+        float cone = clamp((theta - light.outerCutoff) / max(light.innerCutoff - light.outerCutoff, 1e-4), 0.0, 1.0);
+
+        // the ambient term lights the surroundings regardless of the cone
+        result += a * (light.ambient * s.ambient +
+            cone * (shade(lightDir, normal, viewDir, s, vec3(0.0), light.diffuse, light.specular)));
+    }
+
+    return result;
+}
+`
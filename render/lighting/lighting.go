@@ -0,0 +1,152 @@
+// Package lighting provides Phong and Blinn-Phong shading for directional, point and spot lights. The lights and
+// materials are uploaded to programs built with the BlinnPhongGLSL include, which evaluates every light in a single
+// pass of the fragment shader.
+package lighting
+
+import (
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "logl/render"
+    "math"
+)
+
+// the sizes of the light arrays declared by BlinnPhongGLSL
+const (
+    MaxDirectionalLights = 4
+    MaxPointLights       = 16
+    MaxSpotLights        = 8
+)
+
+// the specular model used by the shader
+type Model int
+
+const (
+    // specular from the angle between the normal and the half vector between the light and view directions
+    BlinnPhong Model = iota
+
+    // specular from the angle between the reflected light and view directions. Phong needs roughly a quarter of the
+    // shininess of Blinn-Phong for the same size highlight.
+    Phong
+)
+
+// the contribution of a light to each term of the lighting equation, in linear colour
+type Intensity struct {
+    Ambient  render.Color
+    Diffuse  render.Color
+    Specular render.Color
+}
+
+// Returns the intensity for a light of the given colour, with a little ambient and full diffuse and specular
+func NewIntensity(color render.Color) Intensity {
+    return Intensity{Ambient: color.Scale(0.05), Diffuse: color, Specular: color}
+}
+
+// how a light falls off with distance - its intensity is divided by Constant + Linear*d + Quadratic*d*d
+type Attenuation struct {
+    Constant  float32
+    Linear    float32
+    Quadratic float32
+}
+
+// Returns an attenuation that fades a light to almost nothing over the given distance
+func AttenuationRange(distance float32) Attenuation {
+    return Attenuation{Constant: 1, Linear: 4.5 / distance, Quadratic: 75 / (distance * distance)}
+}
+
+// the attenuation factor at distance d
+func (a Attenuation) At(d float32) float32 {
+    return 1 / (a.Constant + a.Linear*d + a.Quadratic*d*d)
+}
+
+// a light infinitely far away shining in a single direction, like the sun
+type Directional struct {
+    // the direction the light travels in world space
+    Direction mgl32.Vec3
+    Intensity
+}
+
+// a light shining in every direction from a point
+type Point struct {
+    Position mgl32.Vec3
+    Attenuation
+    Intensity
+}
+
+// a light shining from a point in a cone, at full strength within the inner cone and fading to nothing at the outer
+type Spot struct {
+    Position mgl32.Vec3
+
+    // the direction of the axis of the cone in world space
+    Direction mgl32.Vec3
+
+    // the half angles of the inner and outer cones in radians
+    InnerCone float32
+    OuterCone float32
+
+    Attenuation
+    Intensity
+}
+
+// Lights is the set of lights uploaded to a program together
+type Lights struct {
+    Model       Model
+    Directional []*Directional
+    Points      []*Point
+    Spots       []*Spot
+}
+
+// Uploads the lights and the position of the viewer in world space to the program, which must be in use. Call it
+// each frame the lights or camera move.
+func (l *Lights) Upload(prog *render.Program, viewPosition mgl32.Vec3) error {
+    if len(l.Directional) > MaxDirectionalLights || len(l.Points) > MaxPointLights || len(l.Spots) > MaxSpotLights {
+        return fmt.Errorf("too many lights: directional = %d, point = %d, spot = %d",
+            len(l.Directional), len(l.Points), len(l.Spots))
+    }
+
+    u := prog.Uniforms()
+
+    u.Vec3("viewPos", viewPosition)
+    u.Integer("lightingModel", int32(l.Model))
+
+    u.Integer("directionalLightCount", int32(len(l.Directional)))
+    for i, light := range l.Directional {
+        name := fmt.Sprintf("directionalLights[%d].", i)
+        u.Vec3(name+"direction", light.Direction.Normalize())
+        uploadIntensity(u, name, light.Intensity)
+    }
+
+    u.Integer("pointLightCount", int32(len(l.Points)))
+    for i, light := range l.Points {
+        name := fmt.Sprintf("pointLights[%d].", i)
+        u.Vec3(name+"position", light.Position)
+        uploadAttenuation(u, name, light.Attenuation)
+        uploadIntensity(u, name, light.Intensity)
+    }
+
+    u.Integer("spotLightCount", int32(len(l.Spots)))
+    for i, light := range l.Spots {
+        name := fmt.Sprintf("spotLights[%d].", i)
+        u.Vec3(name+"position", light.Position)
+        u.Vec3(name+"direction", light.Direction.Normalize())
+        u.Float(name+"innerCutoff", float32(math.Cos(float64(light.InnerCone))))
+        u.Float(name+"outerCutoff", float32(math.Cos(float64(light.OuterCone))))
+        uploadAttenuation(u, name, light.Attenuation)
+        uploadIntensity(u, name, light.Intensity)
+    }
+
+    return u.Err()
+}
+
+// uploads the intensity to the uniforms with the given name prefix
+func uploadIntensity(u *render.UniformSetter, prefix string, i Intensity) {
+    u.Vec3(prefix+"ambient", i.Ambient.Vec3())
+    u.Vec3(prefix+"diffuse", i.Diffuse.Vec3())
+    u.Vec3(prefix+"specular", i.Specular.Vec3())
+}
+
+// uploads the attenuation to the uniforms with the given name prefix
+func uploadAttenuation(u *render.UniformSetter, prefix string, a Attenuation) {
+    u.Float(prefix+"constant", a.Constant)
+    u.Float(prefix+"linear", a.Linear)
+    u.Float(prefix+"quadratic", a.Quadratic)
+}
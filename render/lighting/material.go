@@ -0,0 +1,85 @@
+package lighting
+
+import (
+    "image"
+    "image/color"
+    "logl/render"
+)
+
+// Material describes how a surface responds to the lights. Each colour multiplies the matching map, and a nil map
+// leaves the colour on its own.
+type Material struct {
+    Ambient  render.Color
+    Diffuse  render.Color
+    Specular render.Color
+
+    AmbientMap  render.BindableTexture
+    DiffuseMap  render.BindableTexture
+    SpecularMap render.BindableTexture
+
+    // the specular exponent, larger for smaller, sharper highlights
+    Shininess float32
+}
+
+// Returns a white material with the given diffuse map used for the ambient term too, as most textured surfaces want
+func NewMaterial(diffuseMap, specularMap render.BindableTexture, shininess float32) *Material {
+    white := render.RGB(1, 1, 1)
+
+    return &Material{
+        Ambient:     white,
+        Diffuse:     white,
+        Specular:    white,
+        AmbientMap:  diffuseMap,
+        DiffuseMap:  diffuseMap,
+        SpecularMap: specularMap,
+        Shininess:   shininess,
+    }
+}
+
+// a 1x1 white texture standing in for missing maps, created with the first material bound and deleted with the window
+var whiteTexture *render.Texture
+
+func orWhite(texture render.BindableTexture) render.BindableTexture {
+    if texture != nil {
+        return texture
+    }
+
+    if whiteTexture == nil {
+        img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+        img.Set(0, 0, color.White)
+
+        whiteTexture = render.NewTexture(img, render.TextureOpts{
+            WrapS:     render.Repeat,
+            WrapT:     render.Repeat,
+            MinFilter: render.Nearest,
+            MagFilter: render.Nearest,
+        })
+
+        render.OnDestroy(func() {
+            whiteTexture.Delete()
+            whiteTexture = nil
+        })
+    }
+
+    return whiteTexture
+}
+
+// Sets the maps as textures of the target (a render.Material for a program built with BlinnPhongGLSL), binds it,
+// and uploads the colours and shininess. The target may hold other textures of its own.
+func (m *Material) Bind(target *render.Material) error {
+    target.SetTexture("material.ambientMap", orWhite(m.AmbientMap))
+    target.SetTexture("material.diffuseMap", orWhite(m.DiffuseMap))
+    target.SetTexture("material.specularMap", orWhite(m.SpecularMap))
+
+    if err := target.Bind(); err != nil {
+        return err
+    }
+
+    u := target.Program().Uniforms()
+    u.Vec3("material.ambient", m.Ambient.Vec3())
+    u.Vec3("material.diffuse", m.Diffuse.Vec3())
+    u.Vec3("material.specular", m.Specular.Vec3())
+    u.Float("material.shininess", m.Shininess)
+
+    return u.Err()
+}
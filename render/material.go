@@ -0,0 +1,135 @@
+package render
+
+import (
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Material
+// --------------------------------------------------------------------------------------------------------
+
+// any texture type that can be bound to a texture unit - Texture, CubeMap, Texture2DArray and Texture3D
+type BindableTexture interface {
+    Bind(textureUnit TextureUnit)
+}
+
+// creates the texture unit with the given zero based index
+func NewTextureUnit(index int) TextureUnit {
+    return TextureUnit{gl.TEXTURE0 + index, index}
+}
+
+// cached GL_MAX_COMBINED_TEXTURE_IMAGE_UNITS - needs a current context so is filled on first use
+var maxTextureUnits int32
+
+// the number of texture units available across all shader stages. Requires a current context.
+func MaxTextureUnits() int32 {
+    if maxTextureUnits == 0 {
+        gl.GetIntegerv(gl.MAX_COMBINED_TEXTURE_IMAGE_UNITS, &maxTextureUnits)
+    }
+
+    return maxTextureUnits
+}
+
+// a texture (and optional sampler) bound to a sampler uniform of the material program
+type materialTexture struct {
+    uniform string
+    texture BindableTexture
+    sampler *Sampler
+}
+
+// Material binds textures to the sampler uniforms of a program by name. Texture units are assigned automatically in
+// the order the textures were first set, so callers never match units to uniforms by hand.
+type Material struct {
+    prog     *Program
+    textures []*materialTexture
+}
+
+// Creates a new material for the given program
+func NewMaterial(prog *Program) *Material {
+    return &Material{prog: prog}
+}
+
+// the program the material binds to
+func (m *Material) Program() *Program {
+    return m.prog
+}
+
+// Sets the texture sampled by the named uniform, replacing any texture previously set for it. A nil texture removes
+// it, as RemoveTexture.
+func (m *Material) SetTexture(uniform string, texture BindableTexture) {
+    if texture == nil {
+        m.RemoveTexture(uniform)
+        return
+    }
+
+    if t := m.find(uniform); t != nil {
+        t.texture = texture
+        return
+    }
+
+    m.textures = append(m.textures, &materialTexture{uniform: uniform, texture: texture})
+}
+
+// Sets the sampler used with the texture of the named uniform, nil restores the texture's own parameters. The
+// texture must already have been set.
+func (m *Material) SetSampler(uniform string, sampler *Sampler) error {
+    t := m.find(uniform)
+    if t == nil {
+        return fmt.Errorf("no texture set for sampler uniform: name = %s", uniform)
+    }
+
+    t.sampler = sampler
+    return nil
+}
+
+// Removes the texture for the named uniform, the remaining textures are reassigned units on the next Bind
+func (m *Material) RemoveTexture(uniform string) {
+    for i, t := range m.textures {
+        if t.uniform != uniform {
+            continue
+        }
+
+        m.textures = append(m.textures[:i], m.textures[i+1:]...)
+        return
+    }
+}
+
+// Uses the program and binds every texture to its assigned unit, writing the sampler uniforms each time - the
+// program may be shared with materials assigning the same uniforms other units. Fails if the textures exceed the
+// available units or a uniform cannot be found.
+func (m *Material) Bind() error {
+    if max := int(MaxTextureUnits()); len(m.textures) > max {
+        return fmt.Errorf("material exceeds available texture units: textures = %d, max = %d", len(m.textures), max)
+    }
+
+    m.prog.Use()
+
+    for i, t := range m.textures {
+        unit := NewTextureUnit(i)
+
+        if err := m.prog.Integer(t.uniform, unit.Index()); err != nil {
+            return err
+        }
+
+        t.texture.Bind(unit)
+
+        if t.sampler != nil {
+            t.sampler.Bind(unit)
+        } else {
+            UnbindSampler(unit)
+        }
+    }
+
+    return nil
+}
+
+func (m *Material) find(uniform string) *materialTexture {
+    for _, t := range m.textures {
+        if t.uniform == uniform {
+            return t
+        }
+    }
+
+    return nil
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Math types
+// --------------------------------------------------------------------------------------------------------
+
+// the vector, quaternion and matrix types used throughout the render package. These are aliases of the mgl32 types
+// so values pass freely between render, its sub packages and mgl32 itself (LookAtV, Perspective etc).
+type (
+    Vec2 = mgl32.Vec2
+    Vec3 = mgl32.Vec3
+    Vec4 = mgl32.Vec4
+    Quat = mgl32.Quat
+    Mat3 = mgl32.Mat3
+    Mat4 = mgl32.Mat4
+)
+
+// Vec4 component synonyms - RGBA
+const (
+    R = iota
+    G
+    B
+    A
+)
+
+// Vec4 component synonyms - XYZW
+const (
+    X = iota
+    Y
+    Z
+    W
+)
+
+// builds a Vec2 from the given components of v, e.g. Swizzle2(v, X, Z)
+func Swizzle2(v Vec4, i, j int) Vec2 {
+    return Vec2{v[i], v[j]}
+}
+
+// builds a Vec3 from the given components of v, e.g. Swizzle3(c, B, G, R)
+func Swizzle3(v Vec4, i, j, k int) Vec3 {
+    return Vec3{v[i], v[j], v[k]}
+}
+
+// builds a Vec4 from the given components of v, e.g. Swizzle4(v, W, Z, Y, X)
+func Swizzle4(v Vec4, i, j, k, l int) Vec4 {
+    return Vec4{v[i], v[j], v[k], v[l]}
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+    "testing"
+)
+
+func TestComponentConstants(t *testing.T) {
+    tests := []struct {
+        name      string
+        got, want int
+    }{
+        {"X", X, 0},
+        {"Y", Y, 1},
+        {"Z", Z, 2},
+        {"W", W, 3},
+        {"R", R, 0},
+        {"G", G, 1},
+        {"B", B, 2},
+        {"A", A, 3},
+    }
+
+    for _, test := range tests {
+        if test.got != test.want {
+            t.Errorf("%s = %d, want %d", test.name, test.got, test.want)
+        }
+    }
+
+    // the constants index the components mgl32 names
+    v := Vec4{1, 2, 3, 4}
+    if v[X] != v.X() || v[Y] != v.Y() || v[Z] != v.Z() || v[W] != v.W() {
+        t.Errorf("components of %v don't match X, Y, Z and W", v)
+    }
+}
+
+func TestSwizzle(t *testing.T) {
+    v := Vec4{1, 2, 3, 4}
+
+    tests2 := []struct {
+        i, j int
+        want Vec2
+    }{
+        {X, Y, Vec2{1, 2}},
+        {X, Z, Vec2{1, 3}},
+        {W, X, Vec2{4, 1}},
+        {Y, Y, Vec2{2, 2}},
+    }
+
+    for _, test := range tests2 {
+        if got := Swizzle2(v, test.i, test.j); got != test.want {
+            t.Errorf("Swizzle2(%v, %d, %d) = %v, want %v", v, test.i, test.j, got, test.want)
+        }
+    }
+
+    tests3 := []struct {
+        i, j, k int
+        want    Vec3
+    }{
+        {X, Y, Z, Vec3{1, 2, 3}},
+        {B, G, R, Vec3{3, 2, 1}},
+        {W, W, X, Vec3{4, 4, 1}},
+    }
+
+    for _, test := range tests3 {
+        if got := Swizzle3(v, test.i, test.j, test.k); got != test.want {
+            t.Errorf("Swizzle3(%v, %d, %d, %d) = %v, want %v", v, test.i, test.j, test.k, got, test.want)
+        }
+    }
+
+    tests4 := []struct {
+        i, j, k, l int
+        want       Vec4
+    }{
+        {X, Y, Z, W, Vec4{1, 2, 3, 4}},
+        {W, Z, Y, X, Vec4{4, 3, 2, 1}},
+        {A, R, G, B, Vec4{4, 1, 2, 3}},
+        {X, X, X, X, Vec4{1, 1, 1, 1}},
+    }
+
+    for _, test := range tests4 {
+        if got := Swizzle4(v, test.i, test.j, test.k, test.l); got != test.want {
+            t.Errorf("Swizzle4(%v, %d, %d, %d, %d) = %v, want %v", v, test.i, test.j, test.k, test.l, got, test.want)
+        }
+    }
+}
@@ -0,0 +1,81 @@
+package render
+
+import (
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "logl/render/model"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Mesh
+// --------------------------------------------------------------------------------------------------------
+
+// an indexed triangle mesh uploaded to gl buffers, with each attribute bound to its fixed location from
+// model.Mesh.Interleave - position 0, normal 1, texture coordinate 2, tangent 3, colour 4, joints 5 and weights 6
+type Mesh struct {
+    vao    *VertexArray
+    vbo    *Buffer
+    ebo    *Buffer
+    count  int32
+    index  uint32
+    layout []model.Attribute
+
+    // the instance buffer the vertex array's instance attributes were last pointed at
+    instances *InstanceBuffer
+}
+
+// Uploads the mesh into a new vertex array with static buffers
+func NewMesh(m *model.Mesh) *Mesh {
+    data, stride, layout := m.Interleave()
+
+    vao := NewVertexArray()
+    vbo := NewBuffer(ArrayBuffer)
+    ebo := NewBuffer(ElementArrayBuffer)
+
+    vao.Bind()
+    vbo.Data(len(data)*4, data, StaticDraw)
+
+    // narrower indices when they fit halve the index data. A mesh without any has nothing to upload or draw.
+    index := uint32(gl.UNSIGNED_INT)
+    if len(m.Indices) == 0 {
+        ebo.Bind()
+    } else if indices, ok := m.Indices16(); ok {
+        ebo.Data(len(indices)*2, indices, StaticDraw)
+        index = gl.UNSIGNED_SHORT
+    } else {
+        ebo.Data(len(m.Indices)*4, m.Indices, StaticDraw)
+    }
+
+    for _, attr := range layout {
+        gl.VertexAttribPointer(attr.Location, attr.Size, gl.FLOAT, false, stride, gl.PtrOffset(attr.Offset))
+        gl.EnableVertexAttribArray(attr.Location)
+    }
+
+    gl.BindVertexArray(0)
+
+    return &Mesh{vao: vao, vbo: vbo, ebo: ebo, count: int32(len(m.Indices)), index: index, layout: layout}
+}
+
+// the attributes of the vertex data, in location order
+func (m *Mesh) Layout() []model.Attribute {
+    return m.layout
+}
+
+// draws the mesh with the program currently in use
+func (m *Mesh) Draw() {
+    if m.count == 0 {
+        return
+    }
+
+    m.vao.Bind()
+    gl.DrawElements(gl.TRIANGLES, m.count, m.index, nil)
+    gl.BindVertexArray(0)
+
+    glCheck("drawing a mesh")
+}
+
+// releases the vertex array and buffers
+func (m *Mesh) Delete() {
+    m.vao.Delete()
+    m.vbo.Delete()
+    m.ebo.Delete()
+}
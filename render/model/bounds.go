@@ -0,0 +1,171 @@
+package model
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// AABB is an axis aligned bounding box
+type AABB struct {
+    Min, Max mgl32.Vec3
+}
+
+// Returns an empty box, inside out so that extending it by any point gives a box around just that point
+func EmptyAABB() AABB {
+    inf := float32(math.Inf(1))
+    return AABB{Min: mgl32.Vec3{inf, inf, inf}, Max: mgl32.Vec3{-inf, -inf, -inf}}
+}
+
+// true when the box contains no points
+func (b AABB) Empty() bool {
+    return b.Min[0] > b.Max[0] || b.Min[1] > b.Max[1] || b.Min[2] > b.Max[2]
+}
+
+// the middle of the box
+func (b AABB) Center() mgl32.Vec3 {
+    return b.Min.Add(b.Max).Mul(0.5)
+}
+
+// the width, height and depth of the box
+func (b AABB) Size() mgl32.Vec3 {
+    return b.Max.Sub(b.Min)
+}
+
+// Returns the box grown to contain p
+func (b AABB) Extend(p mgl32.Vec3) AABB {
+    for c := range p {
+        if p[c] < b.Min[c] {
+            b.Min[c] = p[c]
+        }
+        if p[c] > b.Max[c] {
+            b.Max[c] = p[c]
+        }
+    }
+    return b
+}
+
+// Returns the smallest box containing both boxes
+func (b AABB) Union(other AABB) AABB {
+    if other.Empty() {
+        return b
+    }
+    return b.Extend(other.Min).Extend(other.Max)
+}
+
+// true when p is inside or on the box
+func (b AABB) Contains(p mgl32.Vec3) bool {
+    return p[0] >= b.Min[0] && p[0] <= b.Max[0] &&
+        p[1] >= b.Min[1] && p[1] <= b.Max[1] &&
+        p[2] >= b.Min[2] && p[2] <= b.Max[2]
+}
+
+// the 8 corners of the box, with bit 0 of the index choosing max x, bit 1 max y and bit 2 max z
+func (b AABB) Corners() [8]mgl32.Vec3 {
+    var corners [8]mgl32.Vec3
+    for i := range corners {
+        for c := 0; c < 3; c++ {
+            if i&(1<<uint(c)) != 0 {
+                corners[i][c] = b.Max[c]
+            } else {
+                corners[i][c] = b.Min[c]
+            }
+        }
+    }
+    return corners
+}
+
+// Returns the axis aligned box around the box transformed by m, which is larger than the transformed contents when
+// m rotates them
+func (b AABB) Transform(m mgl32.Mat4) AABB {
+    if b.Empty() {
+        return b
+    }
+
+    // each column of the rotation / scale contributes its smallest and largest product to the new extents
+    // (Arvo, Transforming Axis-Aligned Bounding Boxes, Graphics Gems 1990)
+    t := m.Col(3).Vec3()
+    result := AABB{Min: t, Max: t}
+
+    for col := 0; col < 3; col++ {
+        for row := 0; row < 3; row++ {
+            e, f := m.At(row, col)*b.Min[col], m.At(row, col)*b.Max[col]
+            if e > f {
+                e, f = f, e
+            }
+            result.Min[row] += e
+            result.Max[row] += f
+        }
+    }
+
+    return result
+}
+
+// Sphere is a bounding sphere
+type Sphere struct {
+    Center mgl32.Vec3
+    Radius float32
+}
+
+// Returns the sphere transformed by m, its radius scaled by the largest scale along any axis
+func (s Sphere) Transform(m mgl32.Mat4) Sphere {
+    scale := float32(0)
+    for col := 0; col < 3; col++ {
+        if l := m.Col(col).Vec3().Len(); l > scale {
+            scale = l
+        }
+    }
+
+    return Sphere{Center: mgl32.TransformCoordinate(s.Center, m), Radius: s.Radius * scale}
+}
+
+// true when p is inside or on the sphere
+func (s Sphere) Contains(p mgl32.Vec3) bool {
+    return p.Sub(s.Center).Len() <= s.Radius
+}
+
+// the bounding box of the positions, empty when there are none
+func (m *Mesh) Bounds() AABB {
+    b := EmptyAABB()
+    for _, p := range m.Positions {
+        b = b.Extend(p)
+    }
+    return b
+}
+
+// Returns a sphere enclosing all the positions, using Ritter's algorithm - an initial sphere across two distant
+// points, grown to take in any point outside it. The result is within a few percent of the smallest sphere.
+func (m *Mesh) BoundingSphere() Sphere {
+    if len(m.Positions) == 0 {
+        return Sphere{}
+    }
+
+    farthest := func(from mgl32.Vec3) mgl32.Vec3 {
+        best, distance := from, float32(-1)
+        for _, p := range m.Positions {
+            if d := p.Sub(from).LenSqr(); d > distance {
+                best, distance = p, d
+            }
+        }
+        return best
+    }
+
+    a := farthest(m.Positions[0])
+    b := farthest(a)
+
+    s := Sphere{Center: a.Add(b).Mul(0.5), Radius: b.Sub(a).Len() / 2}
+
+    for _, p := range m.Positions {
+        offset := p.Sub(s.Center)
+        d := offset.Len()
+        if d <= s.Radius {
+            continue
+        }
+
+        // move the center towards p just far enough that the far side of the old sphere stays inside
+        radius := (s.Radius + d) / 2
+        s.Center = s.Center.Add(offset.Mul((radius - s.Radius) / d))
+        s.Radius = radius
+    }
+
+    return s
+}
@@ -0,0 +1,240 @@
+// Package model holds indexed triangle meshes and the loaders producing them. Everything here is pure Go - meshes
+// are flattened with Interleave into vertex and index data ready to upload to gl buffers.
+package model
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// Mesh is an indexed triangle list. Every attribute slice is either empty or the same length as Positions.
+type Mesh struct {
+    Name string
+
+    Positions []mgl32.Vec3
+    Normals   []mgl32.Vec3
+    TexCoords []mgl32.Vec2
+
+    // xyz is the tangent direction and w the handedness (+1 or -1) of the bitangent, cross(normal, tangent) * w
+    Tangents []mgl32.Vec4
+
+    // linear RGBA vertex colours
+    Colors []mgl32.Vec4
+
+    // skinning - the indices of up to four joints influencing each vertex and their weights, which sum to 1
+    Joints  [][4]uint16
+    Weights []mgl32.Vec4
+
+    // three indices per triangle, wound counter clockwise when viewed from the front
+    Indices []uint32
+
+    // the name of the material the mesh is drawn with, empty for none
+    Material string
+}
+
+// the number of vertices
+func (m *Mesh) VertexCount() int {
+    return len(m.Positions)
+}
+
+// the number of triangles
+func (m *Mesh) TriangleCount() int {
+    return len(m.Indices) / 3
+}
+
+// the indices of the three vertices of triangle i
+func (m *Mesh) Triangle(i int) (uint32, uint32, uint32) {
+    return m.Indices[i*3], m.Indices[i*3+1], m.Indices[i*3+2]
+}
+
+// Rebuilds every vertex attribute so that vertex i is a copy of the old vertex sources[i]. The indices are left
+// for the caller to update.
+func (m *Mesh) remap(sources []uint32) {
+    n := len(m.Positions)
+
+    positions := make([]mgl32.Vec3, len(sources))
+    for i, s := range sources {
+        positions[i] = m.Positions[s]
+    }
+    m.Positions = positions
+
+    if len(m.Normals) == n {
+        normals := make([]mgl32.Vec3, len(sources))
+        for i, s := range sources {
+            normals[i] = m.Normals[s]
+        }
+        m.Normals = normals
+    }
+
+    if len(m.TexCoords) == n {
+        texCoords := make([]mgl32.Vec2, len(sources))
+        for i, s := range sources {
+            texCoords[i] = m.TexCoords[s]
+        }
+        m.TexCoords = texCoords
+    }
+
+    if len(m.Tangents) == n {
+        m.Tangents = remapVec4s(m.Tangents, sources)
+    }
+
+    if len(m.Colors) == n {
+        m.Colors = remapVec4s(m.Colors, sources)
+    }
+
+    if len(m.Joints) == n {
+        joints := make([][4]uint16, len(sources))
+        for i, s := range sources {
+            joints[i] = m.Joints[s]
+        }
+        m.Joints = joints
+    }
+
+    if len(m.Weights) == n {
+        m.Weights = remapVec4s(m.Weights, sources)
+    }
+}
+
+func remapVec4s(values []mgl32.Vec4, sources []uint32) []mgl32.Vec4 {
+    remapped := make([]mgl32.Vec4, len(sources))
+    for i, s := range sources {
+        remapped[i] = values[s]
+    }
+    return remapped
+}
+
+// Returns the indices narrowed to uint16, which halves their size, or false when the mesh has too many vertices
+func (m *Mesh) Indices16() ([]uint16, bool) {
+    if len(m.Positions) > math.MaxUint16+1 {
+        return nil, false
+    }
+
+    indices := make([]uint16, len(m.Indices))
+    for i, index := range m.Indices {
+        indices[i] = uint16(index)
+    }
+
+    return indices, true
+}
+
+// Recomputes the normals as the area weighted average of the faces around each vertex. Vertices shared between
+// faces are smoothed over, so hard edges need separate vertices on either side.
+func (m *Mesh) ComputeNormals() {
+    m.Normals = m.faceNormalSums()
+
+    for i, n := range m.Normals {
+        if n.Len() > 0 {
+            m.Normals[i] = n.Normalize()
+        }
+    }
+}
+
+// the sum of the (area weighted) normals of the faces around each vertex
+func (m *Mesh) faceNormalSums() []mgl32.Vec3 {
+    sums := make([]mgl32.Vec3, len(m.Positions))
+
+    for i := 0; i < m.TriangleCount(); i++ {
+        i0, i1, i2 := m.Triangle(i)
+        p0, p1, p2 := m.Positions[i0], m.Positions[i1], m.Positions[i2]
+        face := p1.Sub(p0).Cross(p2.Sub(p0))
+
+        sums[i0] = sums[i0].Add(face)
+        sums[i1] = sums[i1].Add(face)
+        sums[i2] = sums[i2].Add(face)
+    }
+
+    return sums
+}
+
+// Model is a set of meshes along with the materials they reference
+type Model struct {
+    Meshes    []*Mesh
+    Materials map[string]*Material
+}
+
+// describes one attribute within interleaved vertex data
+type Attribute struct {
+    Name string
+
+    // the shader location the attribute is conventionally bound to, whether or not the others are present
+    Location uint32
+
+    // the number of float32 components
+    Size int32
+
+    // byte offset of the attribute from the start of each vertex
+    Offset int
+}
+
+// Flattens the mesh into interleaved float32 vertex data - position, then normal, texture coordinate, tangent, colour,
+// joints and weights when present - returning the data, the per vertex stride in bytes and the layout. These take the
+// attribute locations 0 to 6 in that order, with missing attributes leaving a gap. Joint indices are stored as floats.
+func (m *Mesh) Interleave() ([]float32, int32, []Attribute) {
+    layout := []Attribute{{Name: "position", Size: 3}}
+    floats := 3
+
+    hasNormals := len(m.Normals) == len(m.Positions) && len(m.Normals) > 0
+    if hasNormals {
+        layout = append(layout, Attribute{Name: "normal", Location: 1, Size: 3, Offset: floats * 4})
+        floats += 3
+    }
+
+    hasTexCoords := len(m.TexCoords) == len(m.Positions) && len(m.TexCoords) > 0
+    if hasTexCoords {
+        layout = append(layout, Attribute{Name: "texcoord", Location: 2, Size: 2, Offset: floats * 4})
+        floats += 2
+    }
+
+    hasTangents := len(m.Tangents) == len(m.Positions) && len(m.Tangents) > 0
+    if hasTangents {
+        layout = append(layout, Attribute{Name: "tangent", Location: 3, Size: 4, Offset: floats * 4})
+        floats += 4
+    }
+
+    hasColors := len(m.Colors) == len(m.Positions) && len(m.Colors) > 0
+    if hasColors {
+        layout = append(layout, Attribute{Name: "color", Location: 4, Size: 4, Offset: floats * 4})
+        floats += 4
+    }
+
+    hasSkin := len(m.Joints) == len(m.Positions) && len(m.Weights) == len(m.Positions) && len(m.Joints) > 0
+    if hasSkin {
+        layout = append(layout,
+            Attribute{Name: "joints", Location: 5, Size: 4, Offset: floats * 4},
+            Attribute{Name: "weights", Location: 6, Size: 4, Offset: (floats + 4) * 4},
+        )
+        floats += 8
+    }
+
+    data := make([]float32, 0, len(m.Positions)*floats)
+    for i, p := range m.Positions {
+        data = append(data, p[0], p[1], p[2])
+
+        if hasNormals {
+            n := m.Normals[i]
+            data = append(data, n[0], n[1], n[2])
+        }
+
+        if hasTexCoords {
+            t := m.TexCoords[i]
+            data = append(data, t[0], t[1])
+        }
+
+        if hasTangents {
+            t := m.Tangents[i]
+            data = append(data, t[0], t[1], t[2], t[3])
+        }
+
+        if hasColors {
+            c := m.Colors[i]
+            data = append(data, c[0], c[1], c[2], c[3])
+        }
+
+        if hasSkin {
+            j, w := m.Joints[i], m.Weights[i]
+            data = append(data, float32(j[0]), float32(j[1]), float32(j[2]), float32(j[3]), w[0], w[1], w[2], w[3])
+        }
+    }
+
+    return data, int32(floats * 4), layout
+}
@@ -0,0 +1,228 @@
+package model
+
+import (
+    "bufio"
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// Material holds the surface properties of a mesh, as described by a Wavefront MTL file. Texture maps are paths as
+// written in the file, relative to the file itself.
+type Material struct {
+    Name string
+
+    Ambient  mgl32.Vec3 // Ka
+    Diffuse  mgl32.Vec3 // Kd
+    Specular mgl32.Vec3 // Ks
+    Emissive mgl32.Vec3 // Ke
+
+    Shininess         float32 // Ns
+    OpticalDensity    float32 // Ni
+    Opacity           float32 // d, or 1 - Tr
+    IlluminationModel int     // illum
+
+    AmbientMap   string // map_Ka
+    DiffuseMap   string // map_Kd
+    SpecularMap  string // map_Ks
+    ShininessMap string // map_Ns
+    OpacityMap   string // map_d
+    BumpMap      string // map_Bump / bump
+    NormalMap    string // norm
+    DisplaceMap  string // disp
+}
+
+// a material with the MTL defaults - white diffuse, fully opaque
+func newMaterial(name string) *Material {
+    return &Material{
+        Name:           name,
+        Diffuse:        mgl32.Vec3{1, 1, 1},
+        OpticalDensity: 1,
+        Opacity:        1,
+    }
+}
+
+// Parses the materials of an MTL file
+func ParseMTL(r io.Reader) (map[string]*Material, error) {
+    materials := map[string]*Material{}
+
+    var current *Material
+    err := scanStatements(r, func(line int, keyword string, args []string) error {
+        if keyword == "newmtl" {
+            if len(args) == 0 {
+                return fmt.Errorf("mtl: line %d: newmtl without a name", line)
+            }
+
+            current = newMaterial(strings.Join(args, " "))
+            materials[current.Name] = current
+            return nil
+        }
+
+        if current == nil {
+            return fmt.Errorf("mtl: line %d: %s before newmtl", line, keyword)
+        }
+
+        var err error
+        switch strings.ToLower(keyword) {
+        case "ka":
+            current.Ambient, err = parseColor(args)
+        case "kd":
+            current.Diffuse, err = parseColor(args)
+        case "ks":
+            current.Specular, err = parseColor(args)
+        case "ke":
+            current.Emissive, err = parseColor(args)
+        case "ns":
+            current.Shininess, err = parseFloat(args, 0)
+        case "ni":
+            current.OpticalDensity, err = parseFloat(args, 0)
+        case "d":
+            current.Opacity, err = parseFloat(args, len(args)-1)
+        case "tr":
+            var tr float32
+            tr, err = parseFloat(args, 0)
+            current.Opacity = 1 - tr
+        case "illum":
+            var illum float32
+            illum, err = parseFloat(args, 0)
+            current.IlluminationModel = int(illum)
+        case "map_ka":
+            current.AmbientMap, err = parseMap(args)
+        case "map_kd":
+            current.DiffuseMap, err = parseMap(args)
+        case "map_ks":
+            current.SpecularMap, err = parseMap(args)
+        case "map_ns":
+            current.ShininessMap, err = parseMap(args)
+        case "map_d":
+            current.OpacityMap, err = parseMap(args)
+        case "map_bump", "bump":
+            current.BumpMap, err = parseMap(args)
+        case "norm", "map_kn":
+            current.NormalMap, err = parseMap(args)
+        case "disp":
+            current.DisplaceMap, err = parseMap(args)
+        default:
+            // unsupported statements (Tf, sharpness, refl, pbr extensions etc) are ignored
+        }
+
+        if err != nil {
+            return fmt.Errorf("mtl: line %d: %s: %s", line, keyword, err)
+        }
+
+        return nil
+    })
+
+    if err != nil {
+        return nil, err
+    }
+
+    return materials, nil
+}
+
+// an RGB colour, a single value applies to all three channels. The spectral and xyz forms are not supported.
+func parseColor(args []string) (mgl32.Vec3, error) {
+    if len(args) > 0 && (args[0] == "spectral" || args[0] == "xyz") {
+        return mgl32.Vec3{}, fmt.Errorf("unsupported colour form: %s", args[0])
+    }
+
+    values, err := parseFloats(args, 1, 3)
+    if err != nil {
+        return mgl32.Vec3{}, err
+    }
+
+    if len(values) == 1 {
+        return mgl32.Vec3{values[0], values[0], values[0]}, nil
+    }
+
+    if len(values) != 3 {
+        return mgl32.Vec3{}, fmt.Errorf("expected 1 or 3 values, got %d", len(values))
+    }
+
+    return mgl32.Vec3{values[0], values[1], values[2]}, nil
+}
+
+// the value at the given argument index (the last argument of "d -halo 0.5" is the value)
+func parseFloat(args []string, i int) (float32, error) {
+    if i < 0 || i >= len(args) {
+        return 0, fmt.Errorf("missing value")
+    }
+
+    v, err := strconv.ParseFloat(args[i], 32)
+    return float32(v), err
+}
+
+// the file name of a texture map statement - the options before it (-bm 1, -s 1 1 1 etc) are skipped by taking the
+// final argument
+func parseMap(args []string) (string, error) {
+    if len(args) == 0 {
+        return "", fmt.Errorf("missing file name")
+    }
+
+    return args[len(args)-1], nil
+}
+
+// parses between min and max float arguments
+func parseFloats(args []string, min, max int) ([]float32, error) {
+    if len(args) < min || len(args) > max {
+        return nil, fmt.Errorf("expected %d to %d values, got %d", min, max, len(args))
+    }
+
+    values := make([]float32, len(args))
+    for i, arg := range args {
+        v, err := strconv.ParseFloat(arg, 32)
+        if err != nil {
+            return nil, err
+        }
+        values[i] = float32(v)
+    }
+
+    return values, nil
+}
+
+// splits the input into statements - a keyword followed by whitespace separated arguments - handling comments and
+// backslash line continuations. The line number passed is the line the statement started on.
+func scanStatements(r io.Reader, statement func(line int, keyword string, args []string) error) error {
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+    lineNumber := 0
+    var pending strings.Builder
+    start := 0
+
+    for scanner.Scan() {
+        lineNumber++
+        text := scanner.Text()
+
+        if pending.Len() == 0 {
+            start = lineNumber
+        }
+
+        if strings.HasSuffix(text, "\\") {
+            pending.WriteString(strings.TrimSuffix(text, "\\"))
+            pending.WriteByte(' ')
+            continue
+        }
+
+        pending.WriteString(text)
+        text = pending.String()
+        pending.Reset()
+
+        if i := strings.IndexByte(text, '#'); i >= 0 {
+            text = text[:i]
+        }
+
+        fields := strings.Fields(text)
+        if len(fields) == 0 {
+            continue
+        }
+
+        if err := statement(start, fields[0], fields[1:]); err != nil {
+            return err
+        }
+    }
+
+    return scanner.Err()
+}
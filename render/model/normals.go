@@ -0,0 +1,95 @@
+package model
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// Gives every triangle its own three vertices with the face normal, for a faceted look. Any other attributes are
+// copied to the new vertices.
+func (m *Mesh) ComputeFlatNormals() {
+    sources := make([]uint32, len(m.Indices))
+    copy(sources, m.Indices)
+
+    m.remap(sources)
+
+    m.Normals = make([]mgl32.Vec3, len(m.Positions))
+    for i := range m.Indices {
+        m.Indices[i] = uint32(i)
+    }
+
+    for i := 0; i < m.TriangleCount(); i++ {
+        n := m.triangleNormal(i)
+        m.Normals[i*3], m.Normals[i*3+1], m.Normals[i*3+2] = n, n, n
+    }
+}
+
+// Recomputes the normals, smoothing across edges where the faces meet at less than the threshold angle (in radians)
+// and keeping the edge hard otherwise. Faces are smoothed across by position, so seams in the texture coordinates
+// don't show in the shading. Vertices on a hard edge are split as needed, copying their other attributes.
+func (m *Mesh) ComputeSmoothNormals(threshold float32) {
+    triangles := m.TriangleCount()
+    limit := float32(math.Cos(float64(threshold)))
+
+    // the area weighted and unit normals of each face
+    faces := make([]mgl32.Vec3, triangles)
+    units := make([]mgl32.Vec3, triangles)
+    for i := range faces {
+        i0, i1, i2 := m.Triangle(i)
+        p0, p1, p2 := m.Positions[i0], m.Positions[i1], m.Positions[i2]
+        faces[i] = p1.Sub(p0).Cross(p2.Sub(p0))
+        units[i] = m.triangleNormal(i)
+    }
+
+    // the triangles around each distinct position
+    around := make(map[mgl32.Vec3][]int)
+    for i, index := range m.Indices {
+        p := m.Positions[index]
+        around[p] = append(around[p], i/3)
+    }
+
+    // the normal of each corner sums the faces around its position that are close enough to its own face
+    normals := make([]mgl32.Vec3, len(m.Indices))
+    for i, index := range m.Indices {
+        face := i / 3
+
+        var sum mgl32.Vec3
+        for _, other := range around[m.Positions[index]] {
+            if other == face || units[face].Dot(units[other]) >= limit {
+                sum = sum.Add(faces[other])
+            }
+        }
+
+        if sum.Len() > 0 {
+            sum = sum.Normalize()
+        }
+        normals[i] = sum
+    }
+
+    // corners of the same vertex with the same normal keep sharing it, the rest get their own copy
+    type split struct {
+        vertex uint32
+        normal mgl32.Vec3
+    }
+
+    vertices := make(map[split]uint32, len(m.Positions))
+    sources := make([]uint32, 0, len(m.Positions))
+    resolved := make([]mgl32.Vec3, 0, len(m.Positions))
+
+    for i, index := range m.Indices {
+        key := split{index, normals[i]}
+
+        vertex, ok := vertices[key]
+        if !ok {
+            vertex = uint32(len(sources))
+            vertices[key] = vertex
+            sources = append(sources, index)
+            resolved = append(resolved, normals[i])
+        }
+
+        m.Indices[i] = vertex
+    }
+
+    m.remap(sources)
+    m.Normals = resolved
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "testing"
+)
+
+func BenchmarkComputeSmoothNormals(b *testing.B) {
+    for i := 0; i < b.N; i++ {
+        // a fresh mesh every time, as the work is done in place
+        b.StopTimer()
+        m := Torus(2, 0.5, 128, 64)
+        b.StartTimer()
+
+        m.ComputeSmoothNormals(mgl32.DegToRad(60))
+    }
+}
@@ -0,0 +1,339 @@
+package model
+
+import (
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+)
+
+// opens a material library referenced by an OBJ file
+type MaterialOpener func(name string) (io.ReadCloser, error)
+
+// Reads an OBJ file from the given path, loading any material libraries it references from the same directory
+func ReadOBJ(path string) (*Model, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+
+    defer f.Close()
+
+    dir := filepath.Dir(path)
+
+    return ParseOBJ(f, func(name string) (io.ReadCloser, error) {
+        return os.Open(filepath.Join(dir, name))
+    })
+}
+
+// the attribute indices of a face corner (zero based, -1 when absent)
+type objCorner struct {
+    v, vt, vn int
+}
+
+// the key a vertex is de-duplicated on. Corners without a normal are also split by smoothing group so that
+// generated normals are only averaged within a group - faces with smoothing off get a unique group each.
+type objVertexKey struct {
+    objCorner
+    smoothing int
+}
+
+// accumulates the faces of one mesh
+type objMeshBuilder struct {
+    mesh    *Mesh
+    lookup  map[objVertexKey]uint32
+    missing []bool // per vertex: the normal needs generating
+    hasUV   bool   // any face supplied texture coordinates
+}
+
+// Parses an OBJ file - positions, texture coordinates, normals and faces of any size (triangulated by ear
+// clipping) with relative (negative) indices. A new mesh is started for each object, group and material change.
+// Vertices are de-duplicated into indexed meshes and missing normals are generated following the smoothing groups.
+// Material libraries are loaded through the opener, which may be nil to skip them.
+func ParseOBJ(r io.Reader, opener MaterialOpener) (*Model, error) {
+    model := &Model{Materials: map[string]*Material{}}
+
+    var positions, normals []mgl32.Vec3
+    var texCoords []mgl32.Vec2
+
+    object, group, material := "", "", ""
+    smoothing := 0
+    faces := 0
+
+    var current *objMeshBuilder
+    builders := []*objMeshBuilder{}
+
+    // starts a new mesh on the next face after any naming or material change
+    changed := true
+
+    err := scanStatements(r, func(line int, keyword string, args []string) error {
+        fail := func(format string, a ...interface{}) error {
+            return fmt.Errorf("obj: line %d: %s: %s", line, keyword, fmt.Sprintf(format, a...))
+        }
+
+        switch keyword {
+        case "v":
+            values, err := parseFloats(args, 3, 7)
+            if err != nil {
+                return fail("%s", err)
+            }
+            // any trailing w or vertex colour values are ignored
+            positions = append(positions, mgl32.Vec3{values[0], values[1], values[2]})
+
+        case "vt":
+            values, err := parseFloats(args, 1, 3)
+            if err != nil {
+                return fail("%s", err)
+            }
+            uv := mgl32.Vec2{values[0], 0}
+            if len(values) > 1 {
+                uv[1] = values[1]
+            }
+            texCoords = append(texCoords, uv)
+
+        case "vn":
+            values, err := parseFloats(args, 3, 3)
+            if err != nil {
+                return fail("%s", err)
+            }
+            normals = append(normals, mgl32.Vec3{values[0], values[1], values[2]})
+
+        case "f":
+            if len(args) < 3 {
+                return fail("face needs at least 3 vertices, got %d", len(args))
+            }
+
+            corners := make([]objCorner, len(args))
+            for i, arg := range args {
+                corner, err := parseCorner(arg, len(positions), len(texCoords), len(normals))
+                if err != nil {
+                    return fail("%s", err)
+                }
+                corners[i] = corner
+            }
+
+            if changed {
+                current = &objMeshBuilder{
+                    mesh:   &Mesh{Name: meshName(object, group), Material: material},
+                    lookup: map[objVertexKey]uint32{},
+                }
+                builders = append(builders, current)
+                changed = false
+            }
+
+            faces++
+            smoothingGroup := smoothing
+            if smoothingGroup == 0 {
+                smoothingGroup = -faces
+            }
+
+            current.addFace(corners, smoothingGroup, positions, texCoords, normals)
+
+        case "o":
+            object = strings.Join(args, " ")
+            changed = true
+
+        case "g":
+            group = strings.Join(args, " ")
+            changed = true
+
+        case "usemtl":
+            material = strings.Join(args, " ")
+            changed = true
+
+        case "s":
+            if len(args) != 1 {
+                return fail("expected a single value")
+            }
+
+            if args[0] == "off" {
+                smoothing = 0
+                break
+            }
+
+            value, err := strconv.Atoi(args[0])
+            if err != nil {
+                return fail("%s", err)
+            }
+            smoothing = value
+
+        case "mtllib":
+            if opener == nil {
+                break
+            }
+
+            for _, name := range args {
+                if err := loadMaterials(model, opener, name); err != nil {
+                    return fail("%s", err)
+                }
+            }
+
+        default:
+            // points, lines, free form geometry and display attributes are not supported and are ignored
+        }
+
+        return nil
+    })
+
+    if err != nil {
+        return nil, err
+    }
+
+    for _, b := range builders {
+        b.finish()
+        model.Meshes = append(model.Meshes, b.mesh)
+    }
+
+    return model, nil
+}
+
+func meshName(object, group string) string {
+    switch {
+    case object != "" && group != "":
+        return object + "/" + group
+    case object != "":
+        return object
+    default:
+        return group
+    }
+}
+
+func loadMaterials(model *Model, opener MaterialOpener, name string) error {
+    rc, err := opener(name)
+    if err != nil {
+        return err
+    }
+
+    defer rc.Close()
+
+    materials, err := ParseMTL(rc)
+    if err != nil {
+        return err
+    }
+
+    for k, v := range materials {
+        model.Materials[k] = v
+    }
+
+    return nil
+}
+
+// parses a face corner in the form v, v/vt, v//vn or v/vt/vn, resolving negative indices against the counts so far
+func parseCorner(arg string, positions, texCoords, normals int) (objCorner, error) {
+    parts := strings.Split(arg, "/")
+    if len(parts) > 3 {
+        return objCorner{}, fmt.Errorf("invalid face vertex: %s", arg)
+    }
+
+    corner := objCorner{-1, -1, -1}
+    counts := []int{positions, texCoords, normals}
+    targets := []*int{&corner.v, &corner.vt, &corner.vn}
+
+    for i, part := range parts {
+        if part == "" {
+            if i == 0 {
+                return objCorner{}, fmt.Errorf("missing position index: %s", arg)
+            }
+            continue
+        }
+
+        index, err := strconv.Atoi(part)
+        if err != nil {
+            return objCorner{}, fmt.Errorf("invalid face vertex: %s", arg)
+        }
+
+        switch {
+        case index > 0:
+            index--
+        case index < 0:
+            index += counts[i]
+        default:
+            return objCorner{}, fmt.Errorf("zero index in face vertex: %s", arg)
+        }
+
+        if index < 0 || index >= counts[i] {
+            return objCorner{}, fmt.Errorf("index out of range in face vertex: %s", arg)
+        }
+
+        *targets[i] = index
+    }
+
+    return corner, nil
+}
+
+// triangulates the face and appends its (de-duplicated) vertices and indices
+func (b *objMeshBuilder) addFace(corners []objCorner, smoothing int, positions []mgl32.Vec3, texCoords []mgl32.Vec2,
+    normals []mgl32.Vec3) {
+
+    polygon := make([]mgl32.Vec3, len(corners))
+    for i, c := range corners {
+        polygon[i] = positions[c.v]
+    }
+
+    indices := make([]uint32, len(corners))
+    for i, c := range corners {
+        key := objVertexKey{objCorner: c}
+        if c.vn < 0 {
+            key.smoothing = smoothing
+        }
+
+        index, ok := b.lookup[key]
+        if !ok {
+            index = uint32(len(b.mesh.Positions))
+            b.lookup[key] = index
+
+            b.mesh.Positions = append(b.mesh.Positions, positions[c.v])
+
+            var uv mgl32.Vec2
+            if c.vt >= 0 {
+                uv = texCoords[c.vt]
+                b.hasUV = true
+            }
+            b.mesh.TexCoords = append(b.mesh.TexCoords, uv)
+
+            var n mgl32.Vec3
+            if c.vn >= 0 {
+                n = normals[c.vn]
+            }
+            b.mesh.Normals = append(b.mesh.Normals, n)
+            b.missing = append(b.missing, c.vn < 0)
+        }
+
+        indices[i] = index
+    }
+
+    for _, t := range Triangulate(polygon) {
+        b.mesh.Indices = append(b.mesh.Indices, indices[t[0]], indices[t[1]], indices[t[2]])
+    }
+}
+
+// generates any missing normals and drops attributes no face supplied
+func (b *objMeshBuilder) finish() {
+    mesh := b.mesh
+
+    anyMissing := false
+    for _, m := range b.missing {
+        anyMissing = anyMissing || m
+    }
+
+    if anyMissing {
+        // area weighted face normals for the vertices needing them
+        generated := mesh.faceNormalSums()
+
+        for i, missing := range b.missing {
+            if missing && generated[i].Len() > 0 {
+                mesh.Normals[i] = generated[i].Normalize()
+            }
+        }
+    }
+
+    if !b.hasUV {
+        mesh.TexCoords = nil
+    }
+
+    b.lookup = nil
+    b.missing = nil
+}
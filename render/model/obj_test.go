@@ -0,0 +1,268 @@
+package model
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+    "strings"
+    "testing"
+)
+
+func readTestOBJ(t *testing.T, name string) *Model {
+    t.Helper()
+
+    m, err := ReadOBJ("testdata/" + name)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    return m
+}
+
+func meshNamed(t *testing.T, m *Model, name string) *Mesh {
+    t.Helper()
+
+    for _, mesh := range m.Meshes {
+        if mesh.Name == name {
+            return mesh
+        }
+    }
+
+    t.Fatalf("no mesh named %q", name)
+    return nil
+}
+
+// the area of every triangle of the mesh added up
+func meshArea(m *Mesh) float32 {
+    area := float32(0)
+    for i := 0; i < m.TriangleCount(); i++ {
+        a, b, c := m.Triangle(i)
+        pa, pb, pc := m.Positions[a], m.Positions[b], m.Positions[c]
+        area += pb.Sub(pa).Cross(pc.Sub(pa)).Len() / 2
+    }
+    return area
+}
+
+func near(a, b float32) bool {
+    return math.Abs(float64(a-b)) < 1e-4
+}
+
+func TestOBJNegativeIndices(t *testing.T) {
+    m := readTestOBJ(t, "negative.obj")
+
+    if len(m.Meshes) != 2 {
+        t.Fatalf("got %d meshes, want 2", len(m.Meshes))
+    }
+
+    quad := meshNamed(t, m, "quad")
+
+    if quad.VertexCount() != 4 || quad.TriangleCount() != 2 {
+        t.Fatalf("got %d vertices and %d triangles, want 4 and 2", quad.VertexCount(), quad.TriangleCount())
+    }
+
+    // the relative indices resolve to the quad's own positions, not the triangle before it
+    for i, p := range quad.Positions {
+        if p.Z() != 0 || p.X() < 0 || p.X() > 1 || p.Y() < 0 || p.Y() > 1 {
+            t.Errorf("position %d = %v is outside the quad", i, p)
+        }
+
+        // texture coordinates were written to match the positions
+        if uv := quad.TexCoords[i]; uv != (mgl32.Vec2{p.X(), p.Y()}) {
+            t.Errorf("vertex %d: uv = %v for position %v", i, uv, p)
+        }
+
+        if n := quad.Normals[i]; n != (mgl32.Vec3{0, 0, 1}) {
+            t.Errorf("vertex %d: normal = %v, want +Z", i, n)
+        }
+    }
+
+    if area := meshArea(quad); !near(area, 1) {
+        t.Errorf("area = %v, want 1", area)
+    }
+}
+
+func TestOBJInvalidIndices(t *testing.T) {
+    tests := []string{
+        "v 0 0 0\nf 1 2 3\n",
+        "v 0 0 0\nv 1 0 0\nv 0 1 0\nf -4 -3 -2\n",
+        "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 0 1 2\n",
+        "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1/1 2/1 3/1\n",
+        "v 0 0 0\nv 1 0 0\nf 1 2\n",
+    }
+
+    for _, test := range tests {
+        if _, err := ParseOBJ(strings.NewReader(test), nil); err == nil {
+            t.Errorf("no error parsing %q", test)
+        }
+    }
+}
+
+func TestOBJPolygonTriangulation(t *testing.T) {
+    m := readTestOBJ(t, "polygon.obj")
+    mesh := m.Meshes[0]
+
+    if mesh.TriangleCount() != 4 {
+        t.Fatalf("got %d triangles, want 4", mesh.TriangleCount())
+    }
+
+    // a triangle outside the L (a fan from a reflex corner) would add area
+    if area := meshArea(mesh); !near(area, 3) {
+        t.Errorf("area = %v, want 3", area)
+    }
+
+    // every triangle keeps the face's counter clockwise winding, facing +Z
+    for i := 0; i < mesh.TriangleCount(); i++ {
+        a, b, c := mesh.Triangle(i)
+        pa, pb, pc := mesh.Positions[a], mesh.Positions[b], mesh.Positions[c]
+
+        if n := pb.Sub(pa).Cross(pc.Sub(pa)); n.Z() <= 0 {
+            t.Errorf("triangle %d is wound the wrong way: normal = %v", i, n)
+        }
+    }
+}
+
+func TestOBJGroupsAndObjects(t *testing.T) {
+    m := readTestOBJ(t, "groups.obj")
+
+    want := []struct {
+        name, material string
+        triangles      int
+    }{
+        {"first", "red", 1},
+        {"second/top", "red", 1},
+        {"second/bottom", "red", 1},
+        {"second/bottom", "glass", 1},
+    }
+
+    if len(m.Meshes) != len(want) {
+        t.Fatalf("got %d meshes, want %d", len(m.Meshes), len(want))
+    }
+
+    for i, w := range want {
+        mesh := m.Meshes[i]
+        if mesh.Name != w.name || mesh.Material != w.material || mesh.TriangleCount() != w.triangles {
+            t.Errorf("mesh %d: got %q with %q and %d triangles, want %q with %q and %d", i, mesh.Name,
+                mesh.Material, mesh.TriangleCount(), w.name, w.material, w.triangles)
+        }
+    }
+}
+
+func TestMTL(t *testing.T) {
+    m := readTestOBJ(t, "groups.obj")
+
+    if len(m.Materials) != 2 {
+        t.Fatalf("got %d materials, want 2", len(m.Materials))
+    }
+
+    red := m.Materials["red"]
+    if red == nil {
+        t.Fatal("no red material")
+    }
+
+    if red.Ambient != (mgl32.Vec3{0.1, 0.1, 0.1}) || red.Diffuse != (mgl32.Vec3{1, 0, 0}) ||
+        red.Specular != (mgl32.Vec3{0.5, 0.5, 0.5}) {
+        t.Errorf("red colours: ambient = %v, diffuse = %v, specular = %v", red.Ambient, red.Diffuse, red.Specular)
+    }
+
+    if red.Shininess != 32 || red.IlluminationModel != 2 || red.Opacity != 1 || red.DiffuseMap != "textures/red.png" {
+        t.Errorf("red: got %+v", red)
+    }
+
+    glass := m.Materials["glass"]
+    if glass == nil {
+        t.Fatal("no glass material")
+    }
+
+    if glass.Opacity != 0.25 || glass.OpticalDensity != 1.5 || glass.BumpMap != "textures/glass_normal.png" {
+        t.Errorf("glass: got %+v", glass)
+    }
+}
+
+func TestOBJSmoothingGroups(t *testing.T) {
+    m := readTestOBJ(t, "smoothing.obj")
+
+    tests := []struct {
+        name     string
+        vertices int
+    }{
+        // the shared edge is shared only within a smoothing group
+        {"together", 4},
+        {"apart", 6},
+        {"flat", 6},
+    }
+
+    for _, test := range tests {
+        mesh := meshNamed(t, m, test.name)
+
+        if mesh.VertexCount() != test.vertices {
+            t.Errorf("%s: got %d vertices, want %d", test.name, mesh.VertexCount(), test.vertices)
+        }
+    }
+
+    // smoothed normals on the shared edge point straight up between the two faces
+    together := meshNamed(t, m, "together")
+    for i, p := range together.Positions {
+        if p.Y() != 0 {
+            continue
+        }
+
+        if n := together.Normals[i]; !near(n.X(), 0) || !near(n.Y(), 1) || !near(n.Z(), 0) {
+            t.Errorf("shared vertex %v: normal = %v, want +Y", p, n)
+        }
+    }
+
+    // flat shaded faces keep their face normals
+    flat := meshNamed(t, m, "flat")
+    for i := 0; i < flat.TriangleCount(); i++ {
+        a, b, c := flat.Triangle(i)
+        face := flat.Positions[b].Sub(flat.Positions[a]).Cross(flat.Positions[c].Sub(flat.Positions[a])).Normalize()
+
+        for _, v := range []uint32{a, b, c} {
+            if n := flat.Normals[v]; n.Sub(face).Len() > 1e-4 {
+                t.Errorf("flat triangle %d: normal = %v, want the face normal %v", i, n, face)
+            }
+        }
+    }
+}
+
+func TestOBJVertexDeduplication(t *testing.T) {
+    m := readTestOBJ(t, "cube.obj")
+
+    tests := []struct {
+        name      string
+        vertices  int
+        triangles int
+    }{
+        // each corner is split by the normal of each face it touches
+        {"faceted", 24, 12},
+
+        // one vertex per corner when the faces share a smoothing group
+        {"smooth", 8, 12},
+    }
+
+    for _, test := range tests {
+        mesh := meshNamed(t, m, test.name)
+
+        if mesh.VertexCount() != test.vertices || mesh.TriangleCount() != test.triangles {
+            t.Errorf("%s: got %d vertices and %d triangles, want %d and %d", test.name, mesh.VertexCount(),
+                mesh.TriangleCount(), test.vertices, test.triangles)
+        }
+
+        if area := meshArea(mesh); !near(area, 6) {
+            t.Errorf("%s: area = %v, want 6", test.name, area)
+        }
+
+        if mesh.TexCoords != nil {
+            t.Errorf("%s: texture coordinates kept without any in the file", test.name)
+        }
+    }
+
+    // the smoothed corners are unit length and point out of the cube - not exactly along the diagonal, as the
+    // face normals are weighted by the area of the triangles the quads were split into
+    smooth := meshNamed(t, m, "smooth")
+    for i, p := range smooth.Positions {
+        n := smooth.Normals[i]
+        if !near(n.Len(), 1) || n.Dot(p.Normalize()) < 0.8 {
+            t.Errorf("corner %v: normal = %v, want a unit vector pointing out of the cube", p, n)
+        }
+    }
+}
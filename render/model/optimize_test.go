@@ -0,0 +1,63 @@
+package model
+
+import (
+    "math/rand"
+    "testing"
+)
+
+// a 60x60 plane with its triangles in a random order, the worst case for the vertex cache
+func shuffledGrid() *Mesh {
+    m := Plane(1, 1, 60, 60)
+
+    r := rand.New(rand.NewSource(1))
+    r.Shuffle(m.TriangleCount(), func(i, j int) {
+        for c := 0; c < 3; c++ {
+            m.Indices[3*i+c], m.Indices[3*j+c] = m.Indices[3*j+c], m.Indices[3*i+c]
+        }
+    })
+
+    return m
+}
+
+func TestOptimizeVertexCache(t *testing.T) {
+    m := shuffledGrid()
+    triangles := map[[3]uint32]bool{}
+    for i := 0; i < m.TriangleCount(); i++ {
+        i0, i1, i2 := m.Triangle(i)
+        triangles[[3]uint32{i0, i1, i2}] = true
+    }
+
+    before := m.CacheMissRatio(16)
+    m.OptimizeVertexCache()
+    after := m.CacheMissRatio(16)
+
+    if before < 2.5 {
+        t.Errorf("shuffled grid has a miss ratio of %v, expected close to 3", before)
+    }
+
+    if after > 0.8 {
+        t.Errorf("miss ratio %v after optimising (%v before), expected below 0.8", after, before)
+    }
+
+    // the same triangles with the same winding, starting from any corner
+    for i := 0; i < m.TriangleCount(); i++ {
+        i0, i1, i2 := m.Triangle(i)
+        if !triangles[[3]uint32{i0, i1, i2}] && !triangles[[3]uint32{i1, i2, i0}] &&
+            !triangles[[3]uint32{i2, i0, i1}] {
+
+            t.Errorf("triangle %d (%d, %d, %d) isn't in the original mesh", i, i0, i1, i2)
+            break
+        }
+    }
+}
+
+func BenchmarkOptimizeVertexCache(b *testing.B) {
+    for i := 0; i < b.N; i++ {
+        // a fresh mesh every time, as the work is done in place
+        b.StopTimer()
+        m := shuffledGrid()
+        b.StartTimer()
+
+        m.OptimizeVertexCache()
+    }
+}
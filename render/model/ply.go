@@ -0,0 +1,581 @@
+package model
+
+import (
+    "bufio"
+    "encoding/binary"
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "io"
+    "math"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// the encoding of the data following a PLY header
+type PLYFormat int
+
+const (
+    PLYASCII PLYFormat = iota
+    PLYBinaryLittleEndian
+    PLYBinaryBigEndian
+)
+
+func (f PLYFormat) String() string {
+    switch f {
+    case PLYASCII:
+        return "ascii"
+    case PLYBinaryLittleEndian:
+        return "binary_little_endian"
+    case PLYBinaryBigEndian:
+        return "binary_big_endian"
+    default:
+        return "Unknown"
+    }
+}
+
+// the size in bytes of each PLY scalar type, by both its old and sized names
+var plyTypeSizes = map[string]int{
+    "char": 1, "int8": 1,
+    "uchar": 1, "uint8": 1,
+    "short": 2, "int16": 2,
+    "ushort": 2, "uint16": 2,
+    "int": 4, "int32": 4,
+    "uint": 4, "uint32": 4,
+    "float": 4, "float32": 4,
+    "double": 8, "float64": 8,
+}
+
+// the value representing full intensity for colours of each integer type - floats are already 0 to 1
+var plyTypeMax = map[string]float64{
+    "char": math.MaxInt8, "int8": math.MaxInt8,
+    "uchar": math.MaxUint8, "uint8": math.MaxUint8,
+    "short": math.MaxInt16, "int16": math.MaxInt16,
+    "ushort": math.MaxUint16, "uint16": math.MaxUint16,
+    "int": math.MaxInt32, "int32": math.MaxInt32,
+    "uint": math.MaxUint32, "uint32": math.MaxUint32,
+}
+
+// the most values a list property may hold - far more than any real polygon, but small enough that a corrupt count
+// can't exhaust memory
+const maxPLYListLength = 1 << 16
+
+type plyProperty struct {
+    name string
+    kind string
+
+    // list properties have a count of the given type followed by that many values of kind
+    list      bool
+    countKind string
+}
+
+type plyElement struct {
+    name       string
+    count      int
+    properties []plyProperty
+}
+
+// reads the values of the body one at a time
+type plyValueReader interface {
+    read(kind string) (float64, error)
+}
+
+// Reads an ASCII or binary PLY file
+func ReadPLY(path string) (*Mesh, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+
+    defer f.Close()
+
+    return ParsePLY(f)
+}
+
+// Parses an ASCII or binary (either endianness) PLY file. Vertex positions, normals, texture coordinates (u/v, s/t or
+// texture_u/texture_v) and colours (red, green, blue and optional alpha) are read and any other properties and
+// elements skipped. Integer colours are taken to be sRGB encoded and converted to linear. Faces with more than 3
+// vertices are triangulated and normals are recomputed when the file doesn't have them.
+func ParsePLY(r io.Reader) (*Mesh, error) {
+    br := bufio.NewReader(r)
+
+    format, elements, err := parsePLYHeader(br)
+    if err != nil {
+        return nil, err
+    }
+
+    var values plyValueReader
+    switch format {
+    case PLYASCII:
+        scanner := bufio.NewScanner(br)
+        scanner.Split(bufio.ScanWords)
+        values = &plyASCIIReader{scanner: scanner}
+    case PLYBinaryLittleEndian:
+        values = &plyBinaryReader{r: br, order: binary.LittleEndian}
+    default:
+        values = &plyBinaryReader{r: br, order: binary.BigEndian}
+    }
+
+    mesh := &Mesh{}
+    hasVertices, hasNormals := false, false
+
+    for _, element := range elements {
+        switch element.name {
+        case "vertex":
+            if hasNormals, err = readPLYVertices(mesh, element, values); err != nil {
+                return nil, err
+            }
+            hasVertices = true
+
+        case "face":
+            if !hasVertices {
+                return nil, fmt.Errorf("ply: faces before vertices are not supported")
+            }
+
+            if err := readPLYFaces(mesh, element, values); err != nil {
+                return nil, err
+            }
+
+        default:
+            if err := skipPLYElement(element, values); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    if !hasNormals {
+        mesh.ComputeNormals()
+    }
+
+    return mesh, nil
+}
+
+func parsePLYHeader(r *bufio.Reader) (PLYFormat, []*plyElement, error) {
+    var format PLYFormat
+    var elements []*plyElement
+    formatSeen := false
+
+    for line := 1; ; line++ {
+        text, err := r.ReadString('\n')
+        if err != nil {
+            return 0, nil, fmt.Errorf("ply: header not terminated: %v", err)
+        }
+
+        fields := strings.Fields(text)
+
+        if line == 1 {
+            if len(fields) != 1 || fields[0] != "ply" {
+                return 0, nil, fmt.Errorf("ply: not a PLY file")
+            }
+            continue
+        }
+
+        if len(fields) == 0 {
+            continue
+        }
+
+        fail := func(format string, args ...interface{}) error {
+            return fmt.Errorf("ply: header line %d: %s", line, fmt.Sprintf(format, args...))
+        }
+
+        switch fields[0] {
+        case "format":
+            if len(fields) != 3 || fields[2] != "1.0" {
+                return 0, nil, fail("unsupported format %q", strings.TrimSpace(text))
+            }
+
+            switch fields[1] {
+            case "ascii":
+                format = PLYASCII
+            case "binary_little_endian":
+                format = PLYBinaryLittleEndian
+            case "binary_big_endian":
+                format = PLYBinaryBigEndian
+            default:
+                return 0, nil, fail("unsupported format %s", fields[1])
+            }
+            formatSeen = true
+
+        case "element":
+            if len(fields) != 3 {
+                return 0, nil, fail("element needs a name and count")
+            }
+
+            count, err := strconv.Atoi(fields[2])
+            if err != nil || count < 0 {
+                return 0, nil, fail("invalid element count %s", fields[2])
+            }
+
+            elements = append(elements, &plyElement{name: fields[1], count: count})
+
+        case "property":
+            if len(elements) == 0 {
+                return 0, nil, fail("property before any element")
+            }
+
+            var p plyProperty
+            switch {
+            case len(fields) == 5 && fields[1] == "list":
+                p = plyProperty{name: fields[4], kind: fields[3], list: true, countKind: fields[2]}
+                if _, ok := plyTypeSizes[p.countKind]; !ok {
+                    return 0, nil, fail("unknown type %s", p.countKind)
+                }
+            case len(fields) == 3:
+                p = plyProperty{name: fields[2], kind: fields[1]}
+            default:
+                return 0, nil, fail("invalid property")
+            }
+
+            if _, ok := plyTypeSizes[p.kind]; !ok {
+                return 0, nil, fail("unknown type %s", p.kind)
+            }
+
+            element := elements[len(elements)-1]
+            element.properties = append(element.properties, p)
+
+        case "end_header":
+            if !formatSeen {
+                return 0, nil, fail("missing format")
+            }
+            return format, elements, nil
+
+        case "comment", "obj_info":
+
+        default:
+            return 0, nil, fail("unknown keyword %s", fields[0])
+        }
+    }
+}
+
+// which vertex attribute component each recognised property feeds
+var plyVertexProperties = map[string][2]int{
+    "x": {0, 0}, "y": {0, 1}, "z": {0, 2},
+    "nx": {1, 0}, "ny": {1, 1}, "nz": {1, 2},
+    "u": {2, 0}, "v": {2, 1}, "s": {2, 0}, "t": {2, 1},
+    "texture_u": {2, 0}, "texture_v": {2, 1}, "texture_s": {2, 0}, "texture_t": {2, 1},
+    "red": {3, 0}, "green": {3, 1}, "blue": {3, 2}, "alpha": {3, 3},
+    "diffuse_red": {3, 0}, "diffuse_green": {3, 1}, "diffuse_blue": {3, 2},
+}
+
+// reads the vertex element into the mesh, reporting whether it held normals
+func readPLYVertices(mesh *Mesh, element *plyElement, values plyValueReader) (bool, error) {
+    var present [4]bool
+    for _, p := range element.properties {
+        if target, ok := plyVertexProperties[p.name]; ok && !p.list {
+            present[target[0]] = true
+        }
+    }
+
+    for i := 0; i < element.count; i++ {
+        // position, normal, texture coordinate and colour
+        attrs := [4]mgl32.Vec4{{}, {}, {}, {0, 0, 0, 1}}
+
+        for _, p := range element.properties {
+            target, ok := plyVertexProperties[p.name]
+            if !ok || p.list {
+                if err := skipPLYProperty(p, values); err != nil {
+                    return false, err
+                }
+                continue
+            }
+
+            value, err := values.read(p.kind)
+            if err != nil {
+                return false, fmt.Errorf("ply: vertex %d: %s: %v", i, p.name, err)
+            }
+
+            if target[0] == 3 {
+                if max, ok := plyTypeMax[p.kind]; ok {
+                    value /= max
+                    if target[1] != 3 {
+                        value = srgbToLinear(value)
+                    }
+                }
+            }
+
+            attrs[target[0]][target[1]] = float32(value)
+        }
+
+        mesh.Positions = append(mesh.Positions, attrs[0].Vec3())
+
+        if present[1] {
+            mesh.Normals = append(mesh.Normals, attrs[1].Vec3())
+        }
+
+        if present[2] {
+            mesh.TexCoords = append(mesh.TexCoords, attrs[2].Vec2())
+        }
+
+        if present[3] {
+            mesh.Colors = append(mesh.Colors, attrs[3])
+        }
+    }
+
+    return present[1], nil
+}
+
+func readPLYFaces(mesh *Mesh, element *plyElement, values plyValueReader) error {
+    for i := 0; i < element.count; i++ {
+        for _, p := range element.properties {
+            if !p.list || (p.name != "vertex_indices" && p.name != "vertex_index") {
+                if err := skipPLYProperty(p, values); err != nil {
+                    return err
+                }
+                continue
+            }
+
+            count, err := readPLYListLength(p, values)
+            if err != nil {
+                return fmt.Errorf("ply: face %d: %v", i, err)
+            }
+
+            indices := make([]uint32, count)
+            polygon := make([]mgl32.Vec3, count)
+
+            for j := range indices {
+                value, err := values.read(p.kind)
+                if err != nil {
+                    return fmt.Errorf("ply: face %d: %v", i, err)
+                }
+
+                if value < 0 || int(value) >= len(mesh.Positions) {
+                    return fmt.Errorf("ply: face %d: vertex index %v out of range", i, value)
+                }
+
+                indices[j] = uint32(value)
+                polygon[j] = mesh.Positions[indices[j]]
+            }
+
+            for _, t := range Triangulate(polygon) {
+                mesh.Indices = append(mesh.Indices, indices[t[0]], indices[t[1]], indices[t[2]])
+            }
+        }
+    }
+
+    return nil
+}
+
+func skipPLYElement(element *plyElement, values plyValueReader) error {
+    for i := 0; i < element.count; i++ {
+        for _, p := range element.properties {
+            if err := skipPLYProperty(p, values); err != nil {
+                return err
+            }
+        }
+    }
+
+    return nil
+}
+
+func skipPLYProperty(p plyProperty, values plyValueReader) error {
+    count := 1
+
+    if p.list {
+        var err error
+        if count, err = readPLYListLength(p, values); err != nil {
+            return fmt.Errorf("ply: %s: %v", p.name, err)
+        }
+    }
+
+    for j := 0; j < count; j++ {
+        if _, err := values.read(p.kind); err != nil {
+            return fmt.Errorf("ply: %s: %v", p.name, err)
+        }
+    }
+
+    return nil
+}
+
+// reads the number of values in a list property
+func readPLYListLength(p plyProperty, values plyValueReader) (int, error) {
+    count, err := values.read(p.countKind)
+    if err != nil {
+        return 0, err
+    }
+
+    // written so that NaN fails too
+    if !(count >= 0 && count <= maxPLYListLength) || count != math.Trunc(count) {
+        return 0, fmt.Errorf("invalid list length: count = %v", count)
+    }
+
+    return int(count), nil
+}
+
+type plyASCIIReader struct {
+    scanner *bufio.Scanner
+}
+
+func (r *plyASCIIReader) read(kind string) (float64, error) {
+    if !r.scanner.Scan() {
+        if err := r.scanner.Err(); err != nil {
+            return 0, err
+        }
+        return 0, io.ErrUnexpectedEOF
+    }
+
+    return strconv.ParseFloat(r.scanner.Text(), 64)
+}
+
+type plyBinaryReader struct {
+    r     *bufio.Reader
+    order binary.ByteOrder
+    buf   [8]byte
+}
+
+func (r *plyBinaryReader) read(kind string) (float64, error) {
+    b := r.buf[:plyTypeSizes[kind]]
+    if _, err := io.ReadFull(r.r, b); err != nil {
+        return 0, err
+    }
+
+    switch kind {
+    case "char", "int8":
+        return float64(int8(b[0])), nil
+    case "uchar", "uint8":
+        return float64(b[0]), nil
+    case "short", "int16":
+        return float64(int16(r.order.Uint16(b))), nil
+    case "ushort", "uint16":
+        return float64(r.order.Uint16(b)), nil
+    case "int", "int32":
+        return float64(int32(r.order.Uint32(b))), nil
+    case "uint", "uint32":
+        return float64(r.order.Uint32(b)), nil
+    case "float", "float32":
+        return float64(math.Float32frombits(r.order.Uint32(b))), nil
+    default:
+        return math.Float64frombits(r.order.Uint64(b)), nil
+    }
+}
+
+// Writes the mesh to a PLY file in the given format
+func WritePLY(path string, m *Mesh, format PLYFormat) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+
+    if err := EncodePLY(f, m, format); err != nil {
+        f.Close()
+        return err
+    }
+
+    return f.Close()
+}
+
+// Encodes the mesh as a PLY file in the given format. Positions, normals, texture coordinates (as s and t) are written
+// as floats and colours as sRGB encoded bytes, with faces as lists of uint indices.
+func EncodePLY(w io.Writer, m *Mesh, format PLYFormat) error {
+    bw := bufio.NewWriter(w)
+
+    n := len(m.Positions)
+    hasNormals := len(m.Normals) == n && n > 0
+    hasTexCoords := len(m.TexCoords) == n && n > 0
+    hasColors := len(m.Colors) == n && n > 0
+
+    fmt.Fprintf(bw, "ply\nformat %s 1.0\n", format)
+    if m.Name != "" {
+        fmt.Fprintf(bw, "comment %s\n", m.Name)
+    }
+
+    fmt.Fprintf(bw, "element vertex %d\n", n)
+    bw.WriteString("property float x\nproperty float y\nproperty float z\n")
+
+    if hasNormals {
+        bw.WriteString("property float nx\nproperty float ny\nproperty float nz\n")
+    }
+
+    if hasTexCoords {
+        bw.WriteString("property float s\nproperty float t\n")
+    }
+
+    if hasColors {
+        bw.WriteString("property uchar red\nproperty uchar green\nproperty uchar blue\nproperty uchar alpha\n")
+    }
+
+    fmt.Fprintf(bw, "element face %d\n", m.TriangleCount())
+    bw.WriteString("property list uchar uint vertex_indices\nend_header\n")
+
+    var order binary.ByteOrder = binary.LittleEndian
+    if format == PLYBinaryBigEndian {
+        order = binary.BigEndian
+    }
+
+    // each value is written as text or in binary, ASCII lines separated by newlines
+    var values []interface{}
+    flush := func() error {
+        if format == PLYASCII {
+            for i, v := range values {
+                if i > 0 {
+                    bw.WriteByte(' ')
+                }
+                fmt.Fprint(bw, v)
+            }
+            bw.WriteByte('\n')
+        } else {
+            for _, v := range values {
+                if err := binary.Write(bw, order, v); err != nil {
+                    return err
+                }
+            }
+        }
+
+        values = values[:0]
+        return nil
+    }
+
+    toByte := func(v float32, linear bool) uint8 {
+        if linear {
+            v = float32(linearToSRGB(float64(v)))
+        }
+        return uint8(math.Round(float64(mgl32.Clamp(v, 0, 1)) * 255))
+    }
+
+    for i, p := range m.Positions {
+        values = append(values, p[0], p[1], p[2])
+
+        if hasNormals {
+            values = append(values, m.Normals[i][0], m.Normals[i][1], m.Normals[i][2])
+        }
+
+        if hasTexCoords {
+            values = append(values, m.TexCoords[i][0], m.TexCoords[i][1])
+        }
+
+        if hasColors {
+            c := m.Colors[i]
+            values = append(values, toByte(c[0], true), toByte(c[1], true), toByte(c[2], true), toByte(c[3], false))
+        }
+
+        if err := flush(); err != nil {
+            return err
+        }
+    }
+
+    for i := 0; i < m.TriangleCount(); i++ {
+        i0, i1, i2 := m.Triangle(i)
+        values = append(values, uint8(3), i0, i1, i2)
+
+        if err := flush(); err != nil {
+            return err
+        }
+    }
+
+    return bw.Flush()
+}
+
+func srgbToLinear(v float64) float64 {
+    if v <= 0.04045 {
+        return v / 12.92
+    }
+
+    return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) float64 {
+    if v <= 0.0031308 {
+        return v * 12.92
+    }
+
+    return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+    "bytes"
+    "github.com/go-gl/mathgl/mgl32"
+    "strings"
+    "testing"
+)
+
+// a cube with every vertex attribute PLY can hold
+func colouredCube() *Mesh {
+    m := Cube(2, 2)
+    m.Tangents = nil
+
+    for i, p := range m.Positions {
+        m.Colors = append(m.Colors, mgl32.Vec4{(p[0] + 1) / 2, (p[1] + 1) / 2, (p[2] + 1) / 2, float32(i%2) / 2})
+    }
+
+    return m
+}
+
+func TestPLYRoundTrip(t *testing.T) {
+    for _, format := range []PLYFormat{PLYASCII, PLYBinaryLittleEndian, PLYBinaryBigEndian} {
+        original := colouredCube()
+
+        var buf bytes.Buffer
+        if err := EncodePLY(&buf, original, format); err != nil {
+            t.Errorf("%s: encoding: %v", format, err)
+            continue
+        }
+
+        if !strings.Contains(buf.String(), "format "+format.String()+" 1.0\n") {
+            t.Errorf("%s: header doesn't name the format", format)
+        }
+
+        m, err := ParsePLY(&buf)
+        if err != nil {
+            t.Errorf("%s: parsing: %v", format, err)
+            continue
+        }
+
+        if len(m.Positions) != len(original.Positions) || len(m.Normals) != len(original.Normals) ||
+            len(m.TexCoords) != len(original.TexCoords) || len(m.Colors) != len(original.Colors) {
+
+            t.Errorf("%s: read %d/%d/%d/%d positions/normals/texcoords/colours, expected %d", format,
+                len(m.Positions), len(m.Normals), len(m.TexCoords), len(m.Colors), len(original.Positions))
+            continue
+        }
+
+        for i := range m.Positions {
+            if m.Positions[i] != original.Positions[i] || m.Normals[i] != original.Normals[i] ||
+                m.TexCoords[i] != original.TexCoords[i] {
+
+                t.Errorf("%s: vertex %d differs", format, i)
+                break
+            }
+
+            // colours are stored as sRGB bytes
+            if m.Colors[i].Sub(original.Colors[i]).Len() > 0.01 {
+                t.Errorf("%s: colour %d read as %v, expected %v", format, i, m.Colors[i], original.Colors[i])
+                break
+            }
+        }
+
+        if len(m.Indices) != len(original.Indices) {
+            t.Errorf("%s: read %d indices, expected %d", format, len(m.Indices), len(original.Indices))
+            continue
+        }
+
+        for i := range m.Indices {
+            if m.Indices[i] != original.Indices[i] {
+                t.Errorf("%s: index %d is %d, expected %d", format, i, m.Indices[i], original.Indices[i])
+                break
+            }
+        }
+    }
+}
+
+func TestPLYListLength(t *testing.T) {
+    header := "ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n" +
+        "element face 1\nproperty list int int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\n"
+
+    tests := []struct {
+        face string
+        ok   bool
+    }{
+        {"3 0 1 2", true},
+        {"-1 0 1 2", false},
+        {"2000000000 0 1 2", false},
+        {"2.5 0 1 2", false},
+        {"nan 0 1 2", false},
+    }
+
+    for _, test := range tests {
+        _, err := ParsePLY(strings.NewReader(header + test.face + "\n"))
+        if (err == nil) != test.ok {
+            t.Errorf("%s: error = %v, expected ok = %v", test.face, err, test.ok)
+        }
+    }
+}
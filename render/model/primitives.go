@@ -0,0 +1,422 @@
+package model
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// Procedural meshes. Every generator produces positions, normals, tangents, texture coordinates (v increasing
+// upwards) and indices, centred on the origin with triangles wound counter clockwise seen from outside. Segment counts
+// below the minimum for the shape are raised to it.
+
+// Creates a flat rectangle in the XZ plane facing +Y, split into a grid of segments. Texture v runs towards -Z.
+func Plane(width, depth float32, segmentsX, segmentsZ int) *Mesh {
+    mesh := &Mesh{Name: "plane"}
+
+    origin := mgl32.Vec3{-width / 2, 0, depth / 2}
+    mesh.addGrid(origin, mgl32.Vec3{width, 0, 0}, mgl32.Vec3{0, 0, -depth}, atLeast(segmentsX, 1),
+        atLeast(segmentsZ, 1))
+
+    return mesh
+}
+
+// Creates an axis aligned cube with sides of the given size, each face a grid of segments by segments with its own
+// normals and the full texture
+func Cube(size float32, segments int) *Mesh {
+    mesh := &Mesh{Name: "cube"}
+    segments = atLeast(segments, 1)
+    h := size / 2
+
+    // each face as its bottom left corner and the axes across and up it, seen from outside
+    faces := [6][3]mgl32.Vec3{
+        {{h, -h, h}, {0, 0, -size}, {0, size, 0}},  // +X
+        {{-h, -h, -h}, {0, 0, size}, {0, size, 0}}, // -X
+        {{-h, h, h}, {size, 0, 0}, {0, 0, -size}},  // +Y
+        {{-h, -h, -h}, {size, 0, 0}, {0, 0, size}}, // -Y
+        {{-h, -h, h}, {size, 0, 0}, {0, size, 0}},  // +Z
+        {{h, -h, -h}, {-size, 0, 0}, {0, size, 0}}, // -Z
+    }
+
+    for _, f := range faces {
+        mesh.addGrid(f[0], f[1], f[2], segments, segments)
+    }
+
+    return mesh
+}
+
+// Creates a sphere from rings of latitude and segments of longitude, with the texture wrapped around once.
+// Texture u starts at +Z.
+func UVSphere(radius float32, segments, rings int) *Mesh {
+    rings = atLeast(rings, 2)
+
+    profile := make([]profilePoint, rings+1)
+    for i := range profile {
+        // from the south pole up
+        theta := math.Pi * (1 - float64(i)/float64(rings))
+        s, c := float32(math.Sin(theta)), float32(math.Cos(theta))
+
+        profile[i] = profilePoint{radius: radius * s, y: radius * c, normal: mgl32.Vec2{s, c}}
+    }
+
+    mesh := &Mesh{Name: "uv sphere"}
+    mesh.addLathe(profile, atLeast(segments, 3))
+
+    return mesh
+}
+
+// Creates a sphere by repeatedly subdividing an icosahedron, giving evenly sized triangles. Texture coordinates are
+// a spherical projection, with vertices duplicated along the seam.
+func Icosphere(radius float32, subdivisions int) *Mesh {
+    t := float32((1 + math.Sqrt(5)) / 2)
+
+    points := []mgl32.Vec3{
+        {-1, t, 0}, {1, t, 0}, {-1, -t, 0}, {1, -t, 0},
+        {0, -1, t}, {0, 1, t}, {0, -1, -t}, {0, 1, -t},
+        {t, 0, -1}, {t, 0, 1}, {-t, 0, -1}, {-t, 0, 1},
+    }
+
+    for i := range points {
+        points[i] = points[i].Normalize()
+    }
+
+    triangles := []uint32{
+        0, 11, 5, 0, 5, 1, 0, 1, 7, 0, 7, 10, 0, 10, 11,
+        1, 5, 9, 5, 11, 4, 11, 10, 2, 10, 7, 6, 7, 1, 8,
+        3, 9, 4, 3, 4, 2, 3, 2, 6, 3, 6, 8, 3, 8, 9,
+        4, 9, 5, 2, 4, 11, 6, 2, 10, 8, 6, 7, 9, 8, 1,
+    }
+
+    for s := 0; s < subdivisions; s++ {
+        midpoints := map[[2]uint32]uint32{}
+        midpoint := func(a, b uint32) uint32 {
+            key := [2]uint32{a, b}
+            if a > b {
+                key = [2]uint32{b, a}
+            }
+
+            if index, ok := midpoints[key]; ok {
+                return index
+            }
+
+            index := uint32(len(points))
+            points = append(points, points[a].Add(points[b]).Normalize())
+            midpoints[key] = index
+
+            return index
+        }
+
+        next := make([]uint32, 0, len(triangles)*4)
+        for i := 0; i < len(triangles); i += 3 {
+            a, b, c := triangles[i], triangles[i+1], triangles[i+2]
+            ab, bc, ca := midpoint(a, b), midpoint(b, c), midpoint(c, a)
+
+            next = append(next, a, ab, ca, b, bc, ab, c, ca, bc, ab, bc, ca)
+        }
+
+        triangles = next
+    }
+
+    mesh := &Mesh{Name: "icosphere"}
+    for _, p := range points {
+        mesh.addSphereVertex(p, radius)
+    }
+
+    // triangles crossing the seam at u = 0 / 1 get copies of their low u vertices shifted round by a whole turn
+    seam := map[uint32]uint32{}
+    for i := 0; i < len(triangles); i += 3 {
+        tri := triangles[i : i+3]
+
+        u0, u1, u2 := mesh.TexCoords[tri[0]][0], mesh.TexCoords[tri[1]][0], mesh.TexCoords[tri[2]][0]
+        max := float32(math.Max(float64(u0), math.Max(float64(u1), float64(u2))))
+        min := float32(math.Min(float64(u0), math.Min(float64(u1), float64(u2))))
+
+        if max-min <= 0.5 {
+            continue
+        }
+
+        for j, index := range tri {
+            if mesh.TexCoords[index][0] >= 0.5 {
+                continue
+            }
+
+            copyIndex, ok := seam[index]
+            if !ok {
+                copyIndex = uint32(len(mesh.Positions))
+                mesh.Positions = append(mesh.Positions, mesh.Positions[index])
+                mesh.Normals = append(mesh.Normals, mesh.Normals[index])
+                mesh.Tangents = append(mesh.Tangents, mesh.Tangents[index])
+                mesh.TexCoords = append(mesh.TexCoords, mesh.TexCoords[index].Add(mgl32.Vec2{1, 0}))
+                seam[index] = copyIndex
+            }
+
+            tri[j] = copyIndex
+        }
+    }
+
+    mesh.Indices = triangles
+
+    return mesh
+}
+
+// adds a vertex on a sphere from its direction, with the texture coordinates and tangent of the UV sphere mapping
+func (m *Mesh) addSphereVertex(direction mgl32.Vec3, radius float32) {
+    phi := math.Atan2(float64(direction[0]), float64(direction[2]))
+    u := float32(phi / (2 * math.Pi))
+    if u < 0 {
+        u++
+    }
+
+    v := float32(math.Acos(float64(mgl32.Clamp(direction[1], -1, 1))) / math.Pi)
+
+    m.Positions = append(m.Positions, direction.Mul(radius))
+    m.Normals = append(m.Normals, direction)
+    m.TexCoords = append(m.TexCoords, mgl32.Vec2{u, 1 - v})
+    m.Tangents = append(m.Tangents, lathe{phi}.tangent())
+}
+
+// Creates a closed cylinder around the Y axis with segments around and stacks up the side
+func Cylinder(radius, height float32, segments, stacks int) *Mesh {
+    mesh := &Mesh{Name: "cylinder"}
+    mesh.addFrustum(radius, radius, height, atLeast(segments, 3), atLeast(stacks, 1))
+
+    return mesh
+}
+
+// Creates a cone around the Y axis with its base at the bottom and its apex at the top
+func Cone(radius, height float32, segments, stacks int) *Mesh {
+    mesh := &Mesh{Name: "cone"}
+    mesh.addFrustum(radius, 0, height, atLeast(segments, 3), atLeast(stacks, 1))
+
+    return mesh
+}
+
+// Creates a ring lying in the XZ plane - majorRadius from the centre to the middle of the tube, minorRadius the
+// radius of the tube itself
+func Torus(majorRadius, minorRadius float32, majorSegments, minorSegments int) *Mesh {
+    majorSegments = atLeast(majorSegments, 3)
+    minorSegments = atLeast(minorSegments, 3)
+
+    mesh := &Mesh{Name: "torus"}
+
+    for j := 0; j <= minorSegments; j++ {
+        v := float32(j) / float32(minorSegments)
+
+        // round the tube starting from the inside, so the texture seam is out of sight
+        theta := math.Pi + 2*math.Pi*float64(v)
+        st, ct := float32(math.Sin(theta)), float32(math.Cos(theta))
+
+        for i := 0; i <= majorSegments; i++ {
+            u := float32(i) / float32(majorSegments)
+            l := lathe{2 * math.Pi * float64(u)}
+
+            mesh.Positions = append(mesh.Positions, l.point(majorRadius+minorRadius*ct, minorRadius*st))
+            mesh.Normals = append(mesh.Normals, l.point(ct, st))
+            mesh.Tangents = append(mesh.Tangents, l.tangent())
+            mesh.TexCoords = append(mesh.TexCoords, mgl32.Vec2{u, v})
+        }
+    }
+
+    mesh.addGridIndices(0, majorSegments, minorSegments)
+
+    return mesh
+}
+
+// Creates a cylinder with hemispherical ends, height being the overall height including the ends. Rings sets the
+// number of rings in each hemisphere.
+func Capsule(radius, height float32, segments, rings int) *Mesh {
+    rings = atLeast(rings, 1)
+    half := float32(math.Max(float64(height/2-radius), 0))
+
+    profile := make([]profilePoint, 0, 2*rings+2)
+
+    // the bottom hemisphere from the pole up to the equator, then the top one from the equator
+    for _, end := range []struct {
+        centre     float32
+        start, end float64
+    }{{-half, math.Pi, math.Pi / 2}, {half, math.Pi / 2, 0}} {
+        for i := 0; i <= rings; i++ {
+            theta := end.start + (end.end-end.start)*float64(i)/float64(rings)
+            s, c := float32(math.Sin(theta)), float32(math.Cos(theta))
+
+            profile = append(profile, profilePoint{radius: radius * s, y: end.centre + radius*c, normal: mgl32.Vec2{s, c}})
+        }
+    }
+
+    mesh := &Mesh{Name: "capsule"}
+    mesh.addLathe(profile, atLeast(segments, 3))
+
+    return mesh
+}
+
+// a point on the outline of a surface of revolution, normal being the radial and vertical parts of the normal
+type profilePoint struct {
+    radius float32
+    y      float32
+    normal mgl32.Vec2
+}
+
+// the angle around the Y axis of a surface of revolution, 0 being +Z
+type lathe struct {
+    phi float64
+}
+
+// the point at the given distance from the axis and height
+func (l lathe) point(radius, y float32) mgl32.Vec3 {
+    s, c := float32(math.Sin(l.phi)), float32(math.Cos(l.phi))
+    return mgl32.Vec3{radius * s, y, radius * c}
+}
+
+// the tangent along increasing angle, the direction texture u increases in
+func (l lathe) tangent() mgl32.Vec4 {
+    s, c := float32(math.Sin(l.phi)), float32(math.Cos(l.phi))
+    return mgl32.Vec4{c, 0, -s, 1}
+}
+
+// sweeps the profile (given bottom to top) around the Y axis, with texture v following the length of the profile
+func (m *Mesh) addLathe(profile []profilePoint, segments int) {
+    base := uint32(len(m.Positions))
+
+    // texture v in proportion to the distance along the profile
+    lengths := make([]float32, len(profile))
+    for i := 1; i < len(profile); i++ {
+        a, b := profile[i-1], profile[i]
+        lengths[i] = lengths[i-1] + mgl32.Vec2{b.radius - a.radius, b.y - a.y}.Len()
+    }
+
+    total := lengths[len(lengths)-1]
+
+    for j, p := range profile {
+        v := float32(j) / float32(len(profile)-1)
+        if total > 0 {
+            v = lengths[j] / total
+        }
+
+        for i := 0; i <= segments; i++ {
+            u := float32(i) / float32(segments)
+            l := lathe{2 * math.Pi * float64(u)}
+
+            m.Positions = append(m.Positions, l.point(p.radius, p.y))
+            m.Normals = append(m.Normals, l.point(p.normal[0], p.normal[1]).Normalize())
+            m.Tangents = append(m.Tangents, l.tangent())
+            m.TexCoords = append(m.TexCoords, mgl32.Vec2{u, v})
+        }
+    }
+
+    m.addGridIndices(base, segments, len(profile)-1)
+}
+
+// adds the side and caps of a cylinder (equal radii) or cone (zero top radius)
+func (m *Mesh) addFrustum(bottom, top, height float32, segments, stacks int) {
+    h := height / 2
+
+    // the side normal leans outwards by the change in radius over the height
+    slope := mgl32.Vec2{height, bottom - top}.Normalize()
+
+    profile := make([]profilePoint, stacks+1)
+    for i := range profile {
+        t := float32(i) / float32(stacks)
+        profile[i] = profilePoint{radius: bottom + (top-bottom)*t, y: -h + height*t, normal: slope}
+    }
+
+    m.addLathe(profile, segments)
+
+    if top > 0 {
+        m.addDisc(top, h, true, segments)
+    }
+
+    if bottom > 0 {
+        m.addDisc(bottom, -h, false, segments)
+    }
+}
+
+// adds a disc in the XZ plane facing up or down, textured with a planar projection
+func (m *Mesh) addDisc(radius, y float32, up bool, segments int) {
+    normal := mgl32.Vec3{0, -1, 0}
+    if up {
+        normal = mgl32.Vec3{0, 1, 0}
+    }
+
+    base := uint32(len(m.Positions))
+
+    // centre then the rim, with the first rim vertex repeated to close the fan
+    for i := -1; i <= segments; i++ {
+        var p mgl32.Vec3
+        if i >= 0 {
+            p = lathe{2 * math.Pi * float64(i) / float64(segments)}.point(radius, 0)
+        }
+
+        // u along +X, v along -Z from above and +Z from below, keeping the tangent on +X
+        uv := mgl32.Vec2{0.5 + p[0]/(2*radius), 0.5 - p[2]/(2*radius)}
+        if !up {
+            uv[1] = 0.5 + p[2]/(2*radius)
+        }
+
+        m.Positions = append(m.Positions, mgl32.Vec3{p[0], y, p[2]})
+        m.Normals = append(m.Normals, normal)
+        m.Tangents = append(m.Tangents, mgl32.Vec4{1, 0, 0, 1})
+        m.TexCoords = append(m.TexCoords, uv)
+    }
+
+    for i := uint32(1); i <= uint32(segments); i++ {
+        // going round with increasing angle is counter clockwise seen from above
+        if up {
+            m.Indices = append(m.Indices, base, base+i, base+i+1)
+        } else {
+            m.Indices = append(m.Indices, base, base+i+1, base+i)
+        }
+    }
+}
+
+// adds a flat grid from origin spanning the two axes, facing along across x up
+func (m *Mesh) addGrid(origin, across, up mgl32.Vec3, segmentsU, segmentsV int) {
+    base := uint32(len(m.Positions))
+
+    normal := across.Cross(up).Normalize()
+    tangent := across.Normalize().Vec4(1)
+
+    for j := 0; j <= segmentsV; j++ {
+        v := float32(j) / float32(segmentsV)
+
+        for i := 0; i <= segmentsU; i++ {
+            u := float32(i) / float32(segmentsU)
+
+            m.Positions = append(m.Positions, origin.Add(across.Mul(u)).Add(up.Mul(v)))
+            m.Normals = append(m.Normals, normal)
+            m.Tangents = append(m.Tangents, tangent)
+            m.TexCoords = append(m.TexCoords, mgl32.Vec2{u, v})
+        }
+    }
+
+    m.addGridIndices(base, segmentsU, segmentsV)
+}
+
+// adds the triangles of a (segmentsU + 1) x (segmentsV + 1) grid of vertices starting at base, row by row, facing
+// the side the cross product of the u and v directions points to. Triangles collapsed to nothing (at the poles of
+// a sphere, or the apex of a cone) are left out.
+func (m *Mesh) addGridIndices(base uint32, segmentsU, segmentsV int) {
+    row := uint32(segmentsU + 1)
+
+    for j := uint32(0); j < uint32(segmentsV); j++ {
+        for i := uint32(0); i < uint32(segmentsU); i++ {
+            a := base + j*row + i
+            b, c, d := a+1, a+row+1, a+row
+
+            for _, tri := range [2][3]uint32{{a, b, c}, {a, c, d}} {
+                p0, p1, p2 := m.Positions[tri[0]], m.Positions[tri[1]], m.Positions[tri[2]]
+                if p1.Sub(p0).Cross(p2.Sub(p0)).Len() < 1e-12 {
+                    continue
+                }
+
+                m.Indices = append(m.Indices, tri[0], tri[1], tri[2])
+            }
+        }
+    }
+}
+
+func atLeast(value, min int) int {
+    if value < min {
+        return min
+    }
+
+    return value
+}
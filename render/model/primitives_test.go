@@ -0,0 +1,125 @@
+package model
+
+import (
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "testing"
+)
+
+// a generated mesh and the direction outwards from the shape at a point on its surface
+type primitive struct {
+    name    string
+    mesh    *Mesh
+    outward func(p mgl32.Vec3) mgl32.Vec3
+}
+
+// every generator at several segment counts, including counts below their minimum
+func primitives() []primitive {
+    // the convex shapes contain the origin, so outwards is away from it
+    fromOrigin := func(p mgl32.Vec3) mgl32.Vec3 { return p }
+
+    var prims []primitive
+
+    for _, n := range []int{0, 1, 2, 3, 8, 17} {
+        add := func(name string, mesh *Mesh, outward func(p mgl32.Vec3) mgl32.Vec3) {
+            prims = append(prims, primitive{fmt.Sprintf("%s %d", name, n), mesh, outward})
+        }
+
+        add("plane", Plane(2, 3, n, n+1), func(p mgl32.Vec3) mgl32.Vec3 { return mgl32.Vec3{0, 1, 0} })
+        add("cube", Cube(2, n), fromOrigin)
+        add("uv sphere", UVSphere(1, n, n), fromOrigin)
+        add("cylinder", Cylinder(1, 2, n, n), fromOrigin)
+        add("cone", Cone(1, 2, n, n), fromOrigin)
+        add("capsule", Capsule(0.5, 2, n, n), fromOrigin)
+
+        // away from the circle through the middle of the tube
+        add("torus", Torus(2, 0.5, n, n), func(p mgl32.Vec3) mgl32.Vec3 {
+            return p.Sub(mgl32.Vec3{p.X(), 0, p.Z()}.Normalize().Mul(2))
+        })
+
+        // subdivisions grow the triangle count fourfold
+        if n <= 3 {
+            add("icosphere", Icosphere(1, n), fromOrigin)
+        }
+    }
+
+    return prims
+}
+
+// the unnormalised normal of triangle t given by its winding
+func faceNormal(m *Mesh, t int) mgl32.Vec3 {
+    a := m.Positions[m.Indices[3*t]]
+    b := m.Positions[m.Indices[3*t+1]]
+    c := m.Positions[m.Indices[3*t+2]]
+
+    return b.Sub(a).Cross(c.Sub(a))
+}
+
+func TestPrimitiveWinding(t *testing.T) {
+    for _, p := range primitives() {
+        reversed, degenerate := 0, 0
+
+        for i := 0; i < p.mesh.TriangleCount(); i++ {
+            normal := faceNormal(p.mesh, i)
+
+            // triangles collapsed to a point at a pole or apex have no winding
+            if normal.Len() < 1e-6 {
+                degenerate++
+                continue
+            }
+
+            // outwards at the corners, which lie on the surface where the centre of a coarse triangle may not
+            var outward mgl32.Vec3
+            for _, index := range p.mesh.Indices[3*i : 3*i+3] {
+                outward = outward.Add(p.outward(p.mesh.Positions[index]))
+            }
+
+            if normal.Dot(outward) <= 0 {
+                reversed++
+            }
+        }
+
+        if reversed > 0 {
+            t.Errorf("%s: %d of %d triangles wound inwards", p.name, reversed, p.mesh.TriangleCount())
+        }
+
+        if degenerate == p.mesh.TriangleCount() {
+            t.Errorf("%s: no triangles with any area", p.name)
+        }
+    }
+}
+
+func TestPrimitiveNormals(t *testing.T) {
+    for _, p := range primitives() {
+        if len(p.mesh.Normals) != len(p.mesh.Positions) {
+            t.Errorf("%s: %d normals for %d positions", p.name, len(p.mesh.Normals), len(p.mesh.Positions))
+            continue
+        }
+
+        against := 0
+
+        for i := 0; i < p.mesh.TriangleCount(); i++ {
+            face := faceNormal(p.mesh, i)
+            if face.Len() < 1e-6 {
+                continue
+            }
+
+            for _, index := range p.mesh.Indices[3*i : 3*i+3] {
+                if p.mesh.Normals[index].Dot(face) <= 0 {
+                    against++
+                }
+            }
+        }
+
+        if against > 0 {
+            t.Errorf("%s: %d vertex normals face against their triangle", p.name, against)
+        }
+
+        for i, n := range p.mesh.Normals {
+            if l := n.Len(); l < 0.999 || l > 1.001 {
+                t.Errorf("%s: normal %d has length %v", p.name, i, l)
+                break
+            }
+        }
+    }
+}
@@ -0,0 +1,292 @@
+package model
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "io"
+    "io/ioutil"
+    "math"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// Reads an ASCII or binary STL file
+func ReadSTL(path string) (*Mesh, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+
+    defer f.Close()
+
+    return ParseSTL(f)
+}
+
+// Parses an ASCII or binary STL file. The separate triangles of the file are welded into an indexed mesh by merging
+// vertices at identical positions, and the normals are recomputed from the faces (the facet normals in the file are
+// often missing or wrong).
+func ParseSTL(r io.Reader) (*Mesh, error) {
+    data, err := ioutil.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+
+    var mesh *Mesh
+    if isBinarySTL(data) {
+        mesh, err = parseBinarySTL(data)
+    } else {
+        mesh, err = parseASCIISTL(data)
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    mesh.ComputeNormals()
+
+    return mesh, nil
+}
+
+// binary files can also start with "solid", so the size implied by the triangle count decides
+func isBinarySTL(data []byte) bool {
+    if len(data) < 84 {
+        return false
+    }
+
+    count := binary.LittleEndian.Uint32(data[80:])
+    if 84+50*int64(count) == int64(len(data)) {
+        return true
+    }
+
+    return !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("solid"))
+}
+
+func parseBinarySTL(data []byte) (*Mesh, error) {
+    count := int(binary.LittleEndian.Uint32(data[80:]))
+    if 84+50*int64(count) > int64(len(data)) {
+        return nil, fmt.Errorf("stl: truncated file: triangles = %d, size = %d", count, len(data))
+    }
+
+    w := newWelder(strings.TrimSpace(string(bytes.TrimRight(data[:80], "\x00"))), count)
+
+    for i := 0; i < count; i++ {
+        // skip the facet normal, and the attribute byte count after the vertices
+        offset := 84 + 50*i + 12
+
+        for v := 0; v < 3; v++ {
+            var p mgl32.Vec3
+            for c := range p {
+                p[c] = math.Float32frombits(binary.LittleEndian.Uint32(data[offset:]))
+                offset += 4
+            }
+
+            w.add(p)
+        }
+    }
+
+    return w.mesh, nil
+}
+
+func parseASCIISTL(data []byte) (*Mesh, error) {
+    scanner := bufio.NewScanner(bytes.NewReader(data))
+
+    var w *welder
+    line, corners := 0, 0
+
+    for scanner.Scan() {
+        line++
+        fields := strings.Fields(scanner.Text())
+        if len(fields) == 0 {
+            continue
+        }
+
+        switch fields[0] {
+        case "solid":
+            if w == nil {
+                w = newWelder(strings.Join(fields[1:], " "), 0)
+            }
+
+        case "vertex":
+            if w == nil {
+                return nil, fmt.Errorf("stl: line %d: vertex outside of a solid", line)
+            }
+
+            if len(fields) != 4 {
+                return nil, fmt.Errorf("stl: line %d: vertex needs 3 coordinates", line)
+            }
+
+            var p mgl32.Vec3
+            for c := range p {
+                value, err := strconv.ParseFloat(fields[c+1], 32)
+                if err != nil {
+                    return nil, fmt.Errorf("stl: line %d: %v", line, err)
+                }
+                p[c] = float32(value)
+            }
+
+            w.add(p)
+            corners++
+
+        case "endloop":
+            if corners != 3 {
+                return nil, fmt.Errorf("stl: line %d: facet has %d vertices, expected 3", line, corners)
+            }
+            corners = 0
+
+        case "endsolid":
+            // files with several solids are merged into the one mesh
+
+        default:
+            // facet normal, outer loop and endfacet carry nothing needed
+        }
+    }
+
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+
+    if w == nil {
+        return nil, fmt.Errorf("stl: no solid found")
+    }
+
+    return w.mesh, nil
+}
+
+// builds an indexed mesh from a stream of triangle corners, merging corners at identical positions
+type welder struct {
+    mesh   *Mesh
+    lookup map[mgl32.Vec3]uint32
+}
+
+func newWelder(name string, triangles int) *welder {
+    return &welder{
+        mesh:   &Mesh{Name: name, Indices: make([]uint32, 0, triangles*3)},
+        lookup: make(map[mgl32.Vec3]uint32, triangles/2),
+    }
+}
+
+func (w *welder) add(p mgl32.Vec3) {
+    // -0 and 0 are the same position but not the same map key
+    for c := range p {
+        if p[c] == 0 {
+            p[c] = 0
+        }
+    }
+
+    index, ok := w.lookup[p]
+    if !ok {
+        index = uint32(len(w.mesh.Positions))
+        w.mesh.Positions = append(w.mesh.Positions, p)
+        w.lookup[p] = index
+    }
+
+    w.mesh.Indices = append(w.mesh.Indices, index)
+}
+
+// Writes the mesh to an STL file, ASCII or binary, with facet normals calculated from the triangles
+func WriteSTL(path string, m *Mesh, binaryFormat bool) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+
+    if err := EncodeSTL(f, m, binaryFormat); err != nil {
+        f.Close()
+        return err
+    }
+
+    return f.Close()
+}
+
+// Encodes the mesh as an ASCII or binary STL file, with facet normals calculated from the triangles
+func EncodeSTL(w io.Writer, m *Mesh, binaryFormat bool) error {
+    bw := bufio.NewWriter(w)
+
+    var err error
+    if binaryFormat {
+        err = encodeBinarySTL(bw, m)
+    } else {
+        err = encodeASCIISTL(bw, m)
+    }
+
+    if err != nil {
+        return err
+    }
+
+    return bw.Flush()
+}
+
+// the unit normal of triangle i, zero for a degenerate triangle
+func (m *Mesh) triangleNormal(i int) mgl32.Vec3 {
+    i0, i1, i2 := m.Triangle(i)
+    p0, p1, p2 := m.Positions[i0], m.Positions[i1], m.Positions[i2]
+
+    n := p1.Sub(p0).Cross(p2.Sub(p0))
+    if n.Len() == 0 {
+        return n
+    }
+
+    return n.Normalize()
+}
+
+func encodeBinarySTL(w *bufio.Writer, m *Mesh) error {
+    var header [80]byte
+    copy(header[:], m.Name)
+
+    if _, err := w.Write(header[:]); err != nil {
+        return err
+    }
+
+    if err := binary.Write(w, binary.LittleEndian, uint32(m.TriangleCount())); err != nil {
+        return err
+    }
+
+    var record [50]byte
+    put := func(offset int, v mgl32.Vec3) {
+        for c := range v {
+            binary.LittleEndian.PutUint32(record[offset+c*4:], math.Float32bits(v[c]))
+        }
+    }
+
+    for i := 0; i < m.TriangleCount(); i++ {
+        i0, i1, i2 := m.Triangle(i)
+
+        put(0, m.triangleNormal(i))
+        put(12, m.Positions[i0])
+        put(24, m.Positions[i1])
+        put(36, m.Positions[i2])
+
+        if _, err := w.Write(record[:]); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+func encodeASCIISTL(w *bufio.Writer, m *Mesh) error {
+    name := strings.Fields(m.Name)
+    solid := strings.Join(name, "_")
+
+    fmt.Fprintf(w, "solid %s\n", solid)
+
+    for i := 0; i < m.TriangleCount(); i++ {
+        i0, i1, i2 := m.Triangle(i)
+        n := m.triangleNormal(i)
+
+        fmt.Fprintf(w, "  facet normal %g %g %g\n    outer loop\n", n[0], n[1], n[2])
+        for _, index := range []uint32{i0, i1, i2} {
+            p := m.Positions[index]
+            fmt.Fprintf(w, "      vertex %g %g %g\n", p[0], p[1], p[2])
+        }
+        fmt.Fprintf(w, "    endloop\n  endfacet\n")
+    }
+
+    _, err := fmt.Fprintf(w, "endsolid %s\n", solid)
+
+    return err
+}
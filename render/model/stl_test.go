@@ -0,0 +1,56 @@
+package model
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestSTLRoundTrip(t *testing.T) {
+    for _, binaryFormat := range []bool{false, true} {
+        original := Cube(2, 2)
+
+        var buf bytes.Buffer
+        if err := EncodeSTL(&buf, original, binaryFormat); err != nil {
+            t.Errorf("binary %v: encoding: %v", binaryFormat, err)
+            continue
+        }
+
+        m, err := ParseSTL(&buf)
+        if err != nil {
+            t.Errorf("binary %v: parsing: %v", binaryFormat, err)
+            continue
+        }
+
+        if m.Name != original.Name {
+            t.Errorf("binary %v: name %q, expected %q", binaryFormat, m.Name, original.Name)
+        }
+
+        if m.TriangleCount() != original.TriangleCount() {
+            t.Errorf("binary %v: read %d triangles, expected %d", binaryFormat, m.TriangleCount(),
+                original.TriangleCount())
+            continue
+        }
+
+        // the faces of a 2x2 segment cube share the 26 distinct positions on its surface
+        if len(m.Positions) != 26 {
+            t.Errorf("binary %v: welded into %d vertices, expected 26", binaryFormat, len(m.Positions))
+        }
+
+        for i := 0; i < m.TriangleCount(); i++ {
+            a0, a1, a2 := original.Triangle(i)
+            b0, b1, b2 := m.Triangle(i)
+
+            if m.Positions[b0] != original.Positions[a0] || m.Positions[b1] != original.Positions[a1] ||
+                m.Positions[b2] != original.Positions[a2] {
+
+                t.Errorf("binary %v: triangle %d differs", binaryFormat, i)
+                break
+            }
+        }
+
+        // the normals are recomputed from the welded faces
+        if len(m.Normals) != len(m.Positions) {
+            t.Errorf("binary %v: %d normals for %d positions", binaryFormat, len(m.Normals), len(m.Positions))
+        }
+    }
+}
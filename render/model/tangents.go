@@ -0,0 +1,123 @@
+package model
+
+import (
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// Computes tangents for normal mapping in the style of MikkTSpace: face tangents are projected into the plane of each
+// vertex normal and averaged weighted by the angle of the corner, the handedness comes from the winding of the
+// triangle in texture space and vertices shared by mirrored and unmirrored triangles are split. This isn't a port of
+// MikkTSpace and the results aren't identical, so normal maps baked against it can show faint seams on curved
+// surfaces. Normals are computed first when the mesh has none, and texture coordinates are required.
+func (m *Mesh) ComputeTangents() error {
+    if len(m.TexCoords) != len(m.Positions) || len(m.Positions) == 0 {
+        return fmt.Errorf("tangents need texture coordinates for every vertex")
+    }
+
+    if len(m.Normals) != len(m.Positions) {
+        m.ComputeNormals()
+    }
+
+    // the texture space directions and mirroring of each face
+    triangles := m.TriangleCount()
+    faceTangents := make([]mgl32.Vec3, triangles)
+    mirrored := make([]bool, triangles)
+
+    for i := range faceTangents {
+        i0, i1, i2 := m.Triangle(i)
+        e1, e2 := m.Positions[i1].Sub(m.Positions[i0]), m.Positions[i2].Sub(m.Positions[i0])
+        d1, d2 := m.TexCoords[i1].Sub(m.TexCoords[i0]), m.TexCoords[i2].Sub(m.TexCoords[i0])
+
+        area := d1[0]*d2[1] - d1[1]*d2[0]
+        mirrored[i] = area < 0
+
+        // triangles with no area in texture space don't contribute a direction
+        if area == 0 {
+            continue
+        }
+
+        faceTangents[i] = e1.Mul(d2[1]).Sub(e2.Mul(d1[1])).Mul(1 / area)
+    }
+
+    // corners are grouped by vertex and mirroring, a vertex used both ways becoming two
+    type group struct {
+        vertex   uint32
+        mirrored bool
+    }
+
+    original := make([]uint32, len(m.Indices))
+    copy(original, m.Indices)
+
+    vertices := make(map[group]uint32, len(m.Positions))
+    sources := make([]uint32, 0, len(m.Positions))
+    sums := make([]mgl32.Vec3, 0, len(m.Positions))
+
+    for i, index := range original {
+        face, corner := i/3, i%3
+        key := group{index, mirrored[face]}
+
+        vertex, ok := vertices[key]
+        if !ok {
+            vertex = uint32(len(sources))
+            vertices[key] = vertex
+            sources = append(sources, index)
+            sums = append(sums, mgl32.Vec3{})
+        }
+
+        m.Indices[i] = vertex
+
+        n := m.Normals[index]
+        t := projectToPlane(faceTangents[face], n)
+        if t.Len() == 0 {
+            continue
+        }
+
+        // the angle of the corner measured between its edges in the plane of the normal
+        p := m.Positions[index]
+        next := m.Positions[original[face*3+(corner+1)%3]]
+        prev := m.Positions[original[face*3+(corner+2)%3]]
+
+        a, b := projectToPlane(next.Sub(p), n), projectToPlane(prev.Sub(p), n)
+        if a.Len() == 0 || b.Len() == 0 {
+            continue
+        }
+
+        cos := mgl32.Clamp(a.Normalize().Dot(b.Normalize()), -1, 1)
+        angle := float32(math.Acos(float64(cos)))
+
+        sums[vertex] = sums[vertex].Add(t.Normalize().Mul(angle))
+    }
+
+    m.remap(sources)
+
+    m.Tangents = make([]mgl32.Vec4, len(sources))
+    for key, vertex := range vertices {
+        n := m.Normals[vertex]
+
+        t := projectToPlane(sums[vertex], n)
+        if t.Len() == 0 {
+            // any direction perpendicular to the normal will do when the texture gives none
+            t = projectToPlane(mgl32.Vec3{1, 0, 0}, n)
+            if t.Len() == 0 {
+                t = projectToPlane(mgl32.Vec3{0, 1, 0}, n)
+            }
+        }
+        t = t.Normalize()
+
+        w := float32(1)
+        if key.mirrored {
+            w = -1
+        }
+
+        m.Tangents[vertex] = t.Vec4(w)
+    }
+
+    return nil
+}
+
+// the part of v perpendicular to the unit normal n
+func projectToPlane(v, n mgl32.Vec3) mgl32.Vec3 {
+    return v.Sub(n.Mul(n.Dot(v)))
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "testing"
+)
+
+func TestComputeTangents(t *testing.T) {
+    // two quads side by side, the right one with its texture mirrored in u
+    m := &Mesh{
+        Positions: []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}, {2, 0, 0}, {2, 1, 0}},
+        TexCoords: []mgl32.Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}, {0, 1}},
+        Indices:   []uint32{0, 1, 2, 0, 2, 3, 1, 4, 5, 1, 5, 2},
+    }
+
+    if err := m.ComputeTangents(); err != nil {
+        t.Fatal(err)
+    }
+
+    // the shared edge is used both ways, so its two vertices are split
+    if len(m.Positions) != 8 {
+        t.Errorf("%d vertices, expected 8", len(m.Positions))
+    }
+
+    for i := 0; i < m.TriangleCount(); i++ {
+        expected := mgl32.Vec4{1, 0, 0, 1}
+        if i >= 2 {
+            expected = mgl32.Vec4{-1, 0, 0, -1}
+        }
+
+        i0, i1, i2 := m.Triangle(i)
+        for _, index := range []uint32{i0, i1, i2} {
+            if m.Tangents[index].Sub(expected).Len() > 1e-5 {
+                t.Errorf("triangle %d: vertex %d has tangent %v, expected %v", i, index, m.Tangents[index], expected)
+            }
+        }
+    }
+}
+
+func TestComputeTangentsOrthogonal(t *testing.T) {
+    m := UVSphere(1, 16, 8)
+    if err := m.ComputeTangents(); err != nil {
+        t.Fatal(err)
+    }
+
+    for i, tangent := range m.Tangents {
+        if l := tangent.Vec3().Len(); l < 0.999 || l > 1.001 {
+            t.Errorf("tangent %d has length %v", i, l)
+        }
+
+        if d := tangent.Vec3().Dot(m.Normals[i]); d > 1e-4 || d < -1e-4 {
+            t.Errorf("tangent %d isn't perpendicular to its normal: dot = %v", i, d)
+        }
+    }
+}
+
+func BenchmarkComputeTangents(b *testing.B) {
+    for i := 0; i < b.N; i++ {
+        // a fresh mesh every time, as the work is done in place
+        b.StopTimer()
+        m := UVSphere(1, 128, 64)
+        b.StartTimer()
+
+        if err := m.ComputeTangents(); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
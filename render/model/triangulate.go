@@ -0,0 +1,149 @@
+package model
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// Triangulates a simple (possibly concave) planar polygon by ear clipping, returning index triples into the
+// polygon's vertices that keep the polygon's winding. Degenerate polygons fall back to a triangle fan.
+func Triangulate(polygon []mgl32.Vec3) [][3]int {
+    n := len(polygon)
+    if n < 3 {
+        return nil
+    }
+
+    if n == 3 {
+        return [][3]int{{0, 1, 2}}
+    }
+
+    // project onto the plane of the polygon by dropping the axis its normal is most aligned with
+    normal := newellNormal(polygon)
+    ax, ay := projectionAxes(normal)
+
+    points := make([]mgl32.Vec2, n)
+    for i, p := range polygon {
+        points[i] = mgl32.Vec2{p[ax], p[ay]}
+    }
+
+    // work on a counter clockwise outline, keeping the original indices
+    remaining := make([]int, n)
+    for i := range remaining {
+        remaining[i] = i
+    }
+
+    ccw := signedArea(points) > 0
+
+    triangles := make([][3]int, 0, n-2)
+    for len(remaining) > 3 {
+        ear := -1
+
+        for i := range remaining {
+            prev := remaining[(i+len(remaining)-1)%len(remaining)]
+            cur := remaining[i]
+            next := remaining[(i+1)%len(remaining)]
+
+            if isEar(points, remaining, prev, cur, next, ccw) {
+                ear = i
+                triangles = append(triangles, [3]int{prev, cur, next})
+                break
+            }
+        }
+
+        if ear == -1 {
+            // no ear found - the outline is degenerate or self intersecting, fan what is left
+            for i := 1; i < len(remaining)-1; i++ {
+                triangles = append(triangles, [3]int{remaining[0], remaining[i], remaining[i+1]})
+            }
+            return triangles
+        }
+
+        remaining = append(remaining[:ear], remaining[ear+1:]...)
+    }
+
+    return append(triangles, [3]int{remaining[0], remaining[1], remaining[2]})
+}
+
+// the polygon normal by Newell's method, robust for concave and slightly non planar polygons
+func newellNormal(polygon []mgl32.Vec3) mgl32.Vec3 {
+    var normal mgl32.Vec3
+
+    for i, cur := range polygon {
+        next := polygon[(i+1)%len(polygon)]
+
+        normal[0] += (cur[1] - next[1]) * (cur[2] + next[2])
+        normal[1] += (cur[2] - next[2]) * (cur[0] + next[0])
+        normal[2] += (cur[0] - next[0]) * (cur[1] + next[1])
+    }
+
+    return normal
+}
+
+// the two axes to keep when projecting along the given normal
+func projectionAxes(normal mgl32.Vec3) (int, int) {
+    x, y, z := math.Abs(float64(normal[0])), math.Abs(float64(normal[1])), math.Abs(float64(normal[2]))
+
+    switch {
+    case x >= y && x >= z:
+        return 1, 2
+    case y >= z:
+        return 2, 0
+    default:
+        return 0, 1
+    }
+}
+
+// twice the signed area of the outline, positive when counter clockwise
+func signedArea(points []mgl32.Vec2) float32 {
+    var area float32
+
+    for i, cur := range points {
+        next := points[(i+1)%len(points)]
+        area += cur[0]*next[1] - next[0]*cur[1]
+    }
+
+    return area
+}
+
+// the z component of (b - a) x (c - a)
+func cross2(a, b, c mgl32.Vec2) float32 {
+    return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// reports whether the corner at cur is convex and no other remaining vertex lies within the triangle it forms
+func isEar(points []mgl32.Vec2, remaining []int, prev, cur, next int, ccw bool) bool {
+    a, b, c := points[prev], points[cur], points[next]
+
+    turn := cross2(a, b, c)
+    if !ccw {
+        turn = -turn
+    }
+
+    if turn <= 0 {
+        return false
+    }
+
+    for _, i := range remaining {
+        if i == prev || i == cur || i == next {
+            continue
+        }
+
+        if insideTriangle(points[i], a, b, c) {
+            return false
+        }
+    }
+
+    return true
+}
+
+// reports whether p lies within (or on the edge of) the triangle abc of either winding
+func insideTriangle(p, a, b, c mgl32.Vec2) bool {
+    d1 := cross2(a, b, p)
+    d2 := cross2(b, c, p)
+    d3 := cross2(c, a, p)
+
+    negative := d1 < 0 || d2 < 0 || d3 < 0
+    positive := d1 > 0 || d2 > 0 || d3 > 0
+
+    return !(negative && positive)
+}
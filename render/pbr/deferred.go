@@ -0,0 +1,361 @@
+package pbr
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "logl/render"
+    "logl/render/model"
+    "math"
+)
+
+// Deferred is a deferred renderer for scenes with many lights, an alternative to shading every object for every
+// light in Window.Render. Opaque objects write their surfaces to a render.GBuffer once, then each light shades only
+// the pixels inside its volume - a sphere of the light's range for point and spot lights, the whole screen for
+// directional lights and lights without a range. Transparent objects are forward shaded afterwards. An application
+// chooses between the two paths by what its Window.Render callback draws - either objects directly, or a frame with
+// Deferred.Render.
+type Deferred struct {
+    // image based ambient light, nil to use the flat Ambient colour alone
+    Environment *render.Environment
+
+    // linear ambient light added to every surface
+    Ambient render.Color
+
+    // scales the light before tone mapping
+    Exposure float32
+
+    gbuffer *render.GBuffer
+
+    light   *render.Material
+    ambient *render.Material
+
+    // a sphere enclosing the unit sphere, and the radius its faces reach in to
+    sphere       *render.Mesh
+    sphereRadius float32
+
+    // removes the window resize listener
+    detach func()
+}
+
+// a frame drawn by a Deferred renderer
+type DeferredFrame struct {
+    View       mgl32.Mat4
+    Projection mgl32.Mat4
+
+    // the world space position of the viewer
+    ViewPosition mgl32.Vec3
+
+    // any number of lights, each costing only the pixels it reaches
+    Lights []*Light
+
+    // draws the opaque and alpha masked objects, with programs built with PBRGLSL, render.GBufferGLSL and
+    // DeferredGLSL and bound with Material.BindGeometry
+    Opaque func() error
+
+    // draws the blended objects back to front with forward shading (programs built with PBRGLSL, bound with
+    // Material.Bind and lit with UploadLights), writing linear colour without tone mapping. Nil when there are none.
+    Transparent func() error
+}
+
+// Creates a deferred renderer with a G-buffer the size of the window's framebuffer, kept in step as it resizes
+func NewDeferred(win *render.Window) (*Deferred, error) {
+    gbuffer, err := render.NewGBuffer(win.Width, win.Height)
+    if err != nil {
+        return nil, err
+    }
+
+    d := &Deferred{
+        Ambient:  render.Black,
+        Exposure: 1,
+        gbuffer:  gbuffer,
+    }
+
+    lightProg, err := compile(lightPassSource)
+    if err != nil {
+        d.Delete()
+        return nil, err
+    }
+    d.light = render.NewMaterial(lightProg)
+    gbuffer.SetTextures(d.light)
+
+    ambientProg, err := compile(ambientPassSource)
+    if err != nil {
+        d.Delete()
+        return nil, err
+    }
+    d.ambient = render.NewMaterial(ambientProg)
+    gbuffer.SetTextures(d.ambient)
+
+    sphere := model.Icosphere(1, 1)
+    d.sphere = render.NewMesh(sphere)
+    d.sphereRadius = inradius(sphere)
+
+    d.detach = win.OnResize(func(width, height int32) {
+        if d.gbuffer != nil {
+            d.gbuffer.Resize(width, height)
+        }
+    })
+
+    return d, nil
+}
+
+// compiles a lighting pass from the body of its fragment shader, which follows PBRGLSL and render.GBufferReadGLSL
+func compile(body string) (*render.Program, error) {
+    vsh, err := render.NewShader(render.VertexShader, render.LightVolumeVertexSource)
+    if err != nil {
+        return nil, err
+    }
+    defer vsh.Delete()
+
+    source := "#version 330 core\n" + PBRGLSL + render.GBufferReadGLSL + body + "\x00"
+    fsh, err := render.NewShader(render.FragmentShader, source)
+    if err != nil {
+        return nil, err
+    }
+    defer fsh.Delete()
+
+    return render.NewProgram(vsh, fsh)
+}
+
+// the distance from the centre of a convex mesh around the origin to its nearest face
+func inradius(mesh *model.Mesh) float32 {
+    radius := float32(math.MaxFloat32)
+
+    for i := 0; i < mesh.TriangleCount(); i++ {
+        a, b, c := mesh.Triangle(i)
+        p0, p1, p2 := mesh.Positions[a], mesh.Positions[b], mesh.Positions[c]
+
+        normal := p1.Sub(p0).Cross(p2.Sub(p0)).Normalize()
+        if d := normal.Dot(p0); d < radius {
+            radius = d
+        }
+    }
+
+    return radius
+}
+
+// the G-buffer, for inspecting its attachments
+func (d *Deferred) GBuffer() *render.GBuffer {
+    return d.gbuffer
+}
+
+// Draws a frame into the bound framebuffer - the opaque objects, their lighting and then the transparent objects -
+// and copies the scene depth across for anything drawn after. Call this from the Window.Render callback.
+func (d *Deferred) Render(frame *DeferredFrame) error {
+    d.gbuffer.BeginGeometry()
+
+    err := d.draw(frame)
+
+    if presentErr := d.gbuffer.Present(d.Exposure); err == nil {
+        err = presentErr
+    }
+
+    return err
+}
+
+func (d *Deferred) draw(frame *DeferredFrame) error {
+    if err := frame.Opaque(); err != nil {
+        return err
+    }
+
+    d.gbuffer.BeginLighting()
+
+    if err := d.drawAmbient(frame); err != nil {
+        return err
+    }
+
+    if err := d.drawLights(frame); err != nil {
+        return err
+    }
+
+    if frame.Transparent == nil {
+        return nil
+    }
+
+    d.gbuffer.BeginForward()
+
+    return frame.Transparent()
+}
+
+func (d *Deferred) drawAmbient(frame *DeferredFrame) error {
+    initDefaults()
+
+    intensity := float32(0)
+    levels := int32(1)
+
+    if env := d.Environment; env != nil {
+        d.ambient.SetTexture("irradianceMap", env.Irradiance)
+        d.ambient.SetTexture("prefilterMap", env.Prefiltered)
+        d.ambient.SetTexture("brdfLUT", env.BRDF)
+        intensity, levels = 1, env.PrefilterLevels
+    } else {
+        d.ambient.SetTexture("irradianceMap", defaults.blackCube)
+        d.ambient.SetTexture("prefilterMap", defaults.blackCube)
+        d.ambient.SetTexture("brdfLUT", defaults.black)
+    }
+
+    if err := d.ambient.Bind(); err != nil {
+        return err
+    }
+
+    prog := d.ambient.Program()
+
+    u := prog.Uniforms()
+    u.Vec3("viewPos", frame.ViewPosition)
+    u.Vec3("ambient", d.Ambient.Vec3())
+    u.Float("environmentIntensity", intensity)
+    u.Float("prefilterLevels", float32(levels))
+    if u.Err() != nil {
+        return u.Err()
+    }
+
+    return d.gbuffer.DrawFullscreen(prog)
+}
+
+func (d *Deferred) drawLights(frame *DeferredFrame) error {
+    if len(frame.Lights) == 0 {
+        return nil
+    }
+
+    if err := d.light.Bind(); err != nil {
+        return err
+    }
+
+    prog := d.light.Program()
+    viewProjection := frame.Projection.Mul4(frame.View)
+
+    if err := prog.Vec3("viewPos", frame.ViewPosition); err != nil {
+        return err
+    }
+
+    for _, light := range frame.Lights {
+        u := prog.Uniforms()
+        uploadLight(u, "lights[0].", light)
+        if u.Err() != nil {
+            return u.Err()
+        }
+
+        if light.Type == Directional || light.Range <= 0 {
+            if err := d.gbuffer.DrawFullscreen(prog); err != nil {
+                return err
+            }
+            continue
+        }
+
+        scale := light.Range / d.sphereRadius
+        p := light.Position
+        transform := viewProjection.Mul4(mgl32.Translate3D(p[0], p[1], p[2])).Mul4(mgl32.Scale3D(scale, scale, scale))
+
+        if err := d.gbuffer.DrawVolume(prog, d.sphere, transform); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// deletes the G-buffer, programs and light volume
+func (d *Deferred) Delete() {
+    if d.detach != nil {
+        d.detach()
+        d.detach = nil
+    }
+
+    if d.gbuffer != nil {
+        d.gbuffer.Delete()
+        d.gbuffer = nil
+    }
+
+    if d.light != nil {
+        d.light.Program().Delete()
+    }
+
+    if d.ambient != nil {
+        d.ambient.Program().Delete()
+    }
+
+    if d.sphere != nil {
+        d.sphere.Delete()
+    }
+}
+
+// adds the light of lights[0] reflected by the surface
+const lightPassSource = `
+out vec4 FragColor;
+
+void main()
+{
+    Surface s;
+    if (!readGBuffer(s)) {
+        discard;
+    }
+
+    vec3 v = normalize(viewPos - s.position);
+    vec3 f0 = mix(vec3(0.04), s.albedo, s.metallic);
+
+    vec3 l;
+    vec3 radiance = lightRadiance(lights[0], s.position, l);
+
+    FragColor = vec4(pbrDirect(s.normal, v, l, radiance, s.albedo, s.metallic, s.roughness, f0), 1.0);
+}
+`
+
+// adds the flat ambient light and the image based lighting, as pbrShade does
+const ambientPassSource = `
+out vec4 FragColor;
+
+uniform vec3 ambient;
+
+void main()
+{
+    Surface s;
+    if (!readGBuffer(s)) {
+        discard;
+    }
+
+    vec3 color = ambient * s.albedo * s.occlusion;
+
+    if (environmentIntensity > 0.0) {
+        vec3 v = normalize(viewPos - s.position);
+        float nDotV = max(dot(s.normal, v), 1e-4);
+
+        vec3 f0 = mix(vec3(0.04), s.albedo, s.metallic);
+        vec3 f = fresnelSchlickRoughness(nDotV, f0, s.roughness);
+        vec3 kD = (1.0 - f) * (1.0 - s.metallic);
+
+        vec3 diffuse = texture(irradianceMap, s.normal).rgb * s.albedo;
+
+        vec3 r = reflect(-v, s.normal);
+        vec3 prefiltered = textureLod(prefilterMap, r, s.roughness * (prefilterLevels - 1.0)).rgb;
+        vec2 brdf = texture(brdfLUT, vec2(nDotV, s.roughness)).rg;
+        vec3 specular = prefiltered * (f * brdf.x + brdf.y);
+
+        color += (kD * diffuse + specular) * s.occlusion * environmentIntensity;
+    }
+
+    FragColor = vec4(color, 1.0);
+}
+`
+
+// Fragment shader declarations for the geometry pass of a Deferred renderer, to paste after PBRGLSL and
+// render.GBufferGLSL. pbrWriteGBuffer() writes the surface of the material bound with Material.BindGeometry, taking
+// the same inputs as pbrShade.
+const DeferredGLSL = `
+void pbrWriteGBuffer(vec3 fragPos, vec3 normal, vec4 tangent, vec2 texCoord)
+{
+    vec4 baseColor = pbrBaseColor(texCoord);
+
+    if (material.alphaMode == ALPHA_MASK && baseColor.a < material.alphaCutoff) {
+        discard;
+    }
+
+    vec4 mr = texture(material.metallicRoughnessMap, texCoord);
+    float metallic = clamp(material.metallic * mr.b, 0.0, 1.0);
+    float roughness = clamp(material.roughness * mr.g, 0.04, 1.0);
+
+    float occlusion = mix(1.0, texture(material.occlusionMap, texCoord).r, material.occlusionStrength);
+    vec3 emissive = material.emissive * srgbToLinear(texture(material.emissiveMap, texCoord).rgb);
+
+    writeGBuffer(fragPos, pbrNormal(normal, tangent, texCoord), baseColor.rgb, metallic, roughness, occlusion,
+        emissive);
+}
+`
@@ -0,0 +1,235 @@
+package pbr
+
+// Fragment shader declarations for metallic-roughness shading, to paste after the #version line. It declares the
+// lights, material and environment uploaded by UploadLights and Material.Bind. pbrShade() gives the linear HDR
+// colour and alpha of a fragment from its world space position, interpolated normal, tangent (xyz and handedness
+// in w, all zero when the mesh has none) and texture coordinate, discarding it when alpha masked. The result wants
+// tone mapping (toneMapACES) and sRGB encoding (linearToSRGB) before display.
+const PBRGLSL = `
+#define MAX_LIGHTS 16
+
+#define LIGHT_DIRECTIONAL 0
+#define LIGHT_POINT 1
+#define LIGHT_SPOT 2
+
+#define ALPHA_OPAQUE 0
+#define ALPHA_MASK 1
+#define ALPHA_BLEND 2
+
+struct Light {
+    int type;
+    vec3 position;
+    vec3 direction;
+    vec3 color;
+    float intensity;
+    float range;
+    float innerCos;
+    float outerCos;
+};
+
+struct PBRMaterial {
+    vec4 baseColor;
+    float metallic;
+    float roughness;
+    float normalScale;
+    float occlusionStrength;
+    vec3 emissive;
+    int alphaMode;
+    float alphaCutoff;
+
+    sampler2D baseColorMap;
+    sampler2D metallicRoughnessMap;
+    sampler2D normalMap;
+    sampler2D occlusionMap;
+    sampler2D emissiveMap;
+};
+
+uniform Light lights[MAX_LIGHTS];
+uniform int lightCount;
+uniform vec3 viewPos;
+
+uniform PBRMaterial material;
+
+uniform samplerCube irradianceMap;
+uniform samplerCube prefilterMap;
+uniform sampler2D brdfLUT;
+uniform float prefilterLevels;
+uniform float environmentIntensity;
+
+const float PI = 3.14159265359;
+
+vec3 srgbToLinear(vec3 c)
+{
+    return mix(c / 12.92, pow((c + 0.055) / 1.055, vec3(2.4)), step(vec3(0.04045), c));
+}
+
+vec3 linearToSRGB(vec3 c)
+{
+    return mix(c * 12.92, 1.055 * pow(c, vec3(1.0 / 2.4)) - 0.055, step(vec3(0.0031308), c));
+}
+
+// the ACES filmic curve fitted by Krzysztof Narkowicz
+vec3 toneMapACES(vec3 c)
+{
+    return clamp((c * (2.51 * c + 0.03)) / (c * (2.43 * c + 0.59) + 0.14), 0.0, 1.0);
+}
+
+// Trowbridge-Reitz GGX normal distribution
+float distributionGGX(float nDotH, float roughness)
+{
+    float a = roughness * roughness;
+    float a2 = a * a;
+    float d = nDotH * nDotH * (a2 - 1.0) + 1.0;
+
+    return a2 / (PI * d * d);
+}
+
+// Smith's method with the Schlick-GGX approximation, k remapped for direct lighting
+float geometrySmith(float nDotV, float nDotL, float roughness)
+{
+    float r = roughness + 1.0;
+    float k = (r * r) / 8.0;
+
+    float ggxV = nDotV / (nDotV * (1.0 - k) + k);
+    float ggxL = nDotL / (nDotL * (1.0 - k) + k);
+
+    return ggxV * ggxL;
+}
+
+vec3 fresnelSchlick(float cosTheta, vec3 f0)
+{
+    return f0 + (1.0 - f0) * pow(clamp(1.0 - cosTheta, 0.0, 1.0), 5.0);
+}
+
+// Fresnel averaged over the roughness of the surface, for the image based lighting
+vec3 fresnelSchlickRoughness(float cosTheta, vec3 f0, float roughness)
+{
+    return f0 + (max(vec3(1.0 - roughness), f0) - f0) * pow(clamp(1.0 - cosTheta, 0.0, 1.0), 5.0);
+}
+
+// the base colour of the material at the texture coordinate, linear with alpha
+vec4 pbrBaseColor(vec2 texCoord)
+{
+    vec4 texel = texture(material.baseColorMap, texCoord);
+    return material.baseColor * vec4(srgbToLinear(texel.rgb), texel.a);
+}
+
+// the shading normal, perturbed by the normal map when the mesh has tangents
+vec3 pbrNormal(vec3 normal, vec4 tangent, vec2 texCoord)
+{
+    vec3 n = normalize(normal);
+    if (!gl_FrontFacing) {
+        n = -n;
+    }
+
+    if (dot(tangent.xyz, tangent.xyz) == 0.0) {
+        return n;
+    }
+
+    vec3 t = normalize(tangent.xyz - n * dot(n, tangent.xyz));
+    vec3 b = cross(n, t) * (tangent.w < 0.0 ? -1.0 : 1.0);
+
+    vec3 mapped = texture(material.normalMap, texCoord).xyz * 2.0 - 1.0;
+    mapped.xy *= material.normalScale;
+
+    return normalize(mat3(t, b, n) * mapped);
+}
+
+// the light reflected towards the viewer from one light arriving from direction l with the given radiance
+vec3 pbrDirect(vec3 n, vec3 v, vec3 l, vec3 radiance, vec3 albedo, float metallic, float roughness, vec3 f0)
+{
+    vec3 h = normalize(v + l);
+
+    float nDotL = max(dot(n, l), 0.0);
+    float nDotV = max(dot(n, v), 1e-4);
+
+    float d = distributionGGX(max(dot(n, h), 0.0), roughness);
+    float g = geometrySmith(nDotV, nDotL, roughness);
+    vec3 f = fresnelSchlick(max(dot(h, v), 0.0), f0);
+
+    vec3 specular = d * g * f / (4.0 * nDotV * nDotL + 1e-4);
+
+    // light reflected at the surface doesn't enter it to be scattered, and metals absorb what does
+    vec3 kD = (1.0 - f) * (1.0 - metallic);
+
+    return (kD * albedo / PI + specular) * radiance * nDotL;
+}
+
+// the radiance arriving from a light at the given position, and its direction
+vec3 lightRadiance(Light light, vec3 fragPos, out vec3 l)
+{
+    if (light.type == LIGHT_DIRECTIONAL) {
+        l = -light.direction;
+        return light.color * light.intensity;
+    }
+
+    vec3 toLight = light.position - fragPos;
+    float distance = length(toLight);
+    l = toLight / distance;
+
+    // inverse square with the smooth window of KHR_lights_punctual
+    float attenuation = 1.0 / max(distance * distance, 1e-4);
+    if (light.range > 0.0) {
+        float ratio = distance / light.range;
+        attenuation *= pow(clamp(1.0 - ratio * ratio * ratio * ratio, 0.0, 1.0), 2.0);
+    }
+
+    if (light.type == LIGHT_SPOT) {
+        float cd = dot(light.direction, -l);
+        attenuation *= smoothstep(light.outerCos, light.innerCos, cd);
+    }
+
+    return light.color * light.intensity * attenuation;
+}
+
+vec4 pbrShade(vec3 fragPos, vec3 normal, vec4 tangent, vec2 texCoord)
+{
+    vec4 baseColor = pbrBaseColor(texCoord);
+
+    if (material.alphaMode == ALPHA_MASK && baseColor.a < material.alphaCutoff) {
+        discard;
+    }
+
+    vec4 mr = texture(material.metallicRoughnessMap, texCoord);
+    float metallic = clamp(material.metallic * mr.b, 0.0, 1.0);
+    float roughness = clamp(material.roughness * mr.g, 0.04, 1.0);
+
+    vec3 albedo = baseColor.rgb;
+    vec3 n = pbrNormal(normal, tangent, texCoord);
+    vec3 v = normalize(viewPos - fragPos);
+
+    // dielectrics reflect about 4% head on, metals reflect their colour
+    vec3 f0 = mix(vec3(0.04), albedo, metallic);
+
+    vec3 color = vec3(0.0);
+    for (int i = 0; i < lightCount; i++) {
+        vec3 l;
+        vec3 radiance = lightRadiance(lights[i], fragPos, l);
+        color += pbrDirect(n, v, l, radiance, albedo, metallic, roughness, f0);
+    }
+
+    if (environmentIntensity > 0.0) {
+        float nDotV = max(dot(n, v), 1e-4);
+        vec3 f = fresnelSchlickRoughness(nDotV, f0, roughness);
+        vec3 kD = (1.0 - f) * (1.0 - metallic);
+
+        vec3 diffuse = texture(irradianceMap, n).rgb * albedo;
+
+        vec3 r = reflect(-v, n);
+        vec3 prefiltered = textureLod(prefilterMap, r, roughness * (prefilterLevels - 1.0)).rgb;
+        vec2 brdf = texture(brdfLUT, vec2(nDotV, roughness)).rg;
+        vec3 specular = prefiltered * (f * brdf.x + brdf.y);
+
+        float occlusion = mix(1.0, texture(material.occlusionMap, texCoord).r, material.occlusionStrength);
+
+        color += (kD * diffuse + specular) * occlusion * environmentIntensity;
+    }
+
+    vec3 emissive = material.emissive * srgbToLinear(texture(material.emissiveMap, texCoord).rgb);
+    color += emissive;
+
+    float alpha = material.alphaMode == ALPHA_BLEND ? baseColor.a : 1.0;
+
+    return vec4(color, alpha);
+}
+`
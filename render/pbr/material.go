@@ -0,0 +1,280 @@
+package pbr
+
+import (
+    "image"
+    "image/color"
+    "logl/render"
+    "logl/render/gltf"
+)
+
+// Material is a metallic-roughness material. The factors are linear and multiply the matching maps, nil maps
+// leaving the factors on their own. Base colour and emissive maps hold sRGB values, decoded in the shader.
+type Material struct {
+    Name string
+
+    BaseColor    render.Color
+    BaseColorMap render.BindableTexture
+
+    Metallic  float32
+    Roughness float32
+
+    // metalness in blue and roughness in green
+    MetallicRoughnessMap render.BindableTexture
+
+    // tangent space normals, needing mesh tangents. The scale applies to x and y.
+    NormalMap   render.BindableTexture
+    NormalScale float32
+
+    // ambient occlusion in red, applied to the image based lighting
+    OcclusionMap      render.BindableTexture
+    OcclusionStrength float32
+
+    Emissive    render.Color
+    EmissiveMap render.BindableTexture
+
+    AlphaMode   gltf.AlphaMode
+    AlphaCutoff float32
+    DoubleSided bool
+}
+
+// Returns an opaque material with the given factors and no maps
+func NewMaterial(baseColor render.Color, metallic, roughness float32) *Material {
+    return &Material{
+        BaseColor:         baseColor,
+        Metallic:          metallic,
+        Roughness:         roughness,
+        NormalScale:       1,
+        OcclusionStrength: 1,
+        Emissive:          render.Black,
+        AlphaCutoff:       0.5,
+    }
+}
+
+// placeholders bound for missing maps, created with the first material bound and deleted with the window
+var defaults struct {
+    white       *render.Texture
+    flatNormal  *render.Texture
+    black       *render.Texture
+    blackCube   *render.CubeMap
+    initialised bool
+}
+
+func solidTexture(c color.Color) *render.Texture {
+    img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+    img.Set(0, 0, c)
+
+    return render.NewTexture(img, render.TextureOpts{
+        WrapS:     render.Repeat,
+        WrapT:     render.Repeat,
+        MinFilter: render.Nearest,
+        MagFilter: render.Nearest,
+    })
+}
+
+func initDefaults() {
+    if defaults.initialised {
+        return
+    }
+
+    defaults.white = solidTexture(color.White)
+    defaults.flatNormal = solidTexture(color.RGBA{R: 128, G: 128, B: 255, A: 255})
+    defaults.black = solidTexture(color.Black)
+
+    var faces [6]image.Image
+    for i := range faces {
+        img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+        img.Set(0, 0, color.Black)
+        faces[i] = img
+    }
+
+    // equal 1x1 faces can't fail
+    defaults.blackCube, _ = render.NewCubeMap(faces, render.TextureOpts{
+        WrapS:     render.ClampToEdge,
+        WrapT:     render.ClampToEdge,
+        MinFilter: render.Nearest,
+        MagFilter: render.Nearest,
+    })
+
+    defaults.initialised = true
+
+    render.OnDestroy(func() {
+        defaults.white.Delete()
+        defaults.flatNormal.Delete()
+        defaults.black.Delete()
+        defaults.blackCube.Delete()
+        defaults.white, defaults.flatNormal, defaults.black, defaults.blackCube = nil, nil, nil, nil
+        defaults.initialised = false
+    })
+}
+
+func or(texture render.BindableTexture, fallback render.BindableTexture) render.BindableTexture {
+    if texture != nil {
+        return texture
+    }
+    return fallback
+}
+
+// Sets the maps and environment as textures of the target (a render.Material for a program built with PBRGLSL),
+// binds it and uploads the factors. A nil environment turns off image based lighting. Blending for AlphaBlend and
+// disabling face culling for DoubleSided materials is left to the caller, which knows how it orders its draws.
+func (m *Material) Bind(target *render.Material, env *render.Environment) error {
+    m.setTextures(target)
+
+    intensity := float32(0)
+    levels := int32(1)
+
+    if env != nil {
+        target.SetTexture("irradianceMap", env.Irradiance)
+        target.SetTexture("prefilterMap", env.Prefiltered)
+        target.SetTexture("brdfLUT", env.BRDF)
+        intensity, levels = 1, env.PrefilterLevels
+    } else {
+        target.SetTexture("irradianceMap", defaults.blackCube)
+        target.SetTexture("prefilterMap", defaults.blackCube)
+        target.SetTexture("brdfLUT", defaults.black)
+    }
+
+    if err := target.Bind(); err != nil {
+        return err
+    }
+
+    u := m.upload(target.Program())
+    u.Float("environmentIntensity", intensity)
+    u.Float("prefilterLevels", float32(levels))
+
+    return u.Err()
+}
+
+// Sets the maps as textures of the target, binds it and uploads the factors, for the geometry pass of a Deferred
+// renderer (a program built with PBRGLSL, render.GBufferGLSL and DeferredGLSL) which leaves the lighting for later
+func (m *Material) BindGeometry(target *render.Material) error {
+    m.setTextures(target)
+
+    if err := target.Bind(); err != nil {
+        return err
+    }
+
+    return m.upload(target.Program()).Err()
+}
+
+func (m *Material) setTextures(target *render.Material) {
+    initDefaults()
+
+    target.SetTexture("material.baseColorMap", or(m.BaseColorMap, defaults.white))
+    target.SetTexture("material.metallicRoughnessMap", or(m.MetallicRoughnessMap, defaults.white))
+    target.SetTexture("material.normalMap", or(m.NormalMap, defaults.flatNormal))
+    target.SetTexture("material.occlusionMap", or(m.OcclusionMap, defaults.white))
+    target.SetTexture("material.emissiveMap", or(m.EmissiveMap, defaults.white))
+}
+
+// uploads the factors to the program, which must be in use
+func (m *Material) upload(prog *render.Program) *render.UniformSetter {
+    u := prog.Uniforms()
+    u.Vec4("material.baseColor", m.BaseColor.Vec4())
+    u.Float("material.metallic", m.Metallic)
+    u.Float("material.roughness", m.Roughness)
+    u.Float("material.normalScale", m.NormalScale)
+    u.Float("material.occlusionStrength", m.OcclusionStrength)
+    u.Vec3("material.emissive", m.Emissive.Vec3())
+    u.Integer("material.alphaMode", int32(m.AlphaMode))
+    u.Float("material.alphaCutoff", m.AlphaCutoff)
+
+    return u
+}
+
+// MaterialSet holds the materials of a glTF document along with the textures uploaded for them
+type MaterialSet struct {
+    Materials []*Material
+
+    // the glTF default material, for primitives without one
+    Default *Material
+
+    textures []*render.Texture
+}
+
+// Converts the materials of a glTF document, uploading each texture they use once. Only the first set of texture
+// coordinates is supported, materials sampling with others fall back to it.
+func LoadMaterials(doc *gltf.Document) (*MaterialSet, error) {
+    set := &MaterialSet{Default: fromGLTF(gltf.DefaultMaterial(), nil)}
+
+    uploaded := make(map[int]*render.Texture)
+    var err error
+
+    texture := func(ref gltf.TextureRef) render.BindableTexture {
+        if ref.Texture < 0 || err != nil {
+            return nil
+        }
+
+        t, ok := uploaded[ref.Texture]
+        if !ok {
+            if t, err = doc.NewTexture(ref.Texture); err != nil {
+                return nil
+            }
+
+            uploaded[ref.Texture] = t
+            set.textures = append(set.textures, t)
+        }
+
+        return t
+    }
+
+    for _, def := range doc.Materials {
+        set.Materials = append(set.Materials, fromGLTF(def, texture))
+    }
+
+    if err != nil {
+        set.Delete()
+        return nil, err
+    }
+
+    return set, nil
+}
+
+func fromGLTF(def *gltf.Material, texture func(ref gltf.TextureRef) render.BindableTexture) *Material {
+    m := &Material{
+        Name:              def.Name,
+        BaseColor:         def.BaseColor,
+        Metallic:          def.Metallic,
+        Roughness:         def.Roughness,
+        NormalScale:       1,
+        OcclusionStrength: 1,
+        Emissive:          def.Emissive,
+        AlphaMode:         def.AlphaMode,
+        AlphaCutoff:       def.AlphaCutoff,
+        DoubleSided:       def.DoubleSided,
+    }
+
+    if texture == nil {
+        return m
+    }
+
+    m.BaseColorMap = texture(def.BaseColorTexture)
+    m.MetallicRoughnessMap = texture(def.MetallicRoughnessTexture)
+    m.EmissiveMap = texture(def.EmissiveTexture)
+
+    if m.NormalMap = texture(def.NormalTexture); m.NormalMap != nil {
+        m.NormalScale = def.NormalTexture.Scale
+    }
+
+    if m.OcclusionMap = texture(def.OcclusionTexture); m.OcclusionMap != nil {
+        m.OcclusionStrength = def.OcclusionTexture.Scale
+    }
+
+    return m
+}
+
+// the material for a primitive's material index, the default material for -1
+func (s *MaterialSet) Material(index int) *Material {
+    if index < 0 || index >= len(s.Materials) {
+        return s.Default
+    }
+    return s.Materials[index]
+}
+
+// deletes the textures uploaded for the materials
+func (s *MaterialSet) Delete() {
+    for _, t := range s.textures {
+        t.Delete()
+    }
+    s.textures = nil
+}
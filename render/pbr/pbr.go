@@ -0,0 +1,93 @@
+// Package pbr provides physically based shading with the metallic-roughness model used by glTF - a Cook-Torrance
+// specular BRDF with the GGX distribution, Smith geometry term and Schlick Fresnel, lit by punctual lights and by
+// an image based render.Environment. Programs are built with the PBRGLSL include.
+package pbr
+
+import (
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+    "logl/render"
+    "math"
+)
+
+// the size of the light array declared by PBRGLSL
+const MaxLights = 16
+
+// the kind of a punctual light
+type LightType int
+
+const (
+    Directional LightType = iota
+    Point
+    Spot
+)
+
+func (t LightType) String() string {
+    switch t {
+    case Directional:
+        return "Directional"
+    case Point:
+        return "Point"
+    case Spot:
+        return "Spot"
+    default:
+        return "Unknown"
+    }
+}
+
+// Light is a punctual light in the physical units of KHR_lights_punctual - directional lights give illuminance in
+// lux and point and spot lights luminous intensity in candela, falling off with the inverse square of distance.
+type Light struct {
+    Type LightType
+
+    // the world space position of point and spot lights
+    Position mgl32.Vec3
+
+    // the world space direction directional and spot lights shine in
+    Direction mgl32.Vec3
+
+    // linear colour
+    Color     render.Color
+    Intensity float32
+
+    // the distance at which point and spot lights are faded to nothing, 0 for no limit
+    Range float32
+
+    // the half angles of the spot light cones in radians, full strength inside the inner and nothing outside the outer
+    InnerCone float32
+    OuterCone float32
+}
+
+// Uploads the lights and the world space position of the viewer to the program, which must be in use
+func UploadLights(prog *render.Program, lights []*Light, viewPosition mgl32.Vec3) error {
+    if len(lights) > MaxLights {
+        return fmt.Errorf("too many lights: lights = %d, max = %d", len(lights), MaxLights)
+    }
+
+    u := prog.Uniforms()
+    u.Vec3("viewPos", viewPosition)
+    u.Integer("lightCount", int32(len(lights)))
+
+    for i, light := range lights {
+        uploadLight(u, fmt.Sprintf("lights[%d].", i), light)
+    }
+
+    return u.Err()
+}
+
+// uploads a light to the struct uniform with the given name prefix
+func uploadLight(u *render.UniformSetter, name string, light *Light) {
+    direction := light.Direction
+    if direction.Len() > 0 {
+        direction = direction.Normalize()
+    }
+
+    u.Integer(name+"type", int32(light.Type))
+    u.Vec3(name+"position", light.Position)
+    u.Vec3(name+"direction", direction)
+    u.Vec3(name+"color", light.Color.Vec3())
+    u.Float(name+"intensity", light.Intensity)
+    u.Float(name+"range", light.Range)
+    u.Float(name+"innerCos", float32(math.Cos(float64(light.InnerCone))))
+    u.Float(name+"outerCos", float32(math.Cos(float64(light.OuterCone))))
+}
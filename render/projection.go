@@ -0,0 +1,166 @@
+package render
+
+import (
+    "errors"
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Projection
+// --------------------------------------------------------------------------------------------------------
+
+// the kind of projection matrix produced
+type ProjectionKind int
+
+const (
+    // standard perspective with near and far clip planes
+    Perspective ProjectionKind = iota
+
+    // parallel projection Height world units tall, centred on the view axis
+    Orthographic
+
+    // perspective with the far plane at infinity and depth running from 1 at the near plane to 0 at infinity - see
+    // EnableReverseZ
+    ReverseZPerspective
+)
+
+func (k ProjectionKind) String() string {
+    switch k {
+    case Perspective:
+        return "Perspective"
+    case Orthographic:
+        return "Orthographic"
+    case ReverseZPerspective:
+        return "Reverse-Z Perspective"
+    default:
+        return "Unknown"
+    }
+}
+
+// Projection holds the settings of a projection and keeps its aspect ratio in step with the size of a window, so
+// the matrix never stretches when the window is resized
+type Projection struct {
+    Kind ProjectionKind
+
+    // the vertical field of view in degrees (perspective kinds)
+    FOV float32
+
+    // the visible height in world units (orthographic)
+    Height float32
+
+    // the clip plane distances - Far is ignored by ReverseZPerspective
+    Near float32
+    Far  float32
+
+    width  int32
+    height int32
+
+    // removes the resize listener, nil once detached
+    detach func()
+}
+
+// Creates a perspective projection following the size of the given window
+func NewPerspective(window *Window, fov, near, far float32) *Projection {
+    return newProjection(window, &Projection{Kind: Perspective, FOV: fov, Near: near, Far: far})
+}
+
+// Creates an orthographic projection showing the given height in world units, following the size of the window
+func NewOrthographic(window *Window, height, near, far float32) *Projection {
+    return newProjection(window, &Projection{Kind: Orthographic, Height: height, Near: near, Far: far})
+}
+
+// Creates an infinite reverse-Z perspective projection following the size of the given window
+func NewReverseZPerspective(window *Window, fov, near float32) *Projection {
+    return newProjection(window, &Projection{Kind: ReverseZPerspective, FOV: fov, Near: near})
+}
+
+func newProjection(window *Window, p *Projection) *Projection {
+    p.Resize(window.Width, window.Height)
+    p.detach = window.OnResize(p.Resize)
+
+    return p
+}
+
+// stops following the size of the window, call this when a projection is no longer needed so the window doesn't
+// keep it alive. The aspect ratio stays as it was and can still be set with Resize.
+func (p *Projection) Detach() {
+    if p.detach != nil {
+        p.detach()
+        p.detach = nil
+    }
+}
+
+// updates the viewport size the aspect ratio is taken from - called automatically on window resize
+func (p *Projection) Resize(width, height int32) {
+    p.width, p.height = width, height
+}
+
+// the viewport width divided by its height
+func (p *Projection) Aspect() float32 {
+    if p.height == 0 {
+        return 1
+    }
+
+    return float32(p.width) / float32(p.height)
+}
+
+// the projection matrix for the current settings and viewport size
+func (p *Projection) Matrix() Mat4 {
+    aspect := p.Aspect()
+
+    switch p.Kind {
+    case Orthographic:
+        halfHeight := p.Height / 2
+        halfWidth := halfHeight * aspect
+        return mgl32.Ortho(-halfWidth, halfWidth, -halfHeight, halfHeight, p.Near, p.Far)
+    case ReverseZPerspective:
+        return reverseZPerspective(mgl32.DegToRad(p.FOV), aspect, p.Near)
+    default:
+        return mgl32.Perspective(mgl32.DegToRad(p.FOV), aspect, p.Near, p.Far)
+    }
+}
+
+// an infinite perspective mapping the near plane to depth 1 and infinity to depth 0 (with a 0 to 1 clip range)
+func reverseZPerspective(fovy, aspect, near float32) Mat4 {
+    f := float32(1 / math.Tan(float64(fovy)/2))
+
+    return Mat4{
+        f / aspect, 0, 0, 0,
+        0, f, 0, 0,
+        0, 0, 0, -1,
+        0, 0, near, 0,
+    }
+}
+
+// Switches the depth range to 0..1 (GL 4.5 or ARB_clip_control), clears depth to 0 and tests with gl.GREATER, as
+// needed by ReverseZPerspective. Fails without changing any state when clip control is not available.
+func EnableReverseZ() error {
+    var major, minor int32
+    gl.GetIntegerv(gl.MAJOR_VERSION, &major)
+    gl.GetIntegerv(gl.MINOR_VERSION, &minor)
+
+    if major < 4 || (major == 4 && minor < 5) {
+        if !HasExtension("GL_ARB_clip_control") {
+            return errors.New("reverse-z requires GL 4.5 or GL_ARB_clip_control")
+        }
+    }
+
+    gl.ClipControl(gl.LOWER_LEFT, gl.ZERO_TO_ONE)
+    gl.ClearDepth(0)
+    gl.DepthFunc(gl.GREATER)
+
+    return nil
+}
+
+// an orthographic projection in pixels for 2D overlays - the origin is the top left of the framebuffer with y
+// increasing downwards
+func PixelOrtho(width, height int32) Mat4 {
+    return mgl32.Ortho(0, float32(width), float32(height), 0, -1, 1)
+}
+
+// the pixel space orthographic projection for the current size of the window
+func (w *Window) PixelOrtho() Mat4 {
+    return PixelOrtho(w.Width, w.Height)
+}
@@ -0,0 +1,129 @@
+package render
+
+import (
+    "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Quad Batching
+// --------------------------------------------------------------------------------------------------------
+
+// the most quads drawn by one draw call, so every vertex can be reached with 16 bit indices
+const maxBatchQuads = 16384
+
+// position xy, texture coordinate uv and colour rgba
+const quadVertexFloats = 8
+
+// quadBatch collects textured, coloured quads and draws them with as few draw calls as possible. The vertices are
+// laid out with the position at location 0, texture coordinates at location 2 and colour at location 4.
+type quadBatch struct {
+    vao *VertexArray
+    vbo *Buffer
+    ebo *Buffer
+
+    vertices []float32
+}
+
+func newQuadBatch() *quadBatch {
+    b := &quadBatch{
+        vao: NewVertexArray(),
+        vbo: NewBuffer(ArrayBuffer),
+        ebo: NewBuffer(ElementArrayBuffer),
+    }
+
+    // every batch shares the same two triangles per quad
+    indices := make([]uint16, maxBatchQuads*6)
+    for i := 0; i < maxBatchQuads; i++ {
+        v := uint16(i * 4)
+        copy(indices[i*6:], []uint16{v, v + 1, v + 2, v + 2, v + 3, v})
+    }
+
+    b.vao.Bind()
+    b.vbo.Data(maxBatchQuads*4*quadVertexFloats*4, nil, StreamDraw)
+    b.ebo.Data(len(indices)*2, indices, StaticDraw)
+
+    stride := int32(quadVertexFloats * 4)
+    gl.VertexAttribPointer(0, 2, gl.FLOAT, false, stride, gl.PtrOffset(0))
+    gl.EnableVertexAttribArray(0)
+    gl.VertexAttribPointer(2, 2, gl.FLOAT, false, stride, gl.PtrOffset(2*4))
+    gl.EnableVertexAttribArray(2)
+    gl.VertexAttribPointer(4, 4, gl.FLOAT, false, stride, gl.PtrOffset(4*4))
+    gl.EnableVertexAttribArray(4)
+
+    gl.BindVertexArray(0)
+
+    return b
+}
+
+// queues an axis aligned quad from its top left and bottom right corners and texture coordinates
+func (b *quadBatch) add(x0, y0, x1, y1, u0, v0, u1, v1 float32, c Color) {
+    b.addCorners([4]Vec2{{x0, y0}, {x0, y1}, {x1, y1}, {x1, y0}}, [4]Vec2{{u0, v0}, {u0, v1}, {u1, v1}, {u1, v0}}, c)
+}
+
+// queues a quad from its four corners in order around it, along with their texture coordinates
+func (b *quadBatch) addCorners(corners [4]Vec2, texCoords [4]Vec2, c Color) {
+    for i, p := range corners {
+        t := texCoords[i]
+        b.vertices = append(b.vertices, p[0], p[1], t[0], t[1], c.r, c.g, c.b, c.a)
+    }
+}
+
+// the number of quads queued
+func (b *quadBatch) len() int {
+    return len(b.vertices) / (4 * quadVertexFloats)
+}
+
+// draws the queued quads with the program in use and empties the batch
+func (b *quadBatch) flush() {
+    if len(b.vertices) == 0 {
+        return
+    }
+
+    b.vao.Bind()
+
+    for start := 0; start < b.len(); start += maxBatchQuads {
+        count := b.len() - start
+        if count > maxBatchQuads {
+            count = maxBatchQuads
+        }
+
+        chunk := b.vertices[start*4*quadVertexFloats : (start+count)*4*quadVertexFloats]
+
+        // orphan the previous contents so the driver needn't wait for draws still reading them
+        b.vbo.Data(maxBatchQuads*4*quadVertexFloats*4, nil, StreamDraw)
+        b.vbo.SubData(0, len(chunk)*4, chunk)
+
+        gl.DrawElements(gl.TRIANGLES, int32(count*6), gl.UNSIGNED_SHORT, nil)
+    }
+
+    gl.BindVertexArray(0)
+
+    glCheck("drawing quads")
+
+    b.vertices = b.vertices[:0]
+}
+
+func (b *quadBatch) delete() {
+    b.vao.Delete()
+    b.vbo.Delete()
+    b.ebo.Delete()
+}
+
+// the vertex shader for batched quads in the layout of quadBatch, transformed by the projection
+const quadVertexSource = `#version 330 core
+layout (location = 0) in vec2 aPos;
+layout (location = 2) in vec2 aTexCoord;
+layout (location = 4) in vec4 aColor;
+
+out vec2 texCoord;
+out vec4 color;
+
+uniform mat4 projection;
+
+void main()
+{
+    texCoord = aTexCoord;
+    color = aColor;
+    gl_Position = projection * vec4(aPos, 0.0, 1.0);
+}
+` + "\x00"
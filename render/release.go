@@ -0,0 +1,6 @@
+// +build !debug
+
+package render
+
+// set by building with -tags debug - enables leak reporting when the window is destroyed and gl debug output
+const debugBuild = false
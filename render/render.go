@@ -4,17 +4,40 @@ import (
     "fmt"
     "github.com/go-gl/gl/v3.3-core/gl"
     "github.com/go-gl/glfw/v3.3/glfw"
+    "os"
     "runtime"
 )
 
 // the renderer function used to handle the actual drawing
 type Renderer func()
 
+// listeners for framebuffer size changes
+type ResizeListener func(width, height int32)
+
+// a registered resize listener, identified so it can be removed
+type resizeEntry struct {
+    id       uint64
+    listener ResizeListener
+}
+
+// listeners for cursor movement, the position is in screen coordinates relative to the top left of the window
+type CursorListener func(x, y float64)
+
+// listeners for mouse wheel / touchpad scrolling
+type ScrollListener func(xoffset, yoffset float64)
+
 // holds the gl stuff together and will call the renderer repeatedly
 type Window struct {
     Width  int32
     Height int32
     win    *glfw.Window
+
+    resizeListeners []resizeEntry
+    nextListener    uint64
+    cursorListeners []CursorListener
+    scrollListeners []ScrollListener
+
+    debug *DebugDraw
 }
 
 // closes the window
@@ -26,8 +49,26 @@ func (w *Window) Close() {
     w.win.SetShouldClose(true)
 }
 
-// destroys the window and terminates the glfw instance
+// destroys the window and terminates the glfw instance. Objects cached by the render packages are deleted, then
+// every other gl object created through the render package that is still alive is released - in debug builds
+// (-tags debug) these are reported as leaks along with where they were created.
 func (w *Window) Destroy() {
+    // the window owns its debug drawing, so it isn't a leak of the caller's
+    if w.debug != nil {
+        w.debug.Delete()
+        w.debug = nil
+    }
+
+    resetCaches()
+
+    if debugBuild {
+        if leaks := ReportLeaks(os.Stderr); leaks > 0 {
+            fmt.Fprintf(os.Stderr, "render: %d gl objects were not deleted before the window was destroyed\n", leaks)
+        }
+    }
+
+    releaseAll()
+
     w.win.Destroy()
     glfw.Terminate()
 }
@@ -40,6 +81,69 @@ func (w *Window) IsPressed(key glfw.Key) bool {
     return w.win.GetKey(key) == glfw.Press
 }
 
+func (w *Window) IsMousePressed(button glfw.MouseButton) bool {
+    return w.win.GetMouseButton(button) == glfw.Press
+}
+
+// gets the cursor position in screen coordinates relative to the top left of the window
+func (w *Window) CursorPos() (float64, float64) {
+    return w.win.GetCursorPos()
+}
+
+// hides the cursor and locks it to the window, giving unbounded relative movement (for mouse look etc)
+func (w *Window) CaptureCursor(capture bool) {
+    if capture {
+        w.win.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+    } else {
+        w.win.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+    }
+}
+
+// reports whether the cursor is currently captured
+func (w *Window) IsCursorCaptured() bool {
+    return w.win.GetInputMode(glfw.CursorMode) == glfw.CursorDisabled
+}
+
+// the framebuffer width divided by its height
+func (w *Window) Aspect() float32 {
+    if w.Height == 0 {
+        return 1
+    }
+
+    return float32(w.Width) / float32(w.Height)
+}
+
+// registers a function called whenever the framebuffer is resized, after the viewport has been updated. The
+// returned function removes it again, so objects outliving their use don't keep being resized (or kept alive).
+func (w *Window) OnResize(listener ResizeListener) func() {
+    w.nextListener++
+    id := w.nextListener
+
+    w.resizeListeners = append(w.resizeListeners, resizeEntry{id, listener})
+
+    return func() {
+        for i, entry := range w.resizeListeners {
+            if entry.id != id {
+                continue
+            }
+
+            // copied rather than shifted in place, in case it is removed while the listeners are being called
+            w.resizeListeners = append(w.resizeListeners[:i:i], w.resizeListeners[i+1:]...)
+            return
+        }
+    }
+}
+
+// registers a function called whenever the cursor moves
+func (w *Window) OnCursorMove(listener CursorListener) {
+    w.cursorListeners = append(w.cursorListeners, listener)
+}
+
+// registers a function called whenever the mouse wheel or touchpad scrolls
+func (w *Window) OnScroll(listener ScrollListener) {
+    w.scrollListeners = append(w.scrollListeners, listener)
+}
+
 // gets the elapsed time since the window was created
 func (w *Window) Time() float64 {
     return glfw.GetTime()
@@ -50,6 +154,20 @@ func (w *Window) ClearColor(c Color) {
     gl.ClearColor(c.r, c.g, c.b, c.a)
 }
 
+// Returns the debug drawing of the window, created on first use. Shapes queued on it are drawn at the end of each
+// frame of Render, after the renderer.
+func (w *Window) DebugDraw() (*DebugDraw, error) {
+    if w.debug == nil {
+        debug, err := newDebugDraw()
+        if err != nil {
+            return nil, err
+        }
+        w.debug = debug
+    }
+
+    return w.debug, nil
+}
+
 // enters the render loop and will block the caller until exit
 func (w *Window) Render(render Renderer) {
     for !w.win.ShouldClose() {
@@ -61,6 +179,15 @@ func (w *Window) Render(render Renderer) {
         // render
         render()
 
+        if w.debug != nil {
+            if err := w.debug.Flush(w.PixelOrtho()); err != nil {
+                fmt.Fprintf(os.Stderr, "render: failed to draw debug shapes: error = %s\n", err)
+            }
+        }
+
+        // catches errors from gl calls made outside the render package
+        glCheck("rendering the frame")
+
         // update
         // swap and poll
         w.win.SwapBuffers()
@@ -97,6 +224,12 @@ func NewWindow(title string, resizable bool, width, height int32) (*Window, erro
         glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
     }
 
+    // a debug context lets the driver report errors and warnings as they happen
+    debugOutput := DebugOutputEnabled()
+    if debugOutput {
+        glfw.WindowHint(glfw.OpenGLDebugContext, glfw.True)
+    }
+
     if width < 1 {
         width = 800
     }
@@ -124,10 +257,17 @@ func NewWindow(title string, resizable bool, width, height int32) (*Window, erro
     version := gl.GoStr(gl.GetString(gl.VERSION))
     fmt.Printf("running with opengl: version = %s\n", version)
 
+    if debugOutput {
+        enableDebugOutput()
+    }
+
+    // the framebuffer can differ from the requested window size on high dpi displays
+    fbWidth, fbHeight := window.GetFramebufferSize()
+
     win := &Window{
         win:    window,
-        Width:  width,
-        Height: height,
+        Width:  int32(fbWidth),
+        Height: int32(fbHeight),
     }
 
     // custom handling of the window size changes
@@ -135,6 +275,22 @@ func NewWindow(title string, resizable bool, width, height int32) (*Window, erro
         gl.Viewport(0, 0, int32(width), int32(height))
         win.Width = int32(width)
         win.Height = int32(height)
+
+        for _, entry := range win.resizeListeners {
+            entry.listener(win.Width, win.Height)
+        }
+    })
+
+    window.SetCursorPosCallback(func(w *glfw.Window, x float64, y float64) {
+        for _, listener := range win.cursorListeners {
+            listener(x, y)
+        }
+    })
+
+    window.SetScrollCallback(func(w *glfw.Window, xoffset float64, yoffset float64) {
+        for _, listener := range win.scrollListeners {
+            listener(xoffset, yoffset)
+        }
     })
 
     return win, nil
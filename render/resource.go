@@ -0,0 +1,220 @@
+package render
+
+import (
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "io"
+    "os"
+    "runtime"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Resource tracking
+// --------------------------------------------------------------------------------------------------------
+
+// the kinds of gl object the render package creates
+type resourceKind int
+
+const (
+    textureResource resourceKind = iota
+    shaderResource
+    programResource
+    bufferResource
+    vertexArrayResource
+    samplerResource
+    framebufferResource
+    renderbufferResource
+)
+
+func (k resourceKind) String() string {
+    switch k {
+    case textureResource:
+        return "Texture"
+    case shaderResource:
+        return "Shader"
+    case programResource:
+        return "Program"
+    case bufferResource:
+        return "Buffer"
+    case vertexArrayResource:
+        return "Vertex Array"
+    case samplerResource:
+        return "Sampler"
+    case framebufferResource:
+        return "Framebuffer"
+    case renderbufferResource:
+        return "Renderbuffer"
+    default:
+        return "Unknown"
+    }
+}
+
+// a tracked gl object along with where it was created
+type resource struct {
+    id       uint64
+    kind     resourceKind
+    ptr      uint32
+    callers  []uintptr
+    released bool
+}
+
+// the stack trace of the code that created the object
+func (r *resource) stack() string {
+    var sb strings.Builder
+
+    frames := runtime.CallersFrames(r.callers)
+    for {
+        frame, more := frames.Next()
+        fmt.Fprintf(&sb, "    %s\n        %s:%d\n", frame.Function, frame.File, frame.Line)
+
+        if !more {
+            break
+        }
+    }
+
+    return sb.String()
+}
+
+// deletes the gl object and stops tracking it - safe to call more than once. Must be called on the gl thread.
+func (r *resource) release() {
+    registry.Lock()
+    if r.released {
+        registry.Unlock()
+        return
+    }
+
+    r.released = true
+    delete(registry.live, r.id)
+    registry.Unlock()
+
+    switch r.kind {
+    case textureResource:
+        gl.DeleteTextures(1, &r.ptr)
+    case shaderResource:
+        gl.DeleteShader(r.ptr)
+    case programResource:
+        gl.DeleteProgram(r.ptr)
+    case bufferResource:
+        gl.DeleteBuffers(1, &r.ptr)
+    case vertexArrayResource:
+        gl.DeleteVertexArrays(1, &r.ptr)
+    case samplerResource:
+        gl.DeleteSamplers(1, &r.ptr)
+    case framebufferResource:
+        gl.DeleteFramebuffers(1, &r.ptr)
+    case renderbufferResource:
+        gl.DeleteRenderbuffers(1, &r.ptr)
+    }
+}
+
+// every live gl object created through the render package. Finalizers run on their own goroutine so access is
+// guarded even though gl itself is single threaded.
+var registry = struct {
+    sync.Mutex
+    next uint64
+    live map[uint64]*resource
+}{
+    live: map[uint64]*resource{},
+}
+
+// Starts tracking a gl object owned by the given Go wrapper. If the wrapper is garbage collected before the object
+// is released a warning naming the creation site is written to stderr; the object itself stays registered (gl
+// calls cannot be made from the finalizer goroutine) and is released with the window.
+func track(owner interface{}, kind resourceKind, ptr uint32) *resource {
+    callers := make([]uintptr, 32)
+    callers = callers[:runtime.Callers(2, callers)]
+
+    registry.Lock()
+    registry.next++
+    r := &resource{
+        id:      registry.next,
+        kind:    kind,
+        ptr:     ptr,
+        callers: callers,
+    }
+    registry.live[r.id] = r
+    registry.Unlock()
+
+    runtime.SetFinalizer(owner, func(interface{}) {
+        registry.Lock()
+        released := r.released
+        registry.Unlock()
+
+        if !released {
+            fmt.Fprintf(os.Stderr, "render: %s %d garbage collected without Delete, created at:\n%s",
+                r.kind, r.ptr, r.stack())
+        }
+    })
+
+    return r
+}
+
+// the live objects in creation order
+func liveResources() []*resource {
+    registry.Lock()
+    defer registry.Unlock()
+
+    live := make([]*resource, 0, len(registry.live))
+    for _, r := range registry.live {
+        live = append(live, r)
+    }
+
+    sort.Slice(live, func(i, j int) bool {
+        return live[i].id < live[j].id
+    })
+
+    return live
+}
+
+// the number of gl objects created through the render package that have not been deleted
+func LiveResources() int {
+    registry.Lock()
+    defer registry.Unlock()
+
+    return len(registry.live)
+}
+
+// Writes every gl object that has not been deleted, with the stack trace of its creation, to the given writer and
+// returns how many were found
+func ReportLeaks(w io.Writer) int {
+    live := liveResources()
+
+    for _, r := range live {
+        fmt.Fprintf(w, "render: leaked %s %d, created at:\n%s", r.kind, r.ptr, r.stack())
+    }
+
+    return len(live)
+}
+
+// deletes every live gl object, newest first
+func releaseAll() {
+    live := liveResources()
+
+    for i := len(live) - 1; i >= 0; i-- {
+        live[i].release()
+    }
+}
+
+// functions deleting and forgetting gl objects cached in package variables, run when the window is destroyed
+var cacheResets []func()
+
+// Registers a function deleting gl objects cached in package variables (shared programs, placeholder textures and the
+// like) and setting the variables back to nil. The functions run newest first when the window is destroyed, before
+// leaks are reported, so the objects aren't reported and a later window creates them afresh. Register once each time
+// the cache is filled; must be called on the gl thread.
+func OnDestroy(reset func()) {
+    cacheResets = append(cacheResets, reset)
+}
+
+// runs and forgets the registered cache resets
+func resetCaches() {
+    resets := cacheResets
+    cacheResets = nil
+
+    for i := len(resets) - 1; i >= 0; i-- {
+        resets[i]()
+    }
+}
@@ -0,0 +1,175 @@
+package render
+
+import (
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Sampler
+// --------------------------------------------------------------------------------------------------------
+
+// depth comparison functions used by shadow samplers
+type CompareFunc uint32
+
+const (
+    CompareLessEqual    CompareFunc = gl.LEQUAL
+    CompareGreaterEqual CompareFunc = gl.GEQUAL
+    CompareLess         CompareFunc = gl.LESS
+    CompareGreater      CompareFunc = gl.GREATER
+    CompareEqual        CompareFunc = gl.EQUAL
+    CompareNotEqual     CompareFunc = gl.NOTEQUAL
+    CompareAlways       CompareFunc = gl.ALWAYS
+    CompareNever        CompareFunc = gl.NEVER
+)
+
+// options used when creating a sampler - zero valued wrap and filter fields keep the gl defaults
+type SamplerOpts struct {
+    WrapS     TextureWrap
+    WrapT     TextureWrap
+    WrapR     TextureWrap
+    MinFilter TextureFilter
+    MagFilter TextureFilter
+
+    // offset added to the computed mipmap level
+    LodBias float32
+
+    // enables depth comparison (sampler2DShadow etc) using CompareFunc, defaulting to CompareLessEqual
+    Compare     bool
+    CompareFunc CompareFunc
+
+    // the colour returned outside the texture when wrapping with ClampToBorder
+    BorderColor Color
+
+    // the anisotropic filtering level, values above 1 are clamped to MaxAnisotropy and ignored when the extension
+    // is not available
+    Anisotropy float32
+}
+
+// holds the sampler object reference - a sampler bound to a texture unit overrides the wrap and filter settings of
+// whatever texture is bound to that unit
+type Sampler struct {
+    ptr uint32
+    res *resource
+}
+
+// binds this sampler to the given texture unit
+func (s *Sampler) Bind(textureUnit TextureUnit) {
+    gl.BindSampler(uint32(textureUnit.index), s.ptr)
+}
+
+// deletes the underlying sampler
+func (s *Sampler) Delete() {
+    s.res.release()
+}
+
+// removes any sampler from the given texture unit so the texture's own parameters apply again
+func UnbindSampler(textureUnit TextureUnit) {
+    gl.BindSampler(uint32(textureUnit.index), 0)
+}
+
+// Creates a new sampler with the given options
+func NewSampler(opts SamplerOpts) (*Sampler, error) {
+    var sampler uint32
+    gl.GenSamplers(1, &sampler)
+
+    if sampler == 0 {
+        return nil, fmt.Errorf("failed to create sampler")
+    }
+
+    parami := func(name uint32, value uint32) {
+        if value != 0 {
+            gl.SamplerParameteri(sampler, name, int32(value))
+        }
+    }
+
+    parami(gl.TEXTURE_WRAP_S, uint32(opts.WrapS))
+    parami(gl.TEXTURE_WRAP_T, uint32(opts.WrapT))
+    parami(gl.TEXTURE_WRAP_R, uint32(opts.WrapR))
+    parami(gl.TEXTURE_MIN_FILTER, uint32(opts.MinFilter))
+    parami(gl.TEXTURE_MAG_FILTER, uint32(opts.MagFilter))
+
+    gl.SamplerParameterf(sampler, gl.TEXTURE_LOD_BIAS, opts.LodBias)
+
+    if opts.Compare {
+        compareFunc := opts.CompareFunc
+        if compareFunc == 0 {
+            compareFunc = CompareLessEqual
+        }
+
+        gl.SamplerParameteri(sampler, gl.TEXTURE_COMPARE_MODE, gl.COMPARE_REF_TO_TEXTURE)
+        gl.SamplerParameteri(sampler, gl.TEXTURE_COMPARE_FUNC, int32(compareFunc))
+    }
+
+    border := [4]float32{opts.BorderColor.r, opts.BorderColor.g, opts.BorderColor.b, opts.BorderColor.a}
+    gl.SamplerParameterfv(sampler, gl.TEXTURE_BORDER_COLOR, &border[0])
+
+    if opts.Anisotropy > 1 && AnisotropySupported() {
+        level := opts.Anisotropy
+        if max := MaxAnisotropy(); level > max {
+            level = max
+        }
+
+        gl.SamplerParameterf(sampler, gl.TEXTURE_MAX_ANISOTROPY, level)
+    }
+
+    s := &Sampler{ptr: sampler}
+    s.res = track(s, samplerResource, sampler)
+
+    return s, nil
+}
+
+// --------------------------------------------------------------------------------------------------------
+// Anisotropic filtering
+// --------------------------------------------------------------------------------------------------------
+
+// cached results of the anisotropy query - these need a current context so are filled on first use
+var anisotropy struct {
+    queried   bool
+    supported bool
+    max       float32
+}
+
+// reports whether anisotropic filtering is available via GL_EXT_texture_filter_anisotropic (or the equivalent ARB
+// extension / GL 4.6 core). Requires a current context.
+func AnisotropySupported() bool {
+    queryAnisotropy()
+    return anisotropy.supported
+}
+
+// the largest anisotropy level supported by the driver, 1 when anisotropic filtering is not available. Requires a
+// current context.
+func MaxAnisotropy() float32 {
+    queryAnisotropy()
+    return anisotropy.max
+}
+
+func queryAnisotropy() {
+    if anisotropy.queried {
+        return
+    }
+
+    anisotropy.queried = true
+    anisotropy.max = 1
+    anisotropy.supported = HasExtension("GL_EXT_texture_filter_anisotropic") ||
+        HasExtension("GL_ARB_texture_filter_anisotropic")
+
+    if anisotropy.supported {
+        gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &anisotropy.max)
+    }
+}
+
+// reports whether the current context advertises the named extension
+func HasExtension(name string) bool {
+    var count int32
+    gl.GetIntegerv(gl.NUM_EXTENSIONS, &count)
+
+    for i := int32(0); i < count; i++ {
+        if strings.EqualFold(gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i))), name) {
+            return true
+        }
+    }
+
+    return false
+}
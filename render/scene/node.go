@@ -0,0 +1,274 @@
+// Package scene provides a transform hierarchy - nodes carry a local position, rotation and scale relative to their
+// parent, and their world matrices are computed lazily and cached until something above them changes.
+package scene
+
+import (
+    "fmt"
+    "github.com/go-gl/mathgl/mgl32"
+)
+
+// Node is an element of the scene graph
+type Node struct {
+    Name string
+
+    // hidden nodes (and everything below them) are skipped by Walk
+    Visible bool
+
+    // arbitrary data attached by the application - typically what to draw for the node
+    Data interface{}
+
+    position mgl32.Vec3
+    rotation mgl32.Quat
+    scale    mgl32.Vec3
+
+    parent   *Node
+    children []*Node
+
+    // cached matrices, recomputed when flagged dirty. A node with a dirty world matrix always has dirty world
+    // matrices throughout its subtree.
+    local      mgl32.Mat4
+    world      mgl32.Mat4
+    localDirty bool
+    worldDirty bool
+}
+
+// Creates a visible node with the identity transform
+func NewNode(name string) *Node {
+    return &Node{
+        Name:       name,
+        Visible:    true,
+        rotation:   mgl32.QuatIdent(),
+        scale:      mgl32.Vec3{1, 1, 1},
+        local:      mgl32.Ident4(),
+        world:      mgl32.Ident4(),
+        localDirty: true,
+        worldDirty: true,
+    }
+}
+
+// ----------------------------------------------------------------------------------------------------
+// local transform
+// ----------------------------------------------------------------------------------------------------
+
+// the position relative to the parent
+func (n *Node) Position() mgl32.Vec3 {
+    return n.position
+}
+
+// the rotation relative to the parent
+func (n *Node) Rotation() mgl32.Quat {
+    return n.rotation
+}
+
+// the scale relative to the parent
+func (n *Node) Scale() mgl32.Vec3 {
+    return n.scale
+}
+
+func (n *Node) SetPosition(position mgl32.Vec3) {
+    n.position = position
+    n.invalidate()
+}
+
+// sets the rotation, which is normalised
+func (n *Node) SetRotation(rotation mgl32.Quat) {
+    n.rotation = rotation.Normalize()
+    n.invalidate()
+}
+
+func (n *Node) SetScale(scale mgl32.Vec3) {
+    n.scale = scale
+    n.invalidate()
+}
+
+// sets the same scale on every axis
+func (n *Node) SetUniformScale(scale float32) {
+    n.SetScale(mgl32.Vec3{scale, scale, scale})
+}
+
+// sets position, rotation and scale together
+func (n *Node) SetTransform(position mgl32.Vec3, rotation mgl32.Quat, scale mgl32.Vec3) {
+    n.position = position
+    n.rotation = rotation.Normalize()
+    n.scale = scale
+    n.invalidate()
+}
+
+// moves the node by the given offset in its parent's space
+func (n *Node) Translate(offset mgl32.Vec3) {
+    n.SetPosition(n.position.Add(offset))
+}
+
+// applies a further rotation in the node's own (local) space
+func (n *Node) Rotate(rotation mgl32.Quat) {
+    n.SetRotation(n.rotation.Mul(rotation))
+}
+
+// applies a further rotation of angle radians about the given axis in the node's own space
+func (n *Node) RotateAxis(angle float32, axis mgl32.Vec3) {
+    n.Rotate(mgl32.QuatRotate(angle, axis.Normalize()))
+}
+
+// the local transform - translation * rotation * scale, so scale applies first and translation last
+func (n *Node) LocalMatrix() mgl32.Mat4 {
+    if n.localDirty {
+        s := mgl32.Scale3D(n.scale.X(), n.scale.Y(), n.scale.Z())
+        r := n.rotation.Mat4()
+        t := mgl32.Translate3D(n.position.X(), n.position.Y(), n.position.Z())
+
+        n.local = t.Mul4(r).Mul4(s)
+        n.localDirty = false
+    }
+
+    return n.local
+}
+
+// ----------------------------------------------------------------------------------------------------
+// world transform
+// ----------------------------------------------------------------------------------------------------
+
+// the transform from the node's space to world space - the parent's world matrix * the local matrix
+func (n *Node) WorldMatrix() mgl32.Mat4 {
+    if n.worldDirty {
+        if n.parent == nil {
+            n.world = n.LocalMatrix()
+        } else {
+            n.world = n.parent.WorldMatrix().Mul4(n.LocalMatrix())
+        }
+
+        n.worldDirty = false
+    }
+
+    return n.world
+}
+
+// the position of the node's origin in world space
+func (n *Node) WorldPosition() mgl32.Vec3 {
+    return mgl32.TransformCoordinate(mgl32.Vec3{}, n.WorldMatrix())
+}
+
+// converts a point from the node's space to world space
+func (n *Node) ToWorld(point mgl32.Vec3) mgl32.Vec3 {
+    return mgl32.TransformCoordinate(point, n.WorldMatrix())
+}
+
+// converts a point from world space to the node's space
+func (n *Node) FromWorld(point mgl32.Vec3) mgl32.Vec3 {
+    return mgl32.TransformCoordinate(point, n.WorldMatrix().Inv())
+}
+
+// flags the local matrix and the world matrices of the subtree as needing recomputing
+func (n *Node) invalidate() {
+    n.localDirty = true
+    n.invalidateWorld()
+}
+
+func (n *Node) invalidateWorld() {
+    if n.worldDirty {
+        // the subtree is already dirty
+        return
+    }
+
+    n.worldDirty = true
+    for _, child := range n.children {
+        child.invalidateWorld()
+    }
+}
+
+// ----------------------------------------------------------------------------------------------------
+// hierarchy
+// ----------------------------------------------------------------------------------------------------
+
+// the parent node, nil for a root
+func (n *Node) Parent() *Node {
+    return n.parent
+}
+
+// the child nodes - the slice must not be modified
+func (n *Node) Children() []*Node {
+    return n.children
+}
+
+// Adds the child to this node, detaching it from any previous parent. The child keeps its local transform so its
+// world transform changes to follow the new parent. Fails if the child is this node or one of its ancestors.
+func (n *Node) AddChild(child *Node) error {
+    for a := n; a != nil; a = a.parent {
+        if a == child {
+            return fmt.Errorf("adding node would create a cycle: parent = %s, child = %s", n.Name, child.Name)
+        }
+    }
+
+    if child.parent != nil {
+        child.parent.RemoveChild(child)
+    }
+
+    child.parent = n
+    n.children = append(n.children, child)
+    child.invalidateWorld()
+
+    return nil
+}
+
+// Removes the child from this node, returning false if it was not a child
+func (n *Node) RemoveChild(child *Node) bool {
+    for i, c := range n.children {
+        if c != child {
+            continue
+        }
+
+        copy(n.children[i:], n.children[i+1:])
+        n.children[len(n.children)-1] = nil
+        n.children = n.children[:len(n.children)-1]
+
+        child.parent = nil
+        child.invalidateWorld()
+
+        return true
+    }
+
+    return false
+}
+
+// detaches the node from its parent
+func (n *Node) Detach() {
+    if n.parent != nil {
+        n.parent.RemoveChild(n)
+    }
+}
+
+// finds the first node with the given name in the subtree (including this node), depth first
+func (n *Node) Find(name string) *Node {
+    if n.Name == name {
+        return n
+    }
+
+    for _, child := range n.children {
+        if found := child.Find(name); found != nil {
+            return found
+        }
+    }
+
+    return nil
+}
+
+// ----------------------------------------------------------------------------------------------------
+// traversal
+// ----------------------------------------------------------------------------------------------------
+
+// visits a node along with its world matrix, returning false skips the node's children
+type Visitor func(node *Node, world mgl32.Mat4) bool
+
+// Visits the visible nodes of the subtree depth first, parents before children - use this to draw the scene
+func (n *Node) Walk(visit Visitor) {
+    if !n.Visible {
+        return
+    }
+
+    if !visit(n, n.WorldMatrix()) {
+        return
+    }
+
+    for _, child := range n.children {
+        child.Walk(visit)
+    }
+}
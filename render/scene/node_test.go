@@ -0,0 +1,212 @@
+package scene
+
+import (
+    "github.com/go-gl/mathgl/mgl32"
+    "testing"
+)
+
+func vecNear(a, b mgl32.Vec3) bool {
+    return a.Sub(b).Len() < 1e-5
+}
+
+// a root with a child and a grandchild, each offset by one along x
+func chain() (root, child, grandchild *Node) {
+    root, child, grandchild = NewNode("root"), NewNode("child"), NewNode("grandchild")
+    root.SetPosition(mgl32.Vec3{1, 0, 0})
+    child.SetPosition(mgl32.Vec3{1, 0, 0})
+    grandchild.SetPosition(mgl32.Vec3{1, 0, 0})
+
+    if err := root.AddChild(child); err != nil {
+        panic(err)
+    }
+
+    if err := child.AddChild(grandchild); err != nil {
+        panic(err)
+    }
+
+    return root, child, grandchild
+}
+
+func TestLocalMatrix(t *testing.T) {
+    n := NewNode("node")
+    n.SetTransform(mgl32.Vec3{1, 2, 3}, mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 0, 1}), mgl32.Vec3{2, 2, 2})
+
+    // scaled, then rotated, then translated
+    if p := n.ToWorld(mgl32.Vec3{1, 0, 0}); !vecNear(p, mgl32.Vec3{1, 4, 3}) {
+        t.Errorf("(1, 0, 0) transforms to %v, expected (1, 4, 3)", p)
+    }
+
+    if p := n.FromWorld(mgl32.Vec3{1, 4, 3}); !vecNear(p, mgl32.Vec3{1, 0, 0}) {
+        t.Errorf("(1, 4, 3) transforms back to %v, expected (1, 0, 0)", p)
+    }
+}
+
+func TestWorldMatrixFollowsAncestors(t *testing.T) {
+    root, child, grandchild := chain()
+
+    if p := grandchild.WorldPosition(); !vecNear(p, mgl32.Vec3{3, 0, 0}) {
+        t.Errorf("grandchild at %v, expected (3, 0, 0)", p)
+    }
+
+    // the cached matrices below a moved node are recomputed
+    root.Translate(mgl32.Vec3{0, 5, 0})
+    if p := grandchild.WorldPosition(); !vecNear(p, mgl32.Vec3{3, 5, 0}) {
+        t.Errorf("grandchild at %v after moving the root, expected (3, 5, 0)", p)
+    }
+
+    child.RotateAxis(mgl32.DegToRad(90), mgl32.Vec3{0, 0, 1})
+    if p := grandchild.WorldPosition(); !vecNear(p, mgl32.Vec3{2, 6, 0}) {
+        t.Errorf("grandchild at %v after rotating its parent, expected (2, 6, 0)", p)
+    }
+
+    root.SetUniformScale(2)
+    if p := grandchild.WorldPosition(); !vecNear(p, mgl32.Vec3{3, 7, 0}) {
+        t.Errorf("grandchild at %v after scaling the root, expected (3, 7, 0)", p)
+    }
+
+    // the world matrix of a node computed before its ancestor changed is also recomputed
+    child.WorldMatrix()
+    root.SetPosition(mgl32.Vec3{})
+    if p := child.WorldPosition(); !vecNear(p, mgl32.Vec3{2, 0, 0}) {
+        t.Errorf("child at %v after resetting the root, expected (2, 0, 0)", p)
+    }
+
+    if p := grandchild.WorldPosition(); !vecNear(p, mgl32.Vec3{2, 2, 0}) {
+        t.Errorf("grandchild at %v after resetting the root, expected (2, 2, 0)", p)
+    }
+}
+
+func TestReparent(t *testing.T) {
+    root, child, grandchild := chain()
+    other := NewNode("other")
+    other.SetPosition(mgl32.Vec3{0, 0, 10})
+
+    grandchild.WorldMatrix()
+
+    // the local transform is kept, so the world transform follows the new parent
+    if err := other.AddChild(grandchild); err != nil {
+        t.Fatalf("reparenting: %v", err)
+    }
+
+    if grandchild.Parent() != other || len(child.Children()) != 0 {
+        t.Errorf("reparented node still attached to its old parent")
+    }
+
+    if p := grandchild.WorldPosition(); !vecNear(p, mgl32.Vec3{1, 0, 10}) {
+        t.Errorf("reparented node at %v, expected (1, 0, 10)", p)
+    }
+
+    // a detached node becomes a root
+    grandchild.Detach()
+    if grandchild.Parent() != nil || len(other.Children()) != 0 {
+        t.Errorf("detached node still has a parent")
+    }
+
+    if p := grandchild.WorldPosition(); !vecNear(p, mgl32.Vec3{1, 0, 0}) {
+        t.Errorf("detached node at %v, expected (1, 0, 0)", p)
+    }
+
+    if root.RemoveChild(grandchild) {
+        t.Errorf("removed a node that isn't a child")
+    }
+
+    if !root.RemoveChild(child) || child.Parent() != nil || len(root.Children()) != 0 {
+        t.Errorf("child not removed")
+    }
+
+    if p := child.WorldPosition(); !vecNear(p, mgl32.Vec3{1, 0, 0}) {
+        t.Errorf("removed node at %v, expected (1, 0, 0)", p)
+    }
+}
+
+func TestAddChildCycles(t *testing.T) {
+    root, child, grandchild := chain()
+
+    tests := []struct {
+        name          string
+        parent, child *Node
+    }{
+        {"self", child, child},
+        {"parent", child, root},
+        {"grandparent", grandchild, root},
+    }
+
+    for _, test := range tests {
+        if err := test.parent.AddChild(test.child); err == nil {
+            t.Errorf("%s: cycle accepted", test.name)
+        }
+    }
+
+    // rejected additions leave the hierarchy alone
+    if root.Parent() != nil || child.Parent() != root || grandchild.Parent() != child {
+        t.Errorf("hierarchy changed by a rejected addition")
+    }
+}
+
+func TestFind(t *testing.T) {
+    root, _, grandchild := chain()
+
+    if found := root.Find("grandchild"); found != grandchild {
+        t.Errorf("found %v, expected the grandchild", found)
+    }
+
+    if found := grandchild.Find("root"); found != nil {
+        t.Errorf("found %q above the subtree", found.Name)
+    }
+}
+
+func TestWalk(t *testing.T) {
+    root, child, grandchild := chain()
+    sibling := NewNode("sibling")
+    if err := root.AddChild(sibling); err != nil {
+        t.Fatal(err)
+    }
+
+    walk := func(n *Node, visit Visitor) []string {
+        var names []string
+        n.Walk(func(node *Node, world mgl32.Mat4) bool {
+            if world != node.WorldMatrix() {
+                t.Errorf("%s: visited with a stale world matrix", node.Name)
+            }
+
+            names = append(names, node.Name)
+            return visit(node, world)
+        })
+
+        return names
+    }
+
+    all := func(*Node, mgl32.Mat4) bool { return true }
+
+    tests := []struct {
+        name     string
+        setup    func()
+        visit    Visitor
+        expected []string
+    }{
+        {"all", func() {}, all, []string{"root", "child", "grandchild", "sibling"}},
+        {"hidden child", func() { child.Visible = false }, all, []string{"root", "sibling"}},
+        {"hidden grandchild", func() { grandchild.Visible = false }, all, []string{"root", "child", "sibling"}},
+        {"hidden root", func() { root.Visible = false }, all, nil},
+        {"pruned", func() {}, func(node *Node, world mgl32.Mat4) bool { return node != child },
+            []string{"root", "child", "sibling"}},
+    }
+
+    for _, test := range tests {
+        root.Visible, child.Visible, grandchild.Visible = true, true, true
+        test.setup()
+
+        names := walk(root, test.visit)
+        if len(names) != len(test.expected) {
+            t.Errorf("%s: visited %v, expected %v", test.name, names, test.expected)
+            continue
+        }
+
+        for i := range names {
+            if names[i] != test.expected[i] {
+                t.Errorf("%s: visited %v, expected %v", test.name, names, test.expected)
+                break
+            }
+        }
+    }
+}
@@ -0,0 +1,790 @@
+package render
+
+import (
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "github.com/go-gl/mathgl/mgl32"
+    "math"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Shadow Maps
+// --------------------------------------------------------------------------------------------------------
+
+// the most cascades a directional shadow can be split into, and the size of the arrays declared by ShadowGLSL
+const MaxShadowCascades = 4
+
+// the number of point light shadows ShadowGLSL can sample
+const MaxPointShadows = 4
+
+// options used when creating a shadow map
+type ShadowOpts struct {
+    // the edge length in texels of each depth map (each cascade, or each cube face)
+    Resolution int32
+
+    // the number of cascades a directional shadow is split into along the camera frustum, 1 to MaxShadowCascades
+    Cascades int
+
+    // how the cascades are split between evenly spaced (0) and logarithmic (1) distances - logarithmic gives near
+    // cascades more resolution where the eye notices it
+    SplitLambda float32
+
+    // how far beyond each cascade towards the light shadow casters are still rendered, in world units
+    CasterDistance float32
+
+    // subtracted from the depth of the receiver before comparing, in depth map units (0 to 1)
+    DepthBias float32
+
+    // moves the receiver along its normal before looking it up, in texels of the depth map
+    NormalBias float32
+
+    // pushes back surfaces at steep angles to the light while rendering the depth maps, by this many times the
+    // depth slope across the texel - a polygon offset factor for directional shadows, applied in the shader for point
+    // shadows as they write their own depths
+    SlopeBias float32
+
+    // the radius in texels of the percentage closer filtering kernel - 0 takes a single (hardware filtered) sample,
+    // 1 a 3x3 grid, 2 a 5x5 grid
+    PCFRadius int
+}
+
+// settings that suit most scenes with units of around a metre
+func DefaultShadowOpts() ShadowOpts {
+    return ShadowOpts{
+        Resolution:     2048,
+        Cascades:       4,
+        SplitLambda:    0.75,
+        CasterDistance: 50,
+        DepthBias:      0.0005,
+        NormalBias:     1.5,
+        SlopeBias:      2,
+        PCFRadius:      1,
+    }
+}
+
+// ShadowCaster draws the objects casting shadows with the given depth program, which is in use with its projection
+// already set - the caster sets the "model" matrix uniform before drawing each mesh (laid out as by
+// model.Mesh.Interleave, only the position at location 0 is read).
+type ShadowCaster func(prog *Program) error
+
+// the depth only programs, compiled on first use, shared by every shadow and deleted with the window
+var shadowPrograms shadowProgramSet
+
+type shadowProgramSet struct {
+    directional *Program
+    point       *Program
+
+    // the debug views of the depth maps
+    layer *Program
+    face  *Program
+}
+
+func shadowProgram(point bool) (*Program, error) {
+    registerShadowPrograms()
+
+    var err error
+
+    if point {
+        if shadowPrograms.point == nil {
+            shadowPrograms.point, err = compileProgram(pointShadowVertexSource, pointShadowFragmentSource)
+        }
+        return shadowPrograms.point, err
+    }
+
+    if shadowPrograms.directional == nil {
+        shadowPrograms.directional, err = compileProgram(shadowVertexSource, shadowFragmentSource)
+    }
+    return shadowPrograms.directional, err
+}
+
+func shadowDebugProgram(cube bool) (*Program, error) {
+    registerShadowPrograms()
+
+    var err error
+
+    if cube {
+        if shadowPrograms.face == nil {
+            shadowPrograms.face, err = compileProgram(fullscreenVertexSource, shadowFaceFragmentSource)
+        }
+        return shadowPrograms.face, err
+    }
+
+    if shadowPrograms.layer == nil {
+        shadowPrograms.layer, err = compileProgram(fullscreenVertexSource, shadowLayerFragmentSource)
+    }
+    return shadowPrograms.layer, err
+}
+
+// arranges for the shadow programs to be deleted with the window, before the first of them is compiled
+func registerShadowPrograms() {
+    if shadowPrograms != (shadowProgramSet{}) {
+        return
+    }
+
+    OnDestroy(func() {
+        p := shadowPrograms
+        for _, prog := range []*Program{p.directional, p.point, p.layer, p.face} {
+            if prog != nil {
+                prog.Delete()
+            }
+        }
+
+        shadowPrograms = shadowProgramSet{}
+    })
+}
+
+// draws one layer or cube face of a depth map as grey levels into a rectangle of the bound framebuffer, with the
+// comparison turned off for the duration so the depths can be read directly
+func drawShadowDebug(target, texture uint32, cube bool, layer int, x, y, width, height int32) error {
+    prog, err := shadowDebugProgram(cube)
+    if err != nil {
+        return err
+    }
+
+    var saved passState
+    saved.save()
+    defer saved.restore()
+
+    gl.Viewport(x, y, width, height)
+
+    gl.ActiveTexture(gl.TEXTURE0)
+    gl.BindTexture(target, texture)
+    gl.TexParameteri(target, gl.TEXTURE_COMPARE_MODE, gl.NONE)
+    defer gl.TexParameteri(target, gl.TEXTURE_COMPARE_MODE, gl.COMPARE_REF_TO_TEXTURE)
+
+    gl.Disable(gl.DEPTH_TEST)
+
+    prog.Use()
+    if err := prog.Integer("depthMap", 0); err != nil {
+        return err
+    }
+    if err := prog.Integer("layer", int32(layer)); err != nil {
+        return err
+    }
+
+    drawFullscreenTriangle()
+
+    return nil
+}
+
+// sets the depth comparison and filtering used to sample a shadow map with a shadow sampler
+func applyShadowParameters(target uint32) {
+    gl.TexParameteri(target, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+    gl.TexParameteri(target, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+    gl.TexParameteri(target, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+    gl.TexParameteri(target, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+    gl.TexParameteri(target, gl.TEXTURE_WRAP_R, gl.CLAMP_TO_EDGE)
+    gl.TexParameteri(target, gl.TEXTURE_COMPARE_MODE, gl.COMPARE_REF_TO_TEXTURE)
+    gl.TexParameteri(target, gl.TEXTURE_COMPARE_FUNC, gl.LEQUAL)
+}
+
+// renders every layer of a depth target, clearing each and calling the caster with the program's "lightSpace"
+// matrix set for it
+func renderShadowLayers(fbo *Framebuffer, size int32, opts ShadowOpts, prog *Program, layers int,
+    attach func(layer int), lightSpace func(layer int) Mat4, caster ShadowCaster) error {
+
+    var saved passState
+    saved.save()
+    defer saved.restore()
+
+    fbo.Bind()
+    gl.Viewport(0, 0, size, size)
+
+    gl.Enable(gl.DEPTH_TEST)
+    gl.DepthFunc(gl.LESS)
+    gl.DepthMask(true)
+    gl.ColorMask(false, false, false, false)
+    defer gl.ColorMask(true, true, true, true)
+
+    // point shadows write their own depths, which the polygon offset doesn't apply to
+    if opts.SlopeBias != 0 {
+        gl.Enable(gl.POLYGON_OFFSET_FILL)
+        gl.PolygonOffset(opts.SlopeBias, 1)
+        defer gl.Disable(gl.POLYGON_OFFSET_FILL)
+    }
+
+    prog.Use()
+
+    for layer := 0; layer < layers; layer++ {
+        attach(layer)
+
+        if layer == 0 {
+            if err := fbo.Check(); err != nil {
+                return err
+            }
+        }
+
+        gl.Clear(gl.DEPTH_BUFFER_BIT)
+
+        if err := prog.Mat4("lightSpace", lightSpace(layer)); err != nil {
+            return err
+        }
+
+        if err := caster(prog); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// DirectionalShadow is a cascaded shadow map for a directional light. The camera frustum is split into slices by
+// distance, each covered by its own depth map, so the shadows near the camera get most of the resolution.
+type DirectionalShadow struct {
+    opts ShadowOpts
+
+    texture uint32
+    fbo     *Framebuffer
+    res     *resource
+
+    // the camera view matrix and the far distance of each cascade along the view direction
+    view   Mat4
+    splits [MaxShadowCascades]float32
+
+    // world to light clip space, and the world size of a texel, for each cascade
+    matrices [MaxShadowCascades]Mat4
+    texels   [MaxShadowCascades]float32
+}
+
+// Creates the depth map array of a directional shadow
+func NewDirectionalShadow(opts ShadowOpts) (*DirectionalShadow, error) {
+    if opts.Cascades < 1 || opts.Cascades > MaxShadowCascades {
+        return nil, fmt.Errorf("invalid shadow cascades: cascades = %d, max = %d", opts.Cascades, MaxShadowCascades)
+    }
+
+    if opts.Resolution < 1 {
+        return nil, fmt.Errorf("invalid shadow resolution: resolution = %d", opts.Resolution)
+    }
+
+    var texture uint32
+    gl.GenTextures(1, &texture)
+    gl.BindTexture(gl.TEXTURE_2D_ARRAY, texture)
+    gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.DEPTH_COMPONENT32F, opts.Resolution, opts.Resolution,
+        int32(opts.Cascades), 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
+    applyShadowParameters(gl.TEXTURE_2D_ARRAY)
+
+    s := &DirectionalShadow{opts: opts, texture: texture, fbo: NewFramebuffer()}
+    s.res = track(s, textureResource, texture)
+
+    s.fbo.Bind()
+    gl.DrawBuffer(gl.NONE)
+    gl.ReadBuffer(gl.NONE)
+    BindDefaultFramebuffer()
+
+    return s, nil
+}
+
+// the options the shadow was created with
+func (s *DirectionalShadow) Opts() ShadowOpts {
+    return s.opts
+}
+
+// Fits the cascades to the part of the camera frustum between near and far, for a light shining in the given
+// direction. Each cascade is bounded by a sphere so its size doesn't change as the camera turns, and snapped to whole
+// texels so the shadow edges don't shimmer as the camera moves.
+func (s *DirectionalShadow) Update(direction Vec3, view, projection Mat4, near, far float32) {
+    s.view = view
+    direction = direction.Normalize()
+
+    // the corners of the whole frustum, near plane then far plane
+    inverse := projection.Mul4(view).Inv()
+    var corners [8]Vec3
+    for i := range corners {
+        ndc := Vec3{float32(i&1)*2 - 1, float32(i>>1&1)*2 - 1, float32(i>>2)*2 - 1}
+        corners[i] = mgl32.TransformCoordinate(ndc, inverse)
+    }
+
+    up := Vec3{0, 1, 0}
+    if math.Abs(float64(direction.Dot(up))) > 0.99 {
+        up = Vec3{0, 0, 1}
+    }
+
+    cascades := s.opts.Cascades
+    sliceNear := near
+
+    for c := 0; c < cascades; c++ {
+        // the split between logarithmic and uniform distances
+        t := float32(c+1) / float32(cascades)
+        logSplit := near * float32(math.Pow(float64(far/near), float64(t)))
+        uniformSplit := near + (far-near)*t
+        sliceFar := s.opts.SplitLambda*logSplit + (1-s.opts.SplitLambda)*uniformSplit
+
+        s.splits[c] = sliceFar
+
+        // the corners of the slice lie along the frustum edges, which are linear in view depth
+        var center Vec3
+        var slice [8]Vec3
+        for i := 0; i < 4; i++ {
+            edge := corners[i+4].Sub(corners[i])
+            slice[i] = corners[i].Add(edge.Mul((sliceNear - near) / (far - near)))
+            slice[i+4] = corners[i].Add(edge.Mul((sliceFar - near) / (far - near)))
+        }
+        for _, p := range slice {
+            center = center.Add(p)
+        }
+        center = center.Mul(1.0 / 8)
+
+        radius := float32(0)
+        for _, p := range slice {
+            radius = float32(math.Max(float64(radius), float64(p.Sub(center).Len())))
+        }
+
+        // rounding the radius up keeps the projection from changing size with tiny rotations
+        radius = float32(math.Ceil(float64(radius)*16) / 16)
+
+        // the light looks at the centre from far enough back to take in casters outside the slice
+        back := radius + s.opts.CasterDistance
+        lightView := mgl32.LookAtV(center.Sub(direction.Mul(back)), center, up)
+        ortho := mgl32.Ortho(-radius, radius, -radius, radius, 0, back+radius)
+
+        // snap the projected world origin to a texel so the map contents move in whole texels
+        shadow := ortho.Mul4(lightView)
+        half := float32(s.opts.Resolution) / 2
+        origin := shadow.Mul4x1(Vec4{0, 0, 0, 1}).Mul(half)
+        ortho[12] += (float32(math.Round(float64(origin[0]))) - origin[0]) / half
+        ortho[13] += (float32(math.Round(float64(origin[1]))) - origin[1]) / half
+
+        s.matrices[c] = ortho.Mul4(lightView)
+        s.texels[c] = 2 * radius / float32(s.opts.Resolution)
+
+        sliceNear = sliceFar
+    }
+}
+
+// Renders the depth map of each cascade. Update must have been called first.
+func (s *DirectionalShadow) Render(caster ShadowCaster) error {
+    prog, err := shadowProgram(false)
+    if err != nil {
+        return err
+    }
+
+    attach := func(layer int) {
+        gl.FramebufferTextureLayer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, s.texture, 0, int32(layer))
+    }
+
+    lightSpace := func(layer int) Mat4 {
+        return s.matrices[layer]
+    }
+
+    return renderShadowLayers(s.fbo, s.opts.Resolution, s.opts, prog, s.opts.Cascades, attach, lightSpace, caster)
+}
+
+// binds the depth map array for sampling with sampler2DArrayShadow
+func (s *DirectionalShadow) Bind(textureUnit TextureUnit) {
+    gl.ActiveTexture(uint32(textureUnit.bind))
+    gl.BindTexture(gl.TEXTURE_2D_ARRAY, s.texture)
+}
+
+// Draws the depth map of a cascade into a rectangle of the bound framebuffer, near depths dark and far ones light
+func (s *DirectionalShadow) DrawDebug(cascade int, x, y, width, height int32) error {
+    if cascade < 0 || cascade >= s.opts.Cascades {
+        return fmt.Errorf("invalid shadow cascade: cascade = %d, cascades = %d", cascade, s.opts.Cascades)
+    }
+
+    return drawShadowDebug(gl.TEXTURE_2D_ARRAY, s.texture, false, cascade, x, y, width, height)
+}
+
+// the world to light clip space matrix of the given cascade
+func (s *DirectionalShadow) Matrix(cascade int) Mat4 {
+    return s.matrices[cascade]
+}
+
+// the far distance of the given cascade along the camera view direction
+func (s *DirectionalShadow) Split(cascade int) float32 {
+    return s.splits[cascade]
+}
+
+// Uploads the cascades and bias settings to a program built with ShadowGLSL, which must be in use. The depth maps
+// are sampled from "directionalShadowMap", which the caller binds (directly or through a Material).
+func (s *DirectionalShadow) Upload(prog *Program) error {
+    cascades := s.opts.Cascades
+
+    err := prog.Integer("cascadeCount", int32(cascades))
+    if err == nil {
+        err = prog.Mat4("shadowView", s.view)
+    }
+    if err == nil {
+        err = prog.Mat4Array("cascadeMatrices", s.matrices[:cascades])
+    }
+
+    for c := 0; c < cascades && err == nil; c++ {
+        err = prog.Float(fmt.Sprintf("cascadeSplits[%d]", c), s.splits[c])
+        if err == nil {
+            err = prog.Float(fmt.Sprintf("cascadeTexels[%d]", c), s.texels[c])
+        }
+    }
+
+    if err == nil {
+        err = uploadShadowFilter(prog, s.opts)
+    }
+
+    return err
+}
+
+// deletes the depth maps and framebuffer
+func (s *DirectionalShadow) Delete() {
+    s.res.release()
+    s.fbo.Delete()
+}
+
+func uploadShadowFilter(prog *Program, opts ShadowOpts) error {
+    err := prog.Float("shadowDepthBias", opts.DepthBias)
+    if err == nil {
+        err = prog.Float("shadowNormalBias", opts.NormalBias)
+    }
+    if err == nil {
+        err = prog.Integer("shadowPCFRadius", int32(opts.PCFRadius))
+    }
+    return err
+}
+
+// PointShadow is an omnidirectional shadow map for a point light - a cube map of the distance from the light to the
+// nearest surface in every direction, as a fraction of the far distance
+type PointShadow struct {
+    opts ShadowOpts
+
+    texture uint32
+    fbo     *Framebuffer
+    res     *resource
+
+    position  Vec3
+    near, far float32
+}
+
+// Creates the depth cube map of a point light shadow, the cascade settings of the options are unused
+func NewPointShadow(opts ShadowOpts) (*PointShadow, error) {
+    if opts.Resolution < 1 {
+        return nil, fmt.Errorf("invalid shadow resolution: resolution = %d", opts.Resolution)
+    }
+
+    var texture uint32
+    gl.GenTextures(1, &texture)
+    gl.BindTexture(gl.TEXTURE_CUBE_MAP, texture)
+
+    for face := uint32(0); face < 6; face++ {
+        gl.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+face, 0, gl.DEPTH_COMPONENT32F, opts.Resolution,
+            opts.Resolution, 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
+    }
+    applyShadowParameters(gl.TEXTURE_CUBE_MAP)
+
+    s := &PointShadow{opts: opts, texture: texture, fbo: NewFramebuffer(), near: 0.05, far: 25}
+    s.res = track(s, textureResource, texture)
+
+    s.fbo.Bind()
+    gl.DrawBuffer(gl.NONE)
+    gl.ReadBuffer(gl.NONE)
+    BindDefaultFramebuffer()
+
+    return s, nil
+}
+
+// the options the shadow was created with
+func (s *PointShadow) Opts() ShadowOpts {
+    return s.opts
+}
+
+// Moves the light, only casters between near and far from it cast shadows
+func (s *PointShadow) Update(position Vec3, near, far float32) {
+    s.position, s.near, s.far = position, near, far
+}
+
+// Renders the six faces of the depth cube map
+func (s *PointShadow) Render(caster ShadowCaster) error {
+    prog, err := shadowProgram(true)
+    if err != nil {
+        return err
+    }
+
+    prog.Use()
+    if err := prog.Vec3("lightPos", s.position); err != nil {
+        return err
+    }
+    if err := prog.Float("farPlane", s.far); err != nil {
+        return err
+    }
+    if err := prog.Float("slopeBias", s.opts.SlopeBias); err != nil {
+        return err
+    }
+
+    projection := mgl32.Perspective(mgl32.DegToRad(90), 1, s.near, s.far)
+    translate := mgl32.Translate3D(-s.position[0], -s.position[1], -s.position[2])
+
+    attach := func(layer int) {
+        gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(layer),
+            s.texture, 0)
+    }
+
+    lightSpace := func(layer int) Mat4 {
+        return projection.Mul4(cubeCaptureViews[layer]).Mul4(translate)
+    }
+
+    return renderShadowLayers(s.fbo, s.opts.Resolution, s.opts, prog, 6, attach, lightSpace, caster)
+}
+
+// binds the depth cube map for sampling with samplerCubeShadow
+func (s *PointShadow) Bind(textureUnit TextureUnit) {
+    gl.ActiveTexture(uint32(textureUnit.bind))
+    gl.BindTexture(gl.TEXTURE_CUBE_MAP, s.texture)
+}
+
+// Draws a face of the depth cube map (in the order +x, -x, +y, -y, +z, -z) into a rectangle of the bound
+// framebuffer, near depths dark and far ones light
+func (s *PointShadow) DrawDebug(face int, x, y, width, height int32) error {
+    if face < 0 || face >= 6 {
+        return fmt.Errorf("invalid cube face: face = %d", face)
+    }
+
+    return drawShadowDebug(gl.TEXTURE_CUBE_MAP, s.texture, true, face, x, y, width, height)
+}
+
+// Uploads the light position, range and bias settings as point shadow index of a program built with ShadowGLSL,
+// which must be in use. The cube map is sampled from "pointShadowMaps[index]", which the caller binds.
+func (s *PointShadow) Upload(prog *Program, index int) error {
+    if index < 0 || index >= MaxPointShadows {
+        return fmt.Errorf("invalid point shadow index: index = %d, max = %d", index, MaxPointShadows)
+    }
+
+    err := prog.Vec3(fmt.Sprintf("pointShadowPositions[%d]", index), s.position)
+    if err == nil {
+        err = prog.Float(fmt.Sprintf("pointShadowFar[%d]", index), s.far)
+    }
+
+    // a texel of the cube spans about 2 / resolution radians, which the normal bias is measured in
+    if err == nil {
+        err = prog.Float(fmt.Sprintf("pointShadowTexels[%d]", index), 2/float32(s.opts.Resolution))
+    }
+
+    if err == nil {
+        err = uploadShadowFilter(prog, s.opts)
+    }
+
+    return err
+}
+
+// deletes the depth cube map and framebuffer
+func (s *PointShadow) Delete() {
+    s.res.release()
+    s.fbo.Delete()
+}
+
+const shadowVertexSource = `#version 330 core
+layout (location = 0) in vec3 aPos;
+
+uniform mat4 lightSpace;
+uniform mat4 model;
+
+void main()
+{
+    gl_Position = lightSpace * model * vec4(aPos, 1.0);
+}
+` + "\x00"
+
+const shadowFragmentSource = `#version 330 core
+
+void main()
+{
+}
+` + "\x00"
+
+const pointShadowVertexSource = `#version 330 core
+layout (location = 0) in vec3 aPos;
+
+out vec3 worldPos;
+
+uniform mat4 lightSpace;
+uniform mat4 model;
+
+void main()
+{
+    vec4 world = model * vec4(aPos, 1.0);
+    worldPos = world.xyz;
+    gl_Position = lightSpace * world;
+}
+` + "\x00"
+
+const pointShadowFragmentSource = `#version 330 core
+in vec3 worldPos;
+
+uniform vec3 lightPos;
+uniform float farPlane;
+uniform float slopeBias;
+
+void main()
+{
+    // the linear distance to the light, so lookups compare distances rather than projected depths
+    float depth = length(worldPos - lightPos) / farPlane;
+
+    // the polygon offset has no effect on written depths, so the slope bias is applied here instead
+    float slope = max(abs(dFdx(depth)), abs(dFdy(depth)));
+    gl_FragDepth = depth + slopeBias * slope;
+}
+` + "\x00"
+
+const shadowLayerFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec2 texCoords;
+
+uniform sampler2DArray depthMap;
+uniform int layer;
+
+void main()
+{
+    float depth = texture(depthMap, vec3(texCoords, float(layer))).r;
+    FragColor = vec4(vec3(depth), 1.0);
+}
+` + "\x00"
+
+const shadowFaceFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec2 texCoords;
+
+uniform samplerCube depthMap;
+uniform int layer;
+
+void main()
+{
+    // the direction through the texel, with the orientation of the faces from the cube map specification
+    vec2 uv = texCoords * 2.0 - 1.0;
+    vec3 dir;
+    if (layer == 0) {
+        dir = vec3(1.0, -uv.y, -uv.x);
+    } else if (layer == 1) {
+        dir = vec3(-1.0, -uv.y, uv.x);
+    } else if (layer == 2) {
+        dir = vec3(uv.x, 1.0, uv.y);
+    } else if (layer == 3) {
+        dir = vec3(uv.x, -1.0, -uv.y);
+    } else if (layer == 4) {
+        dir = vec3(uv.x, -uv.y, 1.0);
+    } else {
+        dir = vec3(-uv.x, -uv.y, -1.0);
+    }
+
+    float depth = texture(depthMap, dir).r;
+    FragColor = vec4(vec3(depth), 1.0);
+}
+` + "\x00"
+
+// Fragment shader declarations for sampling shadow maps, to paste after the #version line. directionalShadow()
+// returns how lit (1) or shadowed (0) a fragment is by the cascaded shadow uploaded with DirectionalShadow.Upload,
+// and pointShadow() the same for the point shadow uploaded at the given index with PointShadow.Upload. Both take the
+// fragment's world space position and unit normal.
+const ShadowGLSL = `
+#define MAX_SHADOW_CASCADES 4
+#define MAX_POINT_SHADOWS 4
+
+uniform sampler2DArrayShadow directionalShadowMap;
+uniform mat4 shadowView;
+uniform mat4 cascadeMatrices[MAX_SHADOW_CASCADES];
+uniform float cascadeSplits[MAX_SHADOW_CASCADES];
+uniform float cascadeTexels[MAX_SHADOW_CASCADES];
+uniform int cascadeCount;
+
+uniform samplerCubeShadow pointShadowMaps[MAX_POINT_SHADOWS];
+uniform vec3 pointShadowPositions[MAX_POINT_SHADOWS];
+uniform float pointShadowFar[MAX_POINT_SHADOWS];
+uniform float pointShadowTexels[MAX_POINT_SHADOWS];
+
+uniform float shadowDepthBias;
+uniform float shadowNormalBias;
+uniform int shadowPCFRadius;
+
+// the cascade covering a fragment, by its distance along the camera view direction
+int shadowCascade(vec3 worldPos)
+{
+    float depth = -(shadowView * vec4(worldPos, 1.0)).z;
+
+    for (int i = 0; i < cascadeCount - 1; i++) {
+        if (depth < cascadeSplits[i]) {
+            return i;
+        }
+    }
+
+    return cascadeCount - 1;
+}
+
+float directionalShadow(vec3 worldPos, vec3 normal)
+{
+    int cascade = shadowCascade(worldPos);
+
+    vec3 offsetPos = worldPos + normal * shadowNormalBias * cascadeTexels[cascade];
+    vec4 lightSpace = cascadeMatrices[cascade] * vec4(offsetPos, 1.0);
+    vec3 coords = lightSpace.xyz / lightSpace.w * 0.5 + 0.5;
+
+    // beyond the far side of the map nothing casts a shadow
+    if (coords.z > 1.0) {
+        return 1.0;
+    }
+
+    float depth = coords.z - shadowDepthBias;
+    vec2 texel = 1.0 / vec2(textureSize(directionalShadowMap, 0).xy);
+
+    float lit = 0.0;
+    for (int x = -shadowPCFRadius; x <= shadowPCFRadius; x++) {
+        for (int y = -shadowPCFRadius; y <= shadowPCFRadius; y++) {
+            vec2 uv = coords.xy + vec2(x, y) * texel;
+            lit += texture(directionalShadowMap, vec4(uv, float(cascade), depth));
+        }
+    }
+
+    float taps = float((2 * shadowPCFRadius + 1) * (2 * shadowPCFRadius + 1));
+    return lit / taps;
+}
+
+// offsets spread over a cube around the lookup direction for filtering point shadows
+const vec3 pointShadowOffsets[20] = vec3[](
+    vec3(1, 1, 1), vec3(1, -1, 1), vec3(-1, -1, 1), vec3(-1, 1, 1),
+    vec3(1, 1, -1), vec3(1, -1, -1), vec3(-1, -1, -1), vec3(-1, 1, -1),
+    vec3(1, 1, 0), vec3(1, -1, 0), vec3(-1, -1, 0), vec3(-1, 1, 0),
+    vec3(1, 0, 1), vec3(-1, 0, 1), vec3(1, 0, -1), vec3(-1, 0, -1),
+    vec3(0, 1, 1), vec3(0, -1, 1), vec3(0, -1, -1), vec3(0, 1, -1)
+);
+
+float samplePointShadow(samplerCubeShadow map, vec3 lightPos, float far, float texel, vec3 worldPos, vec3 normal)
+{
+    vec3 toFrag = worldPos - lightPos;
+    float distance = length(toFrag);
+
+    // a texel of the cube covers more of the surface the further it is from the light
+    vec3 offsetPos = worldPos + normal * shadowNormalBias * texel * distance;
+    toFrag = offsetPos - lightPos;
+
+    float depth = length(toFrag) / far - shadowDepthBias;
+    if (depth > 1.0) {
+        return 1.0;
+    }
+
+    if (shadowPCFRadius == 0) {
+        return texture(map, vec4(toFrag, depth));
+    }
+
+    float radius = float(shadowPCFRadius) * texel * length(toFrag);
+
+    float lit = 0.0;
+    for (int i = 0; i < 20; i++) {
+        lit += texture(map, vec4(toFrag + pointShadowOffsets[i] * radius, depth));
+    }
+
+    return lit / 20.0;
+}
+
+float pointShadow(int index, vec3 worldPos, vec3 normal)
+{
+    // samplers in arrays can only be indexed with constants in GLSL 3.30
+    vec3 p = pointShadowPositions[index];
+    float far = pointShadowFar[index];
+    float texel = pointShadowTexels[index];
+
+    if (index == 0) {
+        return samplePointShadow(pointShadowMaps[0], p, far, texel, worldPos, normal);
+    } else if (index == 1) {
+        return samplePointShadow(pointShadowMaps[1], p, far, texel, worldPos, normal);
+    } else if (index == 2) {
+        return samplePointShadow(pointShadowMaps[2], p, far, texel, worldPos, normal);
+    }
+
+    return samplePointShadow(pointShadowMaps[3], p, far, texel, worldPos, normal);
+}
+`
@@ -0,0 +1,120 @@
+package render
+
+import (
+    "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Skinning
+// --------------------------------------------------------------------------------------------------------
+
+// the size of the joint matrix array declared by SkinningUniformGLSL - skins with more joints need a JointTexture
+const MaxUniformJoints = 64
+
+// holds joint matrices in a float texture for skins too large for a uniform array. Each matrix takes 4 texels of an
+// RGBA32F row, one per column, read back with texelFetch by SkinningTextureGLSL.
+type JointTexture struct {
+    ptr    uint32
+    joints int32
+    res    *resource
+}
+
+// Creates a joint texture with room for the given number of joints
+func NewJointTexture(joints int32) *JointTexture {
+    var texture uint32
+    gl.GenTextures(1, &texture)
+    gl.BindTexture(gl.TEXTURE_2D, texture)
+
+    applyTextureOpts(gl.TEXTURE_2D, TextureOpts{
+        WrapS:     ClampToEdge,
+        WrapT:     ClampToEdge,
+        MinFilter: Nearest,
+        MagFilter: Nearest,
+    })
+
+    gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA32F, joints*4, 1, 0, gl.RGBA, gl.FLOAT, nil)
+    glCheck("creating a joint texture")
+
+    t := &JointTexture{ptr: texture, joints: joints}
+    t.res = track(t, textureResource, texture)
+
+    return t
+}
+
+// the number of joints the texture holds
+func (t *JointTexture) Joints() int32 {
+    return t.joints
+}
+
+// uploads the joint matrices, any beyond the capacity of the texture are ignored
+func (t *JointTexture) Update(matrices []Mat4) {
+    count := int32(len(matrices))
+    if count > t.joints {
+        count = t.joints
+    }
+
+    if count == 0 {
+        return
+    }
+
+    gl.BindTexture(gl.TEXTURE_2D, t.ptr)
+    gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, count*4, 1, gl.RGBA, gl.FLOAT, gl.Ptr(&matrices[0][0]))
+}
+
+// binds this texture for usage to the given texture unit
+func (t *JointTexture) Bind(textureUnit TextureUnit) {
+    gl.ActiveTexture(uint32(textureUnit.bind))
+    gl.BindTexture(gl.TEXTURE_2D, t.ptr)
+}
+
+// deletes the underlying texture
+func (t *JointTexture) Delete() {
+    t.res.release()
+}
+
+// Vertex shader declarations for skinning with a uniform array of joint matrices, to paste after the #version line.
+// The joints and weights are read from attribute locations 5 and 6 (as laid out by model.Mesh.Interleave) and
+// skinMatrix() gives the weighted joint transform to apply before the model matrix. Upload the matrices to "joints"
+// with Program.Mat4Array.
+const SkinningUniformGLSL = `
+layout (location = 5) in vec4 aJoints;
+layout (location = 6) in vec4 aWeights;
+
+uniform mat4 joints[64];
+
+mat4 skinMatrix()
+{
+    return aWeights.x * joints[int(aJoints.x)] +
+           aWeights.y * joints[int(aJoints.y)] +
+           aWeights.z * joints[int(aJoints.z)] +
+           aWeights.w * joints[int(aJoints.w)];
+}
+`
+
+// As SkinningUniformGLSL but reading the joint matrices from a JointTexture bound to the "jointTexture" sampler
+const SkinningTextureGLSL = `
+layout (location = 5) in vec4 aJoints;
+layout (location = 6) in vec4 aWeights;
+
+uniform sampler2D jointTexture;
+
+mat4 jointMatrix(float joint)
+{
+    int x = int(joint) * 4;
+
+    return mat4(
+        texelFetch(jointTexture, ivec2(x, 0), 0),
+        texelFetch(jointTexture, ivec2(x + 1, 0), 0),
+        texelFetch(jointTexture, ivec2(x + 2, 0), 0),
+        texelFetch(jointTexture, ivec2(x + 3, 0), 0)
+    );
+}
+
+mat4 skinMatrix()
+{
+    return aWeights.x * jointMatrix(aJoints.x) +
+           aWeights.y * jointMatrix(aJoints.y) +
+           aWeights.z * jointMatrix(aJoints.z) +
+           aWeights.w * jointMatrix(aJoints.w);
+}
+`
@@ -0,0 +1,169 @@
+package render
+
+import (
+    "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Skybox
+// --------------------------------------------------------------------------------------------------------
+
+// draws a cube map around the camera, behind everything else in the scene
+type Skybox struct {
+    CubeMap *CubeMap
+    prog    *Program
+    cube    *unitCube
+}
+
+// Creates a new skybox for the given cube map. The cube map is not owned by the skybox and is not deleted with it.
+func NewSkybox(cubeMap *CubeMap) (*Skybox, error) {
+    prog, err := compileProgram(skyboxVertexSource, skyboxFragmentSource)
+    if err != nil {
+        return nil, err
+    }
+
+    prog.Use()
+    if err := prog.Integer("skybox", TextureUnit0.Index()); err != nil {
+        prog.Delete()
+        return nil, err
+    }
+
+    return &Skybox{
+        CubeMap: cubeMap,
+        prog:    prog,
+        cube:    newUnitCube(),
+    }, nil
+}
+
+// Draws the skybox with the camera view and projection matrices. The translation is removed from the view so the
+// skybox stays centred on the camera and the depth is forced to the far plane, so with depth testing enabled the
+// skybox should be drawn after the opaque geometry (any existing fragments win the depth test). The far plane is
+// taken as depth 0 when the depth function is gl.GREATER or gl.GEQUAL (see EnableReverseZ). This uses texture unit 0
+// and restores the depth function afterwards.
+func (s *Skybox) Draw(view, projection Mat4) error {
+    var depthFunc int32
+    gl.GetIntegerv(gl.DEPTH_FUNC, &depthFunc)
+    defer gl.DepthFunc(uint32(depthFunc))
+
+    reverseZ := depthFunc == gl.GREATER || depthFunc == gl.GEQUAL
+    if reverseZ {
+        gl.DepthFunc(gl.GEQUAL)
+    } else {
+        gl.DepthFunc(gl.LEQUAL)
+    }
+
+    s.prog.Use()
+
+    if err := s.prog.Bool("reverseZ", reverseZ); err != nil {
+        return err
+    }
+
+    if err := s.prog.Mat4("view", view.Mat3().Mat4()); err != nil {
+        return err
+    }
+
+    if err := s.prog.Mat4("projection", projection); err != nil {
+        return err
+    }
+
+    s.CubeMap.Bind(TextureUnit0)
+    s.cube.draw()
+
+    return nil
+}
+
+// releases the program and buffers held by the skybox
+func (s *Skybox) Delete() {
+    s.prog.Delete()
+    s.cube.delete()
+}
+
+// a position only cube spanning -1 to 1 on each axis, wound for viewing from the inside
+type unitCube struct {
+    vao *VertexArray
+    vbo *Buffer
+}
+
+func newUnitCube() *unitCube {
+    vao := NewVertexArray()
+    vbo := NewBuffer(ArrayBuffer)
+
+    vao.Bind()
+    vbo.Data(len(unitCubeVertices)*4, unitCubeVertices, StaticDraw)
+
+    gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 12, gl.PtrOffset(0))
+    gl.EnableVertexAttribArray(0)
+
+    gl.BindVertexArray(0)
+
+    return &unitCube{vao: vao, vbo: vbo}
+}
+
+func (c *unitCube) draw() {
+    c.vao.Bind()
+    gl.DrawArrays(gl.TRIANGLES, 0, int32(len(unitCubeVertices)/3))
+    gl.BindVertexArray(0)
+}
+
+func (c *unitCube) delete() {
+    c.vao.Delete()
+    c.vbo.Delete()
+}
+
+var unitCubeVertices = []float32{
+    // back
+    -1, 1, -1, -1, -1, -1, 1, -1, -1,
+    1, -1, -1, 1, 1, -1, -1, 1, -1,
+
+    // left
+    -1, -1, 1, -1, -1, -1, -1, 1, -1,
+    -1, 1, -1, -1, 1, 1, -1, -1, 1,
+
+    // right
+    1, -1, -1, 1, -1, 1, 1, 1, 1,
+    1, 1, 1, 1, 1, -1, 1, -1, -1,
+
+    // front
+    -1, -1, 1, -1, 1, 1, 1, 1, 1,
+    1, 1, 1, 1, -1, 1, -1, -1, 1,
+
+    // top
+    -1, 1, -1, 1, 1, -1, 1, 1, 1,
+    1, 1, 1, -1, 1, 1, -1, 1, -1,
+
+    // bottom
+    -1, -1, -1, -1, -1, 1, 1, -1, -1,
+    1, -1, -1, -1, -1, 1, 1, -1, 1,
+}
+
+const skyboxVertexSource = `#version 330 core
+layout (location = 0) in vec3 aPos;
+
+out vec3 texCoords;
+
+uniform mat4 projection;
+uniform mat4 view;
+uniform bool reverseZ;
+
+void main()
+{
+    texCoords = aPos;
+    vec4 pos = projection * view * vec4(aPos, 1.0);
+
+    // z / w lands on the far plane, depth 0 with reverse-z and 1 otherwise
+    gl_Position = reverseZ ? vec4(pos.xy, 0.0, pos.w) : pos.xyww;
+}
+` + "\x00"
+
+const skyboxFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec3 texCoords;
+
+uniform samplerCube skybox;
+
+void main()
+{
+    FragColor = texture(skybox, texCoords);
+}
+` + "\x00"
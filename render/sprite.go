@@ -0,0 +1,281 @@
+package render
+
+import (
+    "errors"
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "image"
+    "math"
+    "sort"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Sprite Batching
+// --------------------------------------------------------------------------------------------------------
+
+// the order a SpriteBatch draws its sprites in
+type SpriteSortMode int
+
+const (
+    // in the order they were drawn
+    SortDeferred SpriteSortMode = iota
+
+    // grouped by texture, for the fewest draw calls when sprites don't overlap
+    SortTexture
+
+    // largest depth first, for sprites that overlap
+    SortBackToFront
+
+    // smallest depth first
+    SortFrontToBack
+)
+
+func (m SpriteSortMode) String() string {
+    switch m {
+    case SortDeferred:
+        return "Deferred"
+    case SortTexture:
+        return "Texture"
+    case SortBackToFront:
+        return "BackToFront"
+    case SortFrontToBack:
+        return "FrontToBack"
+    default:
+        return "Unknown"
+    }
+}
+
+// Sprite is a textured quad drawn by a SpriteBatch. Positions are in the units of the batch's projection, pixels
+// from the top left with PixelOrtho. Textures are expected the right way up (created without FlipY).
+type Sprite struct {
+    Texture *Texture
+
+    // the pixel rectangle of the texture drawn, y down - the whole texture when empty
+    Source image.Rectangle
+
+    // where the origin of the sprite is placed
+    Position Vec2
+
+    // the point the sprite is positioned, rotated and scaled about, in pixels from the top left of its source
+    Origin Vec2
+
+    // the rotation in radians, clockwise on screen
+    Rotation float32
+
+    // multiplies the size of the source rectangle, a zero scale is taken as {1, 1}
+    Scale Vec2
+
+    // multiplies the texture colour, the zero value (which is also Transparent) being taken as White
+    Color Color
+
+    // orders the sprites for SortBackToFront and SortFrontToBack
+    Depth float32
+
+    // mirror the texture horizontally or vertically
+    FlipX, FlipY bool
+}
+
+// SpriteBatch draws textured quads in as few draw calls as it can. Sprites are queued between Begin and End, then
+// sorted and streamed through one reused vertex buffer, with a draw call whenever the texture changes or the buffer
+// fills.
+type SpriteBatch struct {
+    // the number of draw calls made by the last End
+    DrawCalls int
+
+    prog  *Program
+    batch *quadBatch
+
+    sprites    []Sprite
+    mode       SpriteSortMode
+    projection Mat4
+    begun      bool
+}
+
+// Creates a new sprite batch
+func NewSpriteBatch() (*SpriteBatch, error) {
+    prog, err := compileProgram(quadVertexSource, spriteFragmentSource)
+    if err != nil {
+        return nil, err
+    }
+
+    return &SpriteBatch{prog: prog, batch: newQuadBatch()}, nil
+}
+
+// starts queuing sprites drawn with the given projection (usually Window.PixelOrtho) in the given order
+func (b *SpriteBatch) Begin(projection Mat4, mode SpriteSortMode) {
+    b.projection = projection
+    b.mode = mode
+    b.sprites = b.sprites[:0]
+    b.begun = true
+}
+
+// queues the whole texture with its top left at the given position, tinted by the colour
+func (b *SpriteBatch) Draw(texture *Texture, position Vec2, color Color) {
+    b.DrawSprite(Sprite{Texture: texture, Position: position, Color: color})
+}
+
+// queues a sprite
+func (b *SpriteBatch) DrawSprite(s Sprite) {
+    b.sprites = append(b.sprites, s)
+}
+
+// Sorts and draws the queued sprites alpha blended over the bound framebuffer, without depth testing. The blending
+// and depth state are restored afterwards.
+func (b *SpriteBatch) End() error {
+    if !b.begun {
+        return errors.New("sprite batch ended without begin")
+    }
+
+    b.begun = false
+    b.DrawCalls = 0
+
+    if len(b.sprites) == 0 {
+        return nil
+    }
+
+    for i := range b.sprites {
+        if b.sprites[i].Texture == nil {
+            return fmt.Errorf("sprite has no texture: index = %d", i)
+        }
+    }
+
+    sortSprites(b.sprites, b.mode)
+
+    var state passState
+    state.save()
+    defer state.restore()
+
+    gl.Disable(gl.DEPTH_TEST)
+    gl.Disable(gl.CULL_FACE)
+    gl.Enable(gl.BLEND)
+    gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+    b.prog.Use()
+
+    if err := b.prog.Mat4("projection", b.projection); err != nil {
+        return err
+    }
+    if err := b.prog.Integer("sprite", 0); err != nil {
+        return err
+    }
+
+    var texture *Texture
+
+    for i := range b.sprites {
+        s := &b.sprites[i]
+
+        if s.Texture != texture || b.batch.len() == maxBatchQuads {
+            b.flush(texture)
+            texture = s.Texture
+        }
+
+        b.add(s)
+    }
+
+    b.flush(texture)
+
+    return nil
+}
+
+// orders sprites for the sort mode, keeping the drawing order between equals
+func sortSprites(sprites []Sprite, mode SpriteSortMode) {
+    switch mode {
+    case SortTexture:
+        sort.SliceStable(sprites, func(i, j int) bool {
+            return sprites[i].Texture.ptr < sprites[j].Texture.ptr
+        })
+
+    case SortBackToFront, SortFrontToBack:
+        back := mode == SortBackToFront
+
+        // sprites at the same depth are grouped by texture
+        sort.SliceStable(sprites, func(i, j int) bool {
+            if di, dj := sprites[i].Depth, sprites[j].Depth; di != dj {
+                return di > dj == back
+            }
+            return sprites[i].Texture.ptr < sprites[j].Texture.ptr
+        })
+    }
+}
+
+// draws the quads queued for a texture
+func (b *SpriteBatch) flush(texture *Texture) {
+    if b.batch.len() == 0 {
+        return
+    }
+
+    texture.Bind(TextureUnit0)
+    b.batch.flush()
+    b.DrawCalls++
+}
+
+// queues the quad of a sprite
+func (b *SpriteBatch) add(s *Sprite) {
+    corners, uvs, color := s.quad()
+    b.batch.addCorners(corners, uvs, color)
+}
+
+// the corners of the sprite's quad and their texture coordinates, in the order quadBatch expects, and its colour
+func (s *Sprite) quad() ([4]Vec2, [4]Vec2, Color) {
+    source := s.Source
+    if source.Empty() {
+        source = image.Rect(0, 0, int(s.Texture.width), int(s.Texture.height))
+    }
+
+    scale := s.Scale
+    if scale == (Vec2{}) {
+        scale = Vec2{1, 1}
+    }
+
+    width, height := float32(source.Dx()), float32(source.Dy())
+
+    sin, cos := math.Sincos(float64(s.Rotation))
+    sn, cs := float32(sin), float32(cos)
+
+    // the corners about the origin, scaled, rotated and moved into place
+    var corners [4]Vec2
+    for i, local := range [4]Vec2{{0, 0}, {0, height}, {width, height}, {width, 0}} {
+        x := (local[0] - s.Origin[0]) * scale[0]
+        y := (local[1] - s.Origin[1]) * scale[1]
+
+        corners[i] = Vec2{s.Position[0] + x*cs - y*sn, s.Position[1] + x*sn + y*cs}
+    }
+
+    tw, th := float32(s.Texture.width), float32(s.Texture.height)
+    u0, v0 := float32(source.Min.X)/tw, float32(source.Min.Y)/th
+    u1, v1 := float32(source.Max.X)/tw, float32(source.Max.Y)/th
+
+    if s.FlipX {
+        u0, u1 = u1, u0
+    }
+    if s.FlipY {
+        v0, v1 = v1, v0
+    }
+
+    color := s.Color
+    if color == (Color{}) {
+        color = White
+    }
+
+    return corners, [4]Vec2{{u0, v0}, {u0, v1}, {u1, v1}, {u1, v0}}, color
+}
+
+// deletes the program and buffers
+func (b *SpriteBatch) Delete() {
+    b.prog.Delete()
+    b.batch.delete()
+}
+
+const spriteFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec2 texCoord;
+in vec4 color;
+
+uniform sampler2D sprite;
+
+void main()
+{
+    FragColor = texture(sprite, texCoord) * color;
+}
+` + "\x00"
@@ -0,0 +1,161 @@
+package render
+
+import (
+    "image"
+    "math"
+    "testing"
+)
+
+func vec2Near(a, b Vec2) bool {
+    return a.Sub(b).Len() < 1e-4
+}
+
+func TestSortSprites(t *testing.T) {
+    a, b := &Texture{ptr: 2}, &Texture{ptr: 1}
+
+    // named by their drawing order
+    queued := []Sprite{
+        {Texture: a, Depth: 1, Position: Vec2{0}},
+        {Texture: b, Depth: 3, Position: Vec2{1}},
+        {Texture: a, Depth: 2, Position: Vec2{2}},
+        {Texture: b, Depth: 1, Position: Vec2{3}},
+        {Texture: a, Depth: 3, Position: Vec2{4}},
+        {Texture: a, Depth: 1, Position: Vec2{5}},
+    }
+
+    tests := []struct {
+        mode     SpriteSortMode
+        expected []float32
+    }{
+        {SortDeferred, []float32{0, 1, 2, 3, 4, 5}},
+        {SortTexture, []float32{1, 3, 0, 2, 4, 5}},
+        {SortBackToFront, []float32{1, 4, 2, 3, 0, 5}},
+        {SortFrontToBack, []float32{3, 0, 5, 2, 1, 4}},
+    }
+
+    for _, test := range tests {
+        sprites := append([]Sprite(nil), queued...)
+        sortSprites(sprites, test.mode)
+
+        for i, s := range sprites {
+            if s.Position[0] != test.expected[i] {
+                order := make([]float32, len(sprites))
+                for j := range sprites {
+                    order[j] = sprites[j].Position[0]
+                }
+
+                t.Errorf("%v: sorted into %v, expected %v", test.mode, order, test.expected)
+                break
+            }
+        }
+    }
+}
+
+func TestSpriteQuad(t *testing.T) {
+    texture := &Texture{ptr: 1, width: 64, height: 32}
+    half := float32(math.Pi / 2)
+
+    tests := []struct {
+        name    string
+        sprite  Sprite
+        corners [4]Vec2
+        uvs     [4]Vec2
+    }{
+        {
+            "whole texture",
+            Sprite{Position: Vec2{10, 20}},
+            [4]Vec2{{10, 20}, {10, 52}, {74, 52}, {74, 20}},
+            [4]Vec2{{0, 0}, {0, 1}, {1, 1}, {1, 0}},
+        },
+        {
+            "source rectangle",
+            Sprite{Source: image.Rect(16, 8, 48, 16)},
+            [4]Vec2{{0, 0}, {0, 8}, {32, 8}, {32, 0}},
+            [4]Vec2{{0.25, 0.25}, {0.25, 0.5}, {0.75, 0.5}, {0.75, 0.25}},
+        },
+        {
+            "origin",
+            Sprite{Position: Vec2{100, 100}, Origin: Vec2{32, 16}},
+            [4]Vec2{{68, 84}, {68, 116}, {132, 116}, {132, 84}},
+            [4]Vec2{{0, 0}, {0, 1}, {1, 1}, {1, 0}},
+        },
+        {
+            "scaled about the origin",
+            Sprite{Position: Vec2{100, 100}, Origin: Vec2{32, 16}, Scale: Vec2{2, 0.5}},
+            [4]Vec2{{36, 92}, {36, 108}, {164, 108}, {164, 92}},
+            [4]Vec2{{0, 0}, {0, 1}, {1, 1}, {1, 0}},
+        },
+        {
+            // a quarter turn clockwise with y down takes +x to +y
+            "rotated about the origin",
+            Sprite{Position: Vec2{100, 100}, Origin: Vec2{32, 16}, Rotation: half},
+            [4]Vec2{{116, 68}, {84, 68}, {84, 132}, {116, 132}},
+            [4]Vec2{{0, 0}, {0, 1}, {1, 1}, {1, 0}},
+        },
+        {
+            "rotated about the top left",
+            Sprite{Rotation: half},
+            [4]Vec2{{0, 0}, {-32, 0}, {-32, 64}, {0, 64}},
+            [4]Vec2{{0, 0}, {0, 1}, {1, 1}, {1, 0}},
+        },
+        {
+            "flipped horizontally",
+            Sprite{FlipX: true},
+            [4]Vec2{{0, 0}, {0, 32}, {64, 32}, {64, 0}},
+            [4]Vec2{{1, 0}, {1, 1}, {0, 1}, {0, 0}},
+        },
+        {
+            "flipped vertically",
+            Sprite{FlipY: true},
+            [4]Vec2{{0, 0}, {0, 32}, {64, 32}, {64, 0}},
+            [4]Vec2{{0, 1}, {0, 0}, {1, 0}, {1, 1}},
+        },
+        {
+            "flipped source rectangle",
+            Sprite{Source: image.Rect(16, 8, 48, 16), FlipX: true, FlipY: true},
+            [4]Vec2{{0, 0}, {0, 8}, {32, 8}, {32, 0}},
+            [4]Vec2{{0.75, 0.5}, {0.75, 0.25}, {0.25, 0.25}, {0.25, 0.5}},
+        },
+    }
+
+    for _, test := range tests {
+        test.sprite.Texture = texture
+        corners, uvs, _ := test.sprite.quad()
+
+        for i := range corners {
+            if !vec2Near(corners[i], test.corners[i]) {
+                t.Errorf("%s: corners %v, expected %v", test.name, corners, test.corners)
+                break
+            }
+        }
+
+        for i := range uvs {
+            if !vec2Near(uvs[i], test.uvs[i]) {
+                t.Errorf("%s: texture coordinates %v, expected %v", test.name, uvs, test.uvs)
+                break
+            }
+        }
+    }
+}
+
+func TestSpriteColor(t *testing.T) {
+    texture := &Texture{ptr: 1, width: 1, height: 1}
+
+    tests := []struct {
+        color, expected Color
+    }{
+        {Color{}, White},
+        {Red, Red},
+
+        // Transparent is the zero colour
+        {Transparent, White},
+        {RGBA(0, 0, 0, 0.5), RGBA(0, 0, 0, 0.5)},
+    }
+
+    for _, test := range tests {
+        s := Sprite{Texture: texture, Color: test.color}
+        if _, _, c := s.quad(); c != test.expected {
+            t.Errorf("%v drawn as %v, expected %v", test.color, c, test.expected)
+        }
+    }
+}
@@ -0,0 +1,213 @@
+package render
+
+import (
+    "errors"
+    "fmt"
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "image"
+    "logl/render/atlas"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Texture Arrays and 3D Textures
+// --------------------------------------------------------------------------------------------------------
+
+// holds a 2D array texture reference - a stack of equally sized 2D layers sampled with sampler2DArray
+type Texture2DArray struct {
+    ptr    uint32
+    layers int32
+    res    *resource
+}
+
+// binds this texture for usage to the given texture unit
+func (t *Texture2DArray) Bind(textureUnit TextureUnit) {
+    gl.ActiveTexture(uint32(textureUnit.bind))
+    gl.BindTexture(gl.TEXTURE_2D_ARRAY, t.ptr)
+}
+
+// the number of layers in the array
+func (t *Texture2DArray) Layers() int32 {
+    return t.layers
+}
+
+// deletes the underlying texture
+func (t *Texture2DArray) Delete() {
+    t.res.release()
+}
+
+// Reads a 2D array texture with one layer per path, all images must be the same size
+func ReadTexture2DArray(paths []string, opts TextureOpts) (*Texture2DArray, error) {
+    layers, err := readImages(paths, opts.FlipY)
+    if err != nil {
+        return nil, err
+    }
+
+    ptr, err := newLayeredTexture(gl.TEXTURE_2D_ARRAY, layers, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    t := &Texture2DArray{ptr: ptr, layers: int32(len(layers))}
+    t.res = track(t, textureResource, ptr)
+
+    return t, nil
+}
+
+// Creates a 2D array texture with one layer per image, all images must be the same size
+func NewTexture2DArray(images []image.Image, opts TextureOpts) (*Texture2DArray, error) {
+    layers := make([]*image.RGBA, len(images))
+    for i, img := range images {
+        layers[i] = toRGBA(img, opts.FlipY)
+    }
+
+    ptr, err := newLayeredTexture(gl.TEXTURE_2D_ARRAY, layers, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    t := &Texture2DArray{ptr: ptr, layers: int32(len(layers))}
+    t.res = track(t, textureResource, ptr)
+
+    return t, nil
+}
+
+// holds a 3D texture reference - a volume sampled with sampler3D
+type Texture3D struct {
+    ptr   uint32
+    depth int32
+    res   *resource
+}
+
+// binds this texture for usage to the given texture unit
+func (t *Texture3D) Bind(textureUnit TextureUnit) {
+    gl.ActiveTexture(uint32(textureUnit.bind))
+    gl.BindTexture(gl.TEXTURE_3D, t.ptr)
+}
+
+// the number of slices along the R axis
+func (t *Texture3D) Depth() int32 {
+    return t.depth
+}
+
+// deletes the underlying texture
+func (t *Texture3D) Delete() {
+    t.res.release()
+}
+
+// Reads a 3D texture with one depth slice per path, all images must be the same size
+func ReadTexture3D(paths []string, opts TextureOpts) (*Texture3D, error) {
+    slices, err := readImages(paths, opts.FlipY)
+    if err != nil {
+        return nil, err
+    }
+
+    ptr, err := newLayeredTexture(gl.TEXTURE_3D, slices, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    t := &Texture3D{ptr: ptr, depth: int32(len(slices))}
+    t.res = track(t, textureResource, ptr)
+
+    return t, nil
+}
+
+// Creates a 3D texture with one depth slice per image, all images must be the same size
+func NewTexture3D(images []image.Image, opts TextureOpts) (*Texture3D, error) {
+    slices := make([]*image.RGBA, len(images))
+    for i, img := range images {
+        slices[i] = toRGBA(img, opts.FlipY)
+    }
+
+    ptr, err := newLayeredTexture(gl.TEXTURE_3D, slices, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    t := &Texture3D{ptr: ptr, depth: int32(len(slices))}
+    t.res = track(t, textureResource, ptr)
+
+    return t, nil
+}
+
+// reads every path into an RGBA image
+func readImages(paths []string, flipY bool) ([]*image.RGBA, error) {
+    images := make([]*image.RGBA, len(paths))
+
+    for i, path := range paths {
+        rgba, err := readImage(path, flipY)
+        if err != nil {
+            return nil, err
+        }
+
+        images[i] = rgba
+    }
+
+    return images, nil
+}
+
+// allocates a texture for the given 3 dimensional target and uploads one image per layer or slice
+func newLayeredTexture(target uint32, images []*image.RGBA, opts TextureOpts) (uint32, error) {
+    if len(images) == 0 {
+        return 0, errors.New("no images specified for texture")
+    }
+
+    size := images[0].Rect.Size()
+    for i, img := range images {
+        if img.Rect.Size() != size {
+            return 0, fmt.Errorf(
+                "texture layer size mismatch: layer = %d, width = %d, height = %d, expected width = %d, height = %d",
+                i, img.Rect.Size().X, img.Rect.Size().Y, size.X, size.Y,
+            )
+        }
+    }
+
+    var texture uint32
+    gl.GenTextures(1, &texture)
+    gl.BindTexture(target, texture)
+
+    applyTextureOpts(target, opts)
+
+    gl.TexImage3D(target, 0, gl.RGBA8, int32(size.X), int32(size.Y), int32(len(images)), 0, gl.RGBA,
+        gl.UNSIGNED_BYTE, nil)
+
+    for i, img := range images {
+        gl.TexSubImage3D(target, 0, 0, 0, int32(i), int32(size.X), int32(size.Y), 1, gl.RGBA, gl.UNSIGNED_BYTE,
+            gl.Ptr(img.Pix))
+    }
+
+    if opts.GenMipMap {
+        gl.GenerateMipmap(target)
+    }
+
+    glCheck("creating a layered texture")
+
+    return texture, nil
+}
+
+// --------------------------------------------------------------------------------------------------------
+// Texture Atlas
+// --------------------------------------------------------------------------------------------------------
+
+// a texture built from a packed atlas along with the texture coordinates of each entry
+type TextureAtlas struct {
+    Texture *Texture
+    Regions map[string]atlas.Region
+}
+
+// looks up the region of the named entry
+func (t *TextureAtlas) Region(name string) (atlas.Region, bool) {
+    region, ok := t.Regions[name]
+    return region, ok
+}
+
+// Uploads a packed atlas as a texture. The image is uploaded as-is (FlipY is ignored) so the region texture
+// coordinates stay valid.
+func NewTextureAtlas(a *atlas.Atlas, opts TextureOpts) *TextureAtlas {
+    opts.FlipY = false
+
+    return &TextureAtlas{
+        Texture: newTexture(a.Image, opts),
+        Regions: a.Regions,
+    }
+}
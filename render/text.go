@@ -0,0 +1,121 @@
+package render
+
+import (
+    "github.com/go-gl/gl/v3.3-core/gl"
+    "logl/render/text"
+)
+
+// --------------------------------------------------------------------------------------------------------
+// Text
+// --------------------------------------------------------------------------------------------------------
+
+// TextRenderer draws text in screen space with the glyph atlas of a face. Text is queued with Draw and drawn in as
+// few draw calls as possible by Flush, usually once at the end of the frame.
+type TextRenderer struct {
+    Face *text.Face
+
+    atlas *TextureAtlas
+    prog  *Program
+    batch *quadBatch
+}
+
+// Uploads the glyph atlas of the face and creates a renderer for it
+func NewTextRenderer(face *text.Face) (*TextRenderer, error) {
+    prog, err := compileProgram(quadVertexSource, textFragmentSource)
+    if err != nil {
+        return nil, err
+    }
+
+    atlas := NewTextureAtlas(face.Atlas, TextureOpts{
+        WrapS:     ClampToEdge,
+        WrapT:     ClampToEdge,
+        MinFilter: Linear,
+        MagFilter: Linear,
+    })
+
+    return &TextRenderer{
+        Face:  face,
+        atlas: atlas,
+        prog:  prog,
+        batch: newQuadBatch(),
+    }, nil
+}
+
+// Lays out and queues text with the top left of its block at (x, y) in pixels, returning the layout for its size
+func (t *TextRenderer) Draw(s string, x, y float32, color Color, opts text.LayoutOpts) *text.Layout {
+    layout := t.Face.Layout(s, opts)
+    t.DrawLayout(layout, x, y, color)
+
+    return layout
+}
+
+// queues text laid out beforehand (for text that rarely changes) with its top left at (x, y) in pixels
+func (t *TextRenderer) DrawLayout(layout *text.Layout, x, y float32, color Color) {
+    for _, q := range layout.Quads {
+        t.batch.add(x+q.X0, y+q.Y0, x+q.X1, y+q.Y1, q.U0, q.V0, q.U1, q.V1, color)
+    }
+}
+
+// Draws the queued text with the given projection - usually Window.PixelOrtho - alpha blended over the bound
+// framebuffer without depth testing. The blending and depth state are restored afterwards.
+func (t *TextRenderer) Flush(projection Mat4) error {
+    if t.batch.len() == 0 {
+        return nil
+    }
+
+    var state passState
+    state.save()
+    defer state.restore()
+
+    gl.Disable(gl.DEPTH_TEST)
+    gl.Disable(gl.CULL_FACE)
+    gl.Enable(gl.BLEND)
+    gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+    t.prog.Use()
+
+    if err := t.prog.Mat4("projection", projection); err != nil {
+        return err
+    }
+    if err := t.prog.Integer("glyphs", 0); err != nil {
+        return err
+    }
+    if err := t.prog.Bool("sdf", t.Face.SDF); err != nil {
+        return err
+    }
+
+    t.atlas.Texture.Bind(TextureUnit0)
+    t.batch.flush()
+
+    return nil
+}
+
+// deletes the atlas texture, program and buffers
+func (t *TextRenderer) Delete() {
+    t.atlas.Texture.Delete()
+    t.prog.Delete()
+    t.batch.delete()
+}
+
+const textFragmentSource = `#version 330 core
+out vec4 FragColor;
+
+in vec2 texCoord;
+in vec4 color;
+
+uniform sampler2D glyphs;
+uniform bool sdf;
+
+void main()
+{
+    float alpha = texture(glyphs, texCoord).a;
+
+    // distance fields are cut at the outline, smoothed over about a pixel whatever the scale
+    if (sdf) {
+        float width = max(fwidth(alpha) * 0.75, 1e-4);
+        alpha = smoothstep(0.5 - width, 0.5 + width, alpha);
+    }
+
+    FragColor = vec4(color.rgb, color.a * alpha);
+}
+` + "\x00"
@@ -0,0 +1,466 @@
+// Package text rasterises fonts into glyph atlases and lays out strings as textured quads. TrueType and OpenType
+// outlines are read with golang.org/x/image/font/sfnt and rasterised with golang.org/x/image/vector (the
+// font/opentype Face of the x/image version in use cannot rasterise glyphs), and any other font.Face - such as the
+// bitmap faces of font/basicfont - can be used as is. Everything here is pure Go, the atlas image is uploaded and the
+// quads drawn by render.TextRenderer.
+package text
+
+import (
+    "errors"
+    "fmt"
+    "golang.org/x/image/font"
+    "golang.org/x/image/font/sfnt"
+    "golang.org/x/image/math/fixed"
+    "golang.org/x/image/vector"
+    "image"
+    "image/draw"
+    "io/ioutil"
+    "logl/render/atlas"
+    "math"
+    "strconv"
+)
+
+// Font is a parsed TrueType or OpenType font, from which faces of any size are rasterised
+type Font struct {
+    f   *sfnt.Font
+    buf sfnt.Buffer
+}
+
+// Reads a TrueType (.ttf) or OpenType (.otf) font from the given path
+func ReadFont(path string) (*Font, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    return ParseFont(data)
+}
+
+// Parses a TrueType or OpenType font held in memory, which must not be modified afterwards
+func ParseFont(data []byte) (*Font, error) {
+    f, err := sfnt.Parse(data)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Font{f: f}, nil
+}
+
+// the full name of the font, empty when it has none
+func (f *Font) Name() string {
+    name, err := f.f.Name(&f.buf, sfnt.NameIDFull)
+    if err != nil {
+        return ""
+    }
+    return name
+}
+
+// the printable ASCII and Latin-1 characters, rasterised when FaceOpts.Runes is empty
+func DefaultRunes() []rune {
+    var runes []rune
+    for r := rune(0x20); r <= 0x7e; r++ {
+        runes = append(runes, r)
+    }
+    for r := rune(0xa0); r <= 0xff; r++ {
+        runes = append(runes, r)
+    }
+    return runes
+}
+
+// options used when rasterising a face
+type FaceOpts struct {
+    // the size of the em square in pixels
+    Size float32
+
+    // the characters rasterised into the atlas, DefaultRunes when empty. Others are drawn with the font's missing
+    // glyph.
+    Runes []rune
+
+    // rasterise signed distance fields rather than coverage, so the text stays sharp scaled well beyond Size
+    SDF bool
+
+    // how far in pixels the distance field reaches either side of the outlines, 4 when 0
+    Spread int
+
+    // the largest width or height the atlas may grow to, 2048 when 0
+    MaxAtlasSize int
+}
+
+// Glyph is the atlas region of a character along with how it is placed relative to the pen
+type Glyph struct {
+    Region atlas.Region
+
+    // the offset in pixels of the top left of the glyph image from the pen position on the baseline, y down
+    X, Y float32
+
+    // the size of the glyph image in pixels, zero for glyphs with nothing to draw (such as spaces)
+    Width, Height float32
+
+    // how far the pen moves after the glyph
+    Advance float32
+}
+
+// Face is a font rasterised at one size into a glyph atlas
+type Face struct {
+    Atlas *atlas.Atlas
+
+    // the size of the em square in pixels
+    Size float32
+
+    // whether the atlas holds signed distance fields, and how far either side of the outlines they reach in pixels
+    SDF    bool
+    Spread float32
+
+    // the distances from the baseline up to the top of a line and down to its bottom, and the recommended distance
+    // between baselines, in pixels
+    Ascent     float32
+    Descent    float32
+    LineHeight float32
+
+    glyphs  map[rune]*Glyph
+    missing *Glyph
+    kerning map[[2]rune]float32
+    kern    func(a, b rune) float32
+}
+
+// a rasterised glyph before packing
+type glyphImage struct {
+    name  string
+    glyph *Glyph
+    img   *image.Alpha
+}
+
+// Rasterises a face of the font into a glyph atlas
+func NewFace(f *Font, opts FaceOpts) (*Face, error) {
+    if opts.Size <= 0 {
+        return nil, fmt.Errorf("invalid face size: size = %v", opts.Size)
+    }
+
+    if opts.Spread <= 0 {
+        opts.Spread = 4
+    }
+
+    ppem := fixed.Int26_6(opts.Size * 64)
+
+    metrics, err := f.f.Metrics(&f.buf, ppem, font.HintingNone)
+    if err != nil {
+        return nil, err
+    }
+
+    face := newFace(opts, metrics)
+    face.kern = func(a, b rune) float32 {
+        ia, _ := f.f.GlyphIndex(&f.buf, a)
+        ib, _ := f.f.GlyphIndex(&f.buf, b)
+
+        k, err := f.f.Kern(&f.buf, ia, ib, ppem, font.HintingNone)
+        if err != nil {
+            return 0
+        }
+        return fromFixed(k)
+    }
+
+    rasterise := func(index sfnt.GlyphIndex) (*glyphImage, error) {
+        advance, err := f.f.GlyphAdvance(&f.buf, index, ppem, font.HintingNone)
+        if err != nil {
+            return nil, err
+        }
+
+        g := &glyphImage{glyph: &Glyph{Advance: fromFixed(advance)}}
+
+        if opts.SDF {
+            err = f.rasteriseSDF(g, index, opts)
+        } else {
+            err = f.rasterise(g, index, opts.Size)
+        }
+
+        return g, err
+    }
+
+    // glyph 0 is the missing glyph of every font
+    missing, err := rasterise(0)
+    if err != nil {
+        return nil, err
+    }
+    missing.name = "missing"
+
+    images := []*glyphImage{missing}
+
+    for _, r := range faceRunes(opts) {
+        index, err := f.f.GlyphIndex(&f.buf, r)
+        if err != nil {
+            return nil, err
+        }
+
+        if _, ok := face.glyphs[r]; ok || index == 0 {
+            continue
+        }
+
+        g, err := rasterise(index)
+        if err != nil {
+            return nil, fmt.Errorf("failed to rasterise glyph: rune = %q, error = %s", r, err)
+        }
+        g.name = strconv.Itoa(int(r))
+
+        face.glyphs[r] = g.glyph
+        images = append(images, g)
+    }
+
+    face.missing = missing.glyph
+
+    return face, face.pack(images, opts)
+}
+
+// Rasterises the glyphs of any font.Face into an atlas - used for bitmap fonts such as basicfont.Face7x13, which
+// have a single size. Signed distance fields are not available here.
+func NewBitmapFace(src font.Face, runes []rune) (*Face, error) {
+    opts := FaceOpts{Runes: runes}
+    metrics := src.Metrics()
+
+    face := newFace(opts, metrics)
+    face.Size = fromFixed(metrics.Ascent + metrics.Descent)
+    face.kern = func(a, b rune) float32 {
+        return fromFixed(src.Kern(a, b))
+    }
+
+    var images []*glyphImage
+
+    // the replacement character stands in for missing glyphs when the face has one
+    for i, r := range append([]rune{0xfffd}, faceRunes(opts)...) {
+        if _, ok := face.glyphs[r]; ok || (i > 0 && r == 0xfffd) {
+            continue
+        }
+
+        bounds, mask, maskp, advance, ok := src.Glyph(fixed.Point26_6{}, r)
+        if !ok {
+            continue
+        }
+
+        g := &glyphImage{
+            name: strconv.Itoa(int(r)),
+            glyph: &Glyph{
+                X:       float32(bounds.Min.X),
+                Y:       float32(bounds.Min.Y),
+                Width:   float32(bounds.Dx()),
+                Height:  float32(bounds.Dy()),
+                Advance: fromFixed(advance),
+            },
+        }
+
+        if !bounds.Empty() {
+            g.img = image.NewAlpha(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+            draw.Draw(g.img, g.img.Bounds(), mask, maskp, draw.Src)
+        }
+
+        if r == 0xfffd {
+            face.missing = g.glyph
+        } else {
+            face.glyphs[r] = g.glyph
+        }
+
+        images = append(images, g)
+    }
+
+    if face.missing == nil {
+        face.missing = &Glyph{}
+    }
+
+    return face, face.pack(images, opts)
+}
+
+func faceRunes(opts FaceOpts) []rune {
+    if len(opts.Runes) == 0 {
+        return DefaultRunes()
+    }
+    return opts.Runes
+}
+
+func newFace(opts FaceOpts, metrics font.Metrics) *Face {
+    face := &Face{
+        Size:       opts.Size,
+        SDF:        opts.SDF,
+        Ascent:     fromFixed(metrics.Ascent),
+        Descent:    fromFixed(metrics.Descent),
+        LineHeight: fromFixed(metrics.Height),
+        glyphs:     make(map[rune]*Glyph),
+        kerning:    make(map[[2]rune]float32),
+    }
+
+    if opts.SDF {
+        face.Spread = float32(opts.Spread)
+    }
+
+    if face.LineHeight <= 0 {
+        face.LineHeight = face.Ascent + face.Descent
+    }
+
+    return face
+}
+
+// packs the glyph images into the atlas, filling in the glyph regions
+func (f *Face) pack(images []*glyphImage, opts FaceOpts) error {
+    maxSize := opts.MaxAtlasSize
+    if maxSize <= 0 {
+        maxSize = 2048
+    }
+
+    builder := atlas.NewBuilder(maxSize, 1)
+    for _, g := range images {
+        if g.img == nil {
+            continue
+        }
+
+        if err := builder.Add(g.name, g.img); err != nil {
+            return err
+        }
+    }
+
+    if builder.Len() == 0 {
+        return errors.New("face has no glyphs to draw")
+    }
+
+    a, err := builder.Build()
+    if err != nil {
+        return err
+    }
+
+    for _, g := range images {
+        if g.img != nil {
+            g.glyph.Region = a.Regions[g.name]
+        }
+    }
+
+    f.Atlas = a
+
+    return nil
+}
+
+// the glyph drawn for the character, the missing glyph when it isn't in the atlas
+func (f *Face) Glyph(r rune) *Glyph {
+    if g, ok := f.glyphs[r]; ok {
+        return g
+    }
+    return f.missing
+}
+
+// reports whether the character was rasterised into the atlas
+func (f *Face) HasGlyph(r rune) bool {
+    _, ok := f.glyphs[r]
+    return ok
+}
+
+// the adjustment in pixels to the pen between two characters, negative to bring them closer
+func (f *Face) Kern(a, b rune) float32 {
+    key := [2]rune{a, b}
+
+    k, ok := f.kerning[key]
+    if !ok {
+        k = f.kern(a, b)
+        f.kerning[key] = k
+    }
+
+    return k
+}
+
+func fromFixed(x fixed.Int26_6) float32 {
+    return float32(x) / 64
+}
+
+// the outline of a glyph at the given size, in pixels with y down from the pen position on the baseline
+func (f *Font) outline(index sfnt.GlyphIndex, size float32) ([]sfnt.Segment, image.Rectangle, error) {
+    segments, err := f.f.LoadGlyph(&f.buf, index, fixed.Int26_6(size*64), nil)
+    if err != nil {
+        return nil, image.Rectangle{}, err
+    }
+
+    if len(segments) == 0 {
+        return nil, image.Rectangle{}, nil
+    }
+
+    // the control points bound the curves, so they give a safe (if loose) bounding box
+    minX, minY := float32(math.MaxFloat32), float32(math.MaxFloat32)
+    maxX, maxY := float32(-math.MaxFloat32), float32(-math.MaxFloat32)
+
+    for _, s := range segments {
+        for _, p := range s.Args[:segmentPoints(s.Op)] {
+            x, y := fromFixed(p.X), fromFixed(p.Y)
+            minX, minY = min32(minX, x), min32(minY, y)
+            maxX, maxY = max32(maxX, x), max32(maxY, y)
+        }
+    }
+
+    bounds := image.Rect(int(math.Floor(float64(minX))), int(math.Floor(float64(minY))),
+        int(math.Ceil(float64(maxX))), int(math.Ceil(float64(maxY))))
+
+    return segments, bounds, nil
+}
+
+func segmentPoints(op sfnt.SegmentOp) int {
+    switch op {
+    case sfnt.SegmentOpQuadTo:
+        return 2
+    case sfnt.SegmentOpCubeTo:
+        return 3
+    default:
+        return 1
+    }
+}
+
+// rasterises segments scaled by the given factor and offset by -origin into a coverage mask of the given size
+func rasteriseSegments(segments []sfnt.Segment, scale float32, origin image.Point, size image.Point) *image.Alpha {
+    r := vector.NewRasterizer(size.X, size.Y)
+
+    point := func(p fixed.Point26_6) (float32, float32) {
+        return fromFixed(p.X)*scale - float32(origin.X), fromFixed(p.Y)*scale - float32(origin.Y)
+    }
+
+    for _, s := range segments {
+        ax, ay := point(s.Args[0])
+
+        switch s.Op {
+        case sfnt.SegmentOpMoveTo:
+            r.MoveTo(ax, ay)
+        case sfnt.SegmentOpLineTo:
+            r.LineTo(ax, ay)
+        case sfnt.SegmentOpQuadTo:
+            bx, by := point(s.Args[1])
+            r.QuadTo(ax, ay, bx, by)
+        case sfnt.SegmentOpCubeTo:
+            bx, by := point(s.Args[1])
+            cx, cy := point(s.Args[2])
+            r.CubeTo(ax, ay, bx, by, cx, cy)
+        }
+    }
+
+    mask := image.NewAlpha(image.Rect(0, 0, size.X, size.Y))
+    r.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+
+    return mask
+}
+
+// rasterises the coverage of a glyph
+func (f *Font) rasterise(g *glyphImage, index sfnt.GlyphIndex, size float32) error {
+    segments, bounds, err := f.outline(index, size)
+    if err != nil || bounds.Empty() {
+        return err
+    }
+
+    g.img = rasteriseSegments(segments, 1, bounds.Min, bounds.Size())
+    g.glyph.X, g.glyph.Y = float32(bounds.Min.X), float32(bounds.Min.Y)
+    g.glyph.Width, g.glyph.Height = float32(bounds.Dx()), float32(bounds.Dy())
+
+    return nil
+}
+
+func min32(a, b float32) float32 {
+    if a < b {
+        return a
+    }
+    return b
+}
+
+func max32(a, b float32) float32 {
+    if a > b {
+        return a
+    }
+    return b
+}
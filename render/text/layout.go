@@ -0,0 +1,251 @@
+package text
+
+import (
+    "math"
+    "unicode"
+)
+
+// the horizontal alignment of lines within a text block
+type Align int
+
+const (
+    AlignLeft Align = iota
+    AlignCenter
+    AlignRight
+)
+
+func (a Align) String() string {
+    switch a {
+    case AlignLeft:
+        return "Left"
+    case AlignCenter:
+        return "Center"
+    case AlignRight:
+        return "Right"
+    default:
+        return "Unknown"
+    }
+}
+
+// options used when laying out text
+type LayoutOpts struct {
+    // lines longer than this many pixels are wrapped at spaces (or between characters for words that don't fit on
+    // a line of their own), 0 for no wrapping
+    MaxWidth float32
+
+    // how lines are aligned within MaxWidth, or within the longest line when not wrapping
+    Align Align
+
+    // multiplies the line height of the face, 1 when 0
+    LineSpacing float32
+
+    // multiplies the size of the face, 1 when 0. Scaling coverage faces blurs them, use a distance field face for
+    // text drawn at many sizes.
+    Scale float32
+}
+
+// a textured rectangle drawing one glyph, in pixels with y down
+type Quad struct {
+    X0, Y0, X1, Y1 float32
+
+    // the texture coordinates of the top left (U0, V0) and bottom right (U1, V1) corners in the atlas
+    U0, V0, U1, V1 float32
+}
+
+// Layout is a block of text laid out from the origin at its top left, y down
+type Layout struct {
+    Quads []Quad
+
+    // the size of the block in pixels - the width of its longest line (or MaxWidth when wrapping) and the height of
+    // its lines
+    Width  float32
+    Height float32
+
+    // the number of lines after wrapping
+    Lines int
+}
+
+// a line of characters and its width without trailing spaces
+type line struct {
+    runes []rune
+    width float32
+}
+
+// Lays out UTF-8 text (invalid bytes draw as U+FFFD) into quads, breaking lines at newlines and wrapping them to
+// MaxWidth. Kerning is applied between every pair of characters on a line. Coverage faces drawn unscaled are kept on
+// whole pixels so they stay sharp.
+func (f *Face) Layout(s string, opts LayoutOpts) *Layout {
+    scale := opts.Scale
+    if scale <= 0 {
+        scale = 1
+    }
+
+    spacing := opts.LineSpacing
+    if spacing <= 0 {
+        spacing = 1
+    }
+
+    lines := f.wrap(s, opts.MaxWidth/scale)
+
+    blockWidth := opts.MaxWidth
+    if blockWidth <= 0 {
+        for _, l := range lines {
+            blockWidth = float32(math.Max(float64(blockWidth), float64(l.width*scale)))
+        }
+    }
+
+    snap := !f.SDF && scale == 1
+    lineHeight := f.LineHeight * spacing * scale
+
+    layout := &Layout{
+        Width:  blockWidth,
+        Height: float32(len(lines)-1)*lineHeight + (f.Ascent+f.Descent)*scale,
+        Lines:  len(lines),
+    }
+
+    for i, l := range lines {
+        x := float32(0)
+        switch opts.Align {
+        case AlignCenter:
+            x = (blockWidth - l.width*scale) / 2
+        case AlignRight:
+            x = blockWidth - l.width*scale
+        }
+
+        baseline := f.Ascent*scale + float32(i)*lineHeight
+
+        var prev rune
+        for j, r := range l.runes {
+            if j > 0 {
+                x += f.Kern(prev, r) * scale
+            }
+            prev = r
+
+            g := f.Glyph(r)
+
+            if g.Width > 0 {
+                x0, y0 := x+g.X*scale, baseline+g.Y*scale
+                if snap {
+                    x0, y0 = float32(math.Round(float64(x0))), float32(math.Round(float64(y0)))
+                }
+
+                layout.Quads = append(layout.Quads, Quad{
+                    X0: x0,
+                    Y0: y0,
+                    X1: x0 + g.Width*scale,
+                    Y1: y0 + g.Height*scale,
+                    U0: g.Region.U0,
+                    V0: g.Region.V0,
+                    U1: g.Region.U1,
+                    V1: g.Region.V1,
+                })
+            }
+
+            x += g.Advance * scale
+        }
+    }
+
+    return layout
+}
+
+// the size in pixels of the block the text lays out into
+func (f *Face) Measure(s string, opts LayoutOpts) (float32, float32) {
+    layout := f.Layout(s, opts)
+    return layout.Width, layout.Height
+}
+
+// splits text into lines at newlines, wrapping any wider than maxWidth (unscaled pixels, 0 for no limit)
+func (f *Face) wrap(s string, maxWidth float32) []line {
+    var lines []line
+    var runes []rune
+
+    for _, r := range s + "\n" {
+        if r != '\n' {
+            if r != '\t' && unicode.IsControl(r) {
+                continue
+            }
+            if r == '\t' {
+                r = ' '
+            }
+
+            runes = append(runes, r)
+            continue
+        }
+
+        lines = append(lines, f.wrapParagraph(runes, maxWidth)...)
+        runes = nil
+    }
+
+    return lines
+}
+
+// greedily fills lines with as many words as fit, the spaces at a break are dropped
+func (f *Face) wrapParagraph(runes []rune, maxWidth float32) []line {
+    if maxWidth <= 0 {
+        return []line{{runes: runes, width: f.width(runes)}}
+    }
+
+    var lines []line
+
+    for {
+        end, next := f.fit(runes, maxWidth)
+        if next >= len(runes) {
+            return append(lines, f.trimmed(runes))
+        }
+
+        lines = append(lines, f.trimmed(runes[:end]))
+        runes = runes[next:]
+    }
+}
+
+// the end of the first line of runes no wider than maxWidth, and where the next line starts
+func (f *Face) fit(runes []rune, maxWidth float32) (int, int) {
+    x := float32(0)
+    lastSpace := -1
+
+    for i, r := range runes {
+        if i > 0 {
+            x += f.Kern(runes[i-1], r)
+        }
+
+        if r == ' ' {
+            lastSpace = i
+        } else if i > 0 && x+f.Glyph(r).Advance > maxWidth {
+            // break after the last space on the line, or mid word when there is none
+            if lastSpace > 0 {
+                next := lastSpace
+                for next < len(runes) && runes[next] == ' ' {
+                    next++
+                }
+                return lastSpace, next
+            }
+            return i, i
+        }
+
+        x += f.Glyph(r).Advance
+    }
+
+    return len(runes), len(runes)
+}
+
+// the line without its trailing spaces
+func (f *Face) trimmed(runes []rune) line {
+    end := len(runes)
+    for end > 0 && runes[end-1] == ' ' {
+        end--
+    }
+
+    return line{runes: runes[:end], width: f.width(runes[:end])}
+}
+
+// the advance of the pen across the runes in unscaled pixels
+func (f *Face) width(runes []rune) float32 {
+    x := float32(0)
+    for i, r := range runes {
+        if i > 0 {
+            x += f.Kern(runes[i-1], r)
+        }
+        x += f.Glyph(r).Advance
+    }
+    return x
+}
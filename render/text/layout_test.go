@@ -0,0 +1,183 @@
+package text
+
+import (
+    "golang.org/x/image/font/basicfont"
+    "strings"
+    "testing"
+)
+
+// a face with 7 pixel advances, no kerning and 13 pixel lines
+func testFace(t *testing.T) *Face {
+    f, err := NewBitmapFace(basicfont.Face7x13, nil)
+    if err != nil {
+        t.Fatalf("creating face: %v", err)
+    }
+    return f
+}
+
+// a laid out line recovered from its quads
+type laidOutLine struct {
+    text string
+    x    float32
+}
+
+// reads the lines back out of unscaled layout, identifying each quad's character by its atlas region
+func readLines(f *Face, s string, layout *Layout) []laidOutLine {
+    runes := map[[2]float32]rune{}
+    for _, r := range s + " " {
+        region := f.Glyph(r).Region
+        runes[[2]float32{region.U0, region.V0}] = r
+    }
+
+    lines := make([]laidOutLine, layout.Lines)
+    for i := range lines {
+        lines[i].x = -1
+    }
+
+    for _, q := range layout.Quads {
+        i := int(q.Y0 / f.LineHeight)
+        if lines[i].x < 0 {
+            lines[i].x = q.X0
+        }
+        lines[i].text += string(runes[[2]float32{q.U0, q.V0}])
+    }
+
+    return lines
+}
+
+func TestLayoutLines(t *testing.T) {
+    f := testFace(t)
+
+    tests := []struct {
+        name     string
+        text     string
+        maxWidth float32
+        expected []string
+    }{
+        {"single line", "hello world", 0, []string{"hello world"}},
+        {"wrap at space", "hello world", 56, []string{"hello", "world"}},
+        {"wrap at last space", "one two three", 63, []string{"one two", "three"}},
+        {"spaces dropped at break", "hello   world", 56, []string{"hello", "world"}},
+        {"exact fit", "hello", 35, []string{"hello"}},
+        {"mid word break", "abcdefghij", 28, []string{"abcd", "efgh", "ij"}},
+        {"long word after space", "a bcdefgh", 28, []string{"a", "bcde", "fgh"}},
+        {"blank line", "a\n\nb", 0, []string{"a", "", "b"}},
+        {"trailing newline", "a\n", 0, []string{"a", ""}},
+        {"empty", "", 0, []string{""}},
+        {"tab as space", "a\tb", 0, []string{"a b"}},
+        {"wrap at tab", "ab\tcd", 21, []string{"ab", "cd"}},
+        {"control characters dropped", "a\rb\x00c", 0, []string{"abc"}},
+    }
+
+    for _, test := range tests {
+        layout := f.Layout(test.text, LayoutOpts{MaxWidth: test.maxWidth})
+
+        if layout.Lines != len(test.expected) {
+            t.Errorf("%s: %d lines, expected %d", test.name, layout.Lines, len(test.expected))
+            continue
+        }
+
+        for i, l := range readLines(f, test.text, layout) {
+            if l.text != test.expected[i] {
+                t.Errorf("%s: line %d is %q, expected %q", test.name, i, l.text, test.expected[i])
+            }
+
+            if l.text != "" && l.x != 0 {
+                t.Errorf("%s: line %d starts at %v", test.name, i, l.x)
+            }
+        }
+
+        // the pen advances a whole glyph per character
+        for i := 1; i < len(layout.Quads); i++ {
+            a, b := layout.Quads[i-1], layout.Quads[i]
+            if a.Y0 == b.Y0 && b.X0-a.X0 != 7 {
+                t.Errorf("%s: quads %d and %d are %v apart", test.name, i-1, i, b.X0-a.X0)
+                break
+            }
+        }
+    }
+}
+
+func TestLayoutAlign(t *testing.T) {
+    f := testFace(t)
+    s := "ab\nabcd"
+
+    tests := []struct {
+        align    Align
+        maxWidth float32
+
+        // where each line starts
+        x []float32
+    }{
+        {AlignLeft, 0, []float32{0, 0}},
+        {AlignCenter, 0, []float32{7, 0}},
+        {AlignRight, 0, []float32{14, 0}},
+        {AlignLeft, 70, []float32{0, 0}},
+        {AlignCenter, 70, []float32{28, 21}},
+        {AlignRight, 70, []float32{56, 42}},
+
+        // the second line wrapped into abc and d
+        {AlignCenter, 21, []float32{3.5, 0, 7}},
+        {AlignRight, 21, []float32{7, 0, 14}},
+    }
+
+    for _, test := range tests {
+        layout := f.Layout(s, LayoutOpts{MaxWidth: test.maxWidth, Align: test.align})
+        lines := readLines(f, s, layout)
+
+        if len(lines) != len(test.x) {
+            t.Errorf("%v in %v: %d lines, expected %d", test.align, test.maxWidth, len(lines), len(test.x))
+            continue
+        }
+
+        for i, l := range lines {
+            // coverage faces are snapped to whole pixels
+            if l.x != test.x[i] && l.x != test.x[i]+0.5 {
+                t.Errorf("%v in %v: line %d starts at %v, expected %v", test.align, test.maxWidth, i, l.x, test.x[i])
+            }
+        }
+
+        for _, q := range layout.Quads {
+            if q.X0 < 0 || q.X1 > layout.Width {
+                t.Errorf("%v in %v: quad %v-%v outside the block width %v", test.align, test.maxWidth, q.X0, q.X1,
+                    layout.Width)
+                break
+            }
+        }
+    }
+}
+
+func TestMeasure(t *testing.T) {
+    f := testFace(t)
+
+    tests := []struct {
+        name          string
+        text          string
+        opts          LayoutOpts
+        width, height float32
+    }{
+        {"single line", "hello world", LayoutOpts{}, 77, 13},
+        {"longest line", "hello\nhi", LayoutOpts{}, 35, 26},
+        {"wrapped", "hello world", LayoutOpts{MaxWidth: 56}, 56, 26},
+        {"wider than the text", "hi", LayoutOpts{MaxWidth: 100}, 100, 13},
+        {"blank lines", "a\n\n\nb", LayoutOpts{}, 7, 52},
+        {"empty", "", LayoutOpts{}, 0, 13},
+        {"line spacing", "a\nb", LayoutOpts{LineSpacing: 2}, 7, 39},
+        {"scaled", "hello", LayoutOpts{Scale: 2}, 70, 26},
+        {"scaled and wrapped", "hello world", LayoutOpts{MaxWidth: 112, Scale: 2}, 112, 52},
+    }
+
+    for _, test := range tests {
+        width, height := f.Measure(test.text, test.opts)
+        if width != test.width || height != test.height {
+            t.Errorf("%s: measured %v x %v, expected %v x %v", test.name, width, height, test.width, test.height)
+        }
+    }
+
+    // measuring agrees with the layout
+    s := strings.Repeat("word ", 20)
+    layout := f.Layout(s, LayoutOpts{MaxWidth: 90})
+    if width, height := f.Measure(s, LayoutOpts{MaxWidth: 90}); width != layout.Width || height != layout.Height {
+        t.Errorf("measured %v x %v, laid out %v x %v", width, height, layout.Width, layout.Height)
+    }
+}
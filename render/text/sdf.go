@@ -0,0 +1,139 @@
+package text
+
+import (
+    "golang.org/x/image/font/sfnt"
+    "image"
+    "math"
+)
+
+// the factor glyphs are oversampled by when computing distance fields
+const sdfOversample = 4
+
+// a distance larger than any in a glyph image
+const sdfInfinity = 1e20
+
+// Rasterises the signed distance field of a glyph. The outline is rasterised oversampled, the distances to the
+// nearest texel on the other side of the edge found with an exact Euclidean distance transform and then sampled down.
+// Distances are stored as 0.5 on the edge, rising to 1 at Spread pixels inside and falling to 0 at Spread pixels
+// outside.
+func (f *Font) rasteriseSDF(g *glyphImage, index sfnt.GlyphIndex, opts FaceOpts) error {
+    segments, bounds, err := f.outline(index, opts.Size)
+    if err != nil || bounds.Empty() {
+        return err
+    }
+
+    bounds = bounds.Inset(-opts.Spread)
+    size := bounds.Size()
+
+    mask := rasteriseSegments(segments, sdfOversample, bounds.Min.Mul(sdfOversample), size.Mul(sdfOversample))
+    width, height := mask.Rect.Dx(), mask.Rect.Dy()
+
+    // squared distances to the nearest inside texel, and to the nearest outside texel
+    inside := make([]float32, width*height)
+    outside := make([]float32, width*height)
+
+    for i, a := range mask.Pix {
+        if a >= 0x80 {
+            inside[i], outside[i] = 0, sdfInfinity
+        } else {
+            inside[i], outside[i] = sdfInfinity, 0
+        }
+    }
+
+    distanceTransform(inside, width, height)
+    distanceTransform(outside, width, height)
+
+    img := image.NewAlpha(image.Rect(0, 0, size.X, size.Y))
+    spread := float64(opts.Spread)
+
+    for y := 0; y < size.Y; y++ {
+        for x := 0; x < size.X; x++ {
+            i := (y*sdfOversample+sdfOversample/2)*width + x*sdfOversample + sdfOversample/2
+
+            distance := (math.Sqrt(float64(outside[i])) - math.Sqrt(float64(inside[i]))) / sdfOversample
+            value := math.Max(0, math.Min(1, 0.5+distance/(2*spread)))
+
+            img.Pix[y*img.Stride+x] = uint8(value*255 + 0.5)
+        }
+    }
+
+    g.img = img
+    g.glyph.X, g.glyph.Y = float32(bounds.Min.X), float32(bounds.Min.Y)
+    g.glyph.Width, g.glyph.Height = float32(size.X), float32(size.Y)
+
+    return nil
+}
+
+// replaces every value of the grid with the smallest squared distance to a texel plus that texel's value - the
+// separable transform of Felzenszwalb and Huttenlocher, a column pass then a row pass
+func distanceTransform(grid []float32, width, height int) {
+    n := width
+    if height > n {
+        n = height
+    }
+
+    f := make([]float32, n)
+    d := make([]float32, n)
+    v := make([]int, n)
+    z := make([]float32, n+1)
+
+    for x := 0; x < width; x++ {
+        for y := 0; y < height; y++ {
+            f[y] = grid[y*width+x]
+        }
+
+        distanceTransform1D(f[:height], d, v, z)
+
+        for y := 0; y < height; y++ {
+            grid[y*width+x] = d[y]
+        }
+    }
+
+    for y := 0; y < height; y++ {
+        row := grid[y*width : (y+1)*width]
+        copy(f, row)
+
+        distanceTransform1D(f[:width], d, v, z)
+        copy(row, d[:width])
+    }
+}
+
+// the one dimensional transform, the lower envelope of the parabolas rooted at each sample
+func distanceTransform1D(f, d []float32, v []int, z []float32) {
+    k := 0
+    v[0] = 0
+    z[0], z[1] = -sdfInfinity, sdfInfinity
+
+    for q := 1; q < len(f); q++ {
+        var s float32
+        for {
+            r := v[k]
+            s = ((f[q] + float32(q*q)) - (f[r] + float32(r*r))) / float32(2*q-2*r)
+            if s > z[k] || k == 0 {
+                break
+            }
+            k--
+        }
+
+        if s <= z[k] {
+            // only reached with k == 0, the new parabola is lowest everywhere so far
+            v[0] = q
+            z[0], z[1] = -sdfInfinity, sdfInfinity
+            continue
+        }
+
+        k++
+        v[k] = q
+        z[k], z[k+1] = s, sdfInfinity
+    }
+
+    k = 0
+    for q := range f {
+        for z[k+1] < float32(q) {
+            k++
+        }
+
+        r := v[k]
+        d[q] = float32((q-r)*(q-r)) + f[r]
+    }
+}
@@ -15,6 +15,8 @@ func main() {
         os.Exit(1)
     }
 
+    defer window.Destroy()
+
     vertices := []float32 {
         // positions      // colors
          0.5, -0.5, 0.0,  1.0, 0.0, 0.0,   // bottom right
@@ -44,13 +46,13 @@ func main() {
 
     window.ClearColor(render.White)
 
-    var vao, vbo uint32
-    gl.GenVertexArrays(1, &vao)
-    gl.GenBuffers(1, &vbo)
+    vao := render.NewVertexArray()
+    defer vao.Delete()
+    vbo := render.NewBuffer(render.ArrayBuffer)
+    defer vbo.Delete()
 
-    gl.BindVertexArray(vao)
-    gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-    gl.BufferData(gl.ARRAY_BUFFER, len(vertices) * 4, gl.Ptr(vertices), gl.STATIC_DRAW)
+    vao.Bind()
+    vbo.Data(len(vertices) * 4, vertices, render.StaticDraw)
 
     // position attribute
     gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 6 * 4, nil)
@@ -67,7 +69,7 @@ func main() {
             fmt.Println(err)
             os.Exit(1)
         }
-        gl.BindVertexArray(vao)
+        vao.Bind()
         gl.DrawArrays(gl.TRIANGLES, 0, 6)
     })
 
@@ -44,17 +44,16 @@ func main() {
         0.5, -0.5, 0,
     }
 
-    var vao uint32
-    gl.GenVertexArrays(1, &vao)
+    vao := render.NewVertexArray()
+    defer vao.Delete()
 
-    var vbo uint32
-    gl.GenBuffers(1, &vbo)
+    vbo := render.NewBuffer(render.ArrayBuffer)
+    defer vbo.Delete()
 
     // setup the vao object
-    gl.BindVertexArray(vao)
+    vao.Bind()
 
-    gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-    gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+    vbo.Data(len(vertices)*4, vertices, render.StaticDraw)
 
     gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 12, nil)
     gl.EnableVertexAttribArray(0)
@@ -78,7 +77,7 @@ func main() {
             os.Exit(1)
         }
 
-        gl.BindVertexArray(vao)
+        vao.Bind()
         gl.DrawArrays(gl.TRIANGLES, 0, 3)
     })
 
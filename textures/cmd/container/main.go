@@ -15,6 +15,8 @@ func main() {
         os.Exit(1)
     }
 
+    defer window.Destroy()
+
     vsh, err := render.ReadShader(render.VertexShader, "vert.glsl")
     if err != nil {
         fmt.Println(err)
@@ -44,6 +46,11 @@ func main() {
         FlipY: false,
     })
 
+    if err != nil {
+        fmt.Println(err)
+        os.Exit(1)
+    }
+
     atexture, err := render.ReadTexture("awesomeface.png", render.TextureOpts{
         GenMipMap: true,
         WrapS: render.Repeat,
@@ -93,19 +100,13 @@ func main() {
         1, 2, 3,
     }
 
-    var vao, vbo, ebo uint32
-    gl.GenVertexArrays(1, &vao)
+    vao := render.NewVertexArray()
+    vbo := render.NewBuffer(render.ArrayBuffer)         // vertex
+    ebo := render.NewBuffer(render.ElementArrayBuffer)  // element order
 
-    gl.GenBuffers(1, &vbo)  // vertex
-    gl.GenBuffers(1, &ebo)  // element order
-
-    gl.BindVertexArray(vao)
-
-    gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-    gl.BufferData(gl.ARRAY_BUFFER, len(vertices) * 4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-    gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-    gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(elements) * 4, gl.Ptr(elements), gl.STATIC_DRAW)
+    vao.Bind()
+    vbo.Data(len(vertices) * 4, vertices, render.StaticDraw)
+    ebo.Data(len(elements) * 4, elements, render.StaticDraw)
 
     gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 32, gl.PtrOffset(0))
     gl.EnableVertexAttribArray(0)
@@ -121,17 +122,10 @@ func main() {
 
     var mixture float32 = 0.2
 
-    // texture uniforms - bind prog before use - one time only needed
-    prog.Use()
-    if err = prog.Integer("containerTexture", render.TextureUnit0.Index()); err != nil {
-        fmt.Println(err)
-        os.Exit(1)
-    }
-
-    if err = prog.Integer("awesomeTexture", render.TextureUnit1.Index()); err != nil {
-        fmt.Println(err)
-        os.Exit(1)
-    }
+    // texture uniforms - units are assigned by the material when bound
+    material := render.NewMaterial(prog)
+    material.SetTexture("containerTexture", ctexture)
+    material.SetTexture("awesomeTexture", atexture)
 
     window.Render(func() {
 
@@ -168,7 +162,10 @@ func main() {
             }
         }
 
-        prog.Use()
+        if err := material.Bind(); err != nil {
+            fmt.Println(err)
+            os.Exit(1)
+        }
 
         if err := prog.Float("mixture", mixture); err != nil {
             fmt.Println(err)
@@ -185,13 +182,14 @@ func main() {
             os.Exit(1)
         }
 
-
-        ctexture.Bind(render.TextureUnit0)
-        atexture.Bind(render.TextureUnit1)
-
-        gl.BindVertexArray(vao)
+        vao.Bind()
         gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
     })
 
-
+    vao.Delete()
+    vbo.Delete()
+    ebo.Delete()
+    ctexture.Delete()
+    atexture.Delete()
+    prog.Delete()
 }
@@ -5,6 +5,8 @@ import (
     "github.com/go-gl/gl/v3.3-core/gl"
     "github.com/go-gl/mathgl/mgl32"
     "logl/render"
+    "logl/render/scene"
+    "math"
     "os"
 )
 
@@ -28,6 +30,8 @@ func main() {
         os.Exit(1)
     }
 
+    defer window.Destroy()
+
     vsh, err := render.ReadShader(render.VertexShader, "vert.glsl")
     if err != nil {
         fmt.Println(err)
@@ -75,19 +79,19 @@ func main() {
         1, 2, 3,
     }
 
-    var vao, vbo, ebo uint32
-    gl.GenVertexArrays(1, &vao)
+    vao := render.NewVertexArray()
+    defer vao.Delete()
 
-    gl.GenBuffers(1, &vbo) // vertex
-    gl.GenBuffers(1, &ebo) // element order
+    vbo := render.NewBuffer(render.ArrayBuffer) // vertex
+    defer vbo.Delete()
+    ebo := render.NewBuffer(render.ElementArrayBuffer) // element order
+    defer ebo.Delete()
 
-    gl.BindVertexArray(vao)
+    vao.Bind()
 
-    gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-    gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+    vbo.Data(len(vertices)*4, vertices, render.StaticDraw)
 
-    gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-    gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(elements)*4, gl.Ptr(elements), gl.STATIC_DRAW)
+    ebo.Data(len(elements)*4, elements, render.StaticDraw)
 
     gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 32, gl.PtrOffset(0))
     gl.EnableVertexAttribArray(0)
@@ -98,9 +102,6 @@ func main() {
 
     window.ClearColor(render.White)
 
-    // trans = mgl32.HomogRotate3DZ(mgl32.DegToRad(90))
-    // trans = trans.Mul4(mgl32.Scale3D(0.5, 0.5, 0.5))
-
     // texture uniforms - bind prog before use - one time only needed
     prog.Use()
     if err = prog.Integer("awesomeTexture", render.TextureUnit0.Index()); err != nil {
@@ -108,35 +109,50 @@ func main() {
         os.Exit(1)
     }
 
-    window.Render(func() {
+    // the scene graph holds the ordering of the transforms - each node is scaled, then rotated, then translated
+    // relative to its parent, so the face spins in place rather than orbiting the origin
+    root := scene.NewNode("root")
 
-        prog.Use()
-        atexture.Bind(render.TextureUnit0)
-        gl.BindVertexArray(vao)
+    // rotate
+    face := scene.NewNode("face")
+    face.SetPosition(mgl32.Vec3{0.5, -0.5, 0})
+    face.Data = atexture
 
-        // rotate
-        // trans0 := mgl32.Translate3D(0.5, -0.5, 0)
-        // trans0 = trans0.Mul4(mgl32.HomogRotate3DZ(float32(window.Time())))
+    // scale - ex2
+    pulse := scene.NewNode("pulse")
+    pulse.SetPosition(mgl32.Vec3{-0.5, 0.5, 0})
+    pulse.Data = atexture
 
-        // flip around for fun and profit
-        // trans0 := mgl32.HomogRotate3DZ(float32(window.Time()))
-        // trans0 = trans0.Mul4(mgl32.Translate3D(0.5, -0.5, 0))
-        if err := prog.Mat4("transform", trans0); err != nil {
+    for _, node := range []*scene.Node{face, pulse} {
+        if err := root.AddChild(node); err != nil {
             fmt.Println(err)
             os.Exit(1)
         }
+    }
+
+    window.Render(func() {
+        face.SetRotation(mgl32.QuatRotate(float32(window.Time()), mgl32.Vec3{0, 0, 1}))
+        pulse.SetUniformScale(float32(math.Sin(window.Time())))
+
+        prog.Use()
+        vao.Bind()
+
+        root.Walk(func(node *scene.Node, world mgl32.Mat4) bool {
+            texture, ok := node.Data.(*render.Texture)
+            if !ok {
+                return true
+            }
+
+            texture.Bind(render.TextureUnit0)
+
+            if err := prog.Mat4("transform", world); err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
 
-        gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
-
-        // scale - ex2
-        // scale := float32(math.Sin(window.Time()))
-        // trans1 := mgl32.Translate3D(-0.5, 0.5, 0)
-        // trans1 = trans1.Mul4(mgl32.Scale3D(scale, scale, scale))
-        // if err := prog.Mat4("transform", trans1); err != nil {
-        //     fmt.Println(err)
-        //     os.Exit(1)
-        // }
-        // gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
+            gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
+            return true
+        })
     })
 
 }
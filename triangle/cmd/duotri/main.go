@@ -54,18 +54,17 @@ func main() {
     // var vao uint32
     // gl.GenVertexArrays(1, &vao)
 
-    var vbo uint32
-    gl.GenBuffers(1, &vbo)
+    vbo := render.NewBuffer(render.ArrayBuffer)
+    defer vbo.Delete()
 
     // set up the buffers
-    var vao uint32
-    gl.GenVertexArrays(1, &vao)
+    vao := render.NewVertexArray()
+    defer vao.Delete()
 
     // setup the vao object
-    gl.BindVertexArray(vao)
+    vao.Bind()
 
-    gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-    gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+    vbo.Data(len(vertices)*4, vertices, render.StaticDraw)
 
     gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 12, nil)
     gl.EnableVertexAttribArray(0)
@@ -74,7 +73,7 @@ func main() {
     window.Render(func() {
         gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
         prog.Use()
-        gl.BindVertexArray(vao)
+        vao.Bind()
 
         // draw vao
         gl.DrawArrays(gl.TRIANGLES, 0, 6)
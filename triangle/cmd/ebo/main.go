@@ -52,22 +52,20 @@ func main() {
     fsh.Delete()
     vsh.Delete()
 
-    var ebo uint32
-    gl.GenBuffers(1, &ebo)
+    ebo := render.NewBuffer(render.ElementArrayBuffer)
+    defer ebo.Delete()
 
-    var vbo uint32
-    gl.GenBuffers(1, &vbo)
+    vbo := render.NewBuffer(render.ArrayBuffer)
+    defer vbo.Delete()
 
-    var vao uint32
-    gl.GenVertexArrays(1, &vao)
+    vao := render.NewVertexArray()
+    defer vao.Delete()
 
-    gl.BindVertexArray(vao)
+    vao.Bind()
 
-    gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-    gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+    vbo.Data(len(vertices)*4, vertices, render.StaticDraw)
 
-    gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-    gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+    ebo.Data(len(indices)*4, indices, render.StaticDraw)
 
     gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 12, nil)
     gl.EnableVertexAttribArray(0)
@@ -95,7 +93,7 @@ func main() {
         }
 
         program.Use()
-        gl.BindVertexArray(vao)
+        vao.Bind()
         gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
 
     })
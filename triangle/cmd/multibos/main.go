@@ -15,6 +15,8 @@ func main() {
         os.Exit(1)
     }
 
+    defer window.Destroy()
+
     vsh, err := render.ReadShader(render.VertexShader, "../vert.glsl")
     if err != nil {
         fmt.Println(err)
@@ -35,13 +37,15 @@ func main() {
     vsh.Delete()
     fsh.Delete()
 
-    var vbo0, vbo1 uint32
-    gl.GenBuffers(1, &vbo0)
-    gl.GenBuffers(1, &vbo1)
+    vbo0 := render.NewBuffer(render.ArrayBuffer)
+    defer vbo0.Delete()
+    vbo1 := render.NewBuffer(render.ArrayBuffer)
+    defer vbo1.Delete()
 
-    var vao0, vao1 uint32
-    gl.GenVertexArrays(1, &vao0)
-    gl.GenVertexArrays(1, &vao1)
+    vao0 := render.NewVertexArray()
+    defer vao0.Delete()
+    vao1 := render.NewVertexArray()
+    defer vao1.Delete()
 
     vert0 := []float32{
         -1.0, 0.0, 0.0,
@@ -55,15 +59,13 @@ func main() {
         1.0, 0.0, 0.0,
     }
 
-    gl.BindVertexArray(vao0)
-    gl.BindBuffer(gl.ARRAY_BUFFER, vbo0)
-    gl.BufferData(gl.ARRAY_BUFFER, len(vert0)*4, gl.Ptr(vert0), gl.STATIC_DRAW)
+    vao0.Bind()
+    vbo0.Data(len(vert0)*4, vert0, render.StaticDraw)
     gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 12, nil)
     gl.EnableVertexAttribArray(0)
 
-    gl.BindVertexArray(vao1)
-    gl.BindBuffer(gl.ARRAY_BUFFER, vbo1)
-    gl.BufferData(gl.ARRAY_BUFFER, len(vert1)*4, gl.Ptr(vert1), gl.STATIC_DRAW)
+    vao1.Bind()
+    vbo1.Data(len(vert1)*4, vert1, render.StaticDraw)
     gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 12, nil)
     gl.EnableVertexAttribArray(0)
 
@@ -71,9 +73,9 @@ func main() {
     window.Render(func() {
         gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
         prog.Use()
-        gl.BindVertexArray(vao0)
+        vao0.Bind()
         gl.DrawArrays(gl.TRIANGLES, 0, 3)
-        gl.BindVertexArray(vao1)
+        vao1.Bind()
         gl.DrawArrays(gl.TRIANGLES, 0, 3)
     })
 }
@@ -190,18 +190,15 @@ void main()
 
     // set up the various VBOs and VAOs here before the render
     // buffer to store the data in
-    var vbo uint32
-    gl.GenBuffers(1, &vbo)
+    vbo := render.NewBuffer(render.ArrayBuffer)
 
     // vao setup
-    var vao uint32
-    gl.GenVertexArrays(1, &vao)
+    vao := render.NewVertexArray()
 
-    gl.BindVertexArray(vao)
+    vao.Bind()
 
     // bind the buffer
-    gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-    gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+    vbo.Data(len(vertices)*4, vertices, render.StaticDraw)
 
     gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 12, nil)
     gl.EnableVertexAttribArray(0)
@@ -211,12 +208,15 @@ void main()
         gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
         gl.UseProgram(program.Ptr)
-        gl.BindVertexArray(vao)
+        vao.Bind()
 
         // draw vao
         gl.DrawArrays(gl.TRIANGLES, 0, 3)
     })
 
+    vao.Delete()
+    vbo.Delete()
+
     window.Destroy()
 
     fmt.Println("application exiting")